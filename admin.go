@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+)
+
+type createAdminParams struct {
+	Username    string
+	Password    string
+	Fullname    string
+	Email       string
+	PhoneNumber string
+}
+
+// parseCreateAdminArgs reads the account of the create-admin command from its
+// flags. The password is taken from ADMIN_PASSWORD or else the first line of
+// stdin, so it does not end up in the shell history.
+func parseCreateAdminArgs(args []string, stdin io.Reader) (createAdminParams, error) {
+	var arg createAdminParams
+
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	flags.StringVar(&arg.Username, "username", "", "username of the admin")
+	flags.StringVar(&arg.Fullname, "fullname", "", "full name of the admin")
+	flags.StringVar(&arg.Email, "email", "", "email of the admin")
+	flags.StringVar(&arg.PhoneNumber, "phone", "", "phone number of the admin")
+	if err := flags.Parse(args); err != nil {
+		return createAdminParams{}, err
+	}
+
+	if arg.Username == "" || arg.Fullname == "" || arg.Email == "" || arg.PhoneNumber == "" {
+		return createAdminParams{}, errors.New("username, fullname, email and phone are required")
+	}
+
+	// logins only accept alphanumeric usernames
+	for _, r := range arg.Username {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return createAdminParams{}, errors.New("username can only have letters and digits")
+		}
+	}
+
+	arg.Password = os.Getenv("ADMIN_PASSWORD")
+	if arg.Password == "" {
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return createAdminParams{}, err
+		}
+		arg.Password = strings.TrimRight(line, "\r\n")
+	}
+
+	return arg, nil
+}
+
+// createAdmin creates an admin account with a verified email. It is how the
+// first admin of an install is made, every other account can then be invited.
+func createAdmin(ctx context.Context, store db.Store, policy util.PasswordPolicy, arg createAdminParams) (db.User, error) {
+	err := policy.Check(arg.Password, arg.Username, arg.Email)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	hashedPassword, err := util.HashPassword(arg.Password)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Username:       sql.NullString{String: arg.Username, Valid: true},
+		HashedPassword: hashedPassword,
+		Fullname:       sql.NullString{String: arg.Fullname, Valid: true},
+		Email:          sql.NullString{String: arg.Email, Valid: true},
+		PhoneNumber:    sql.NullString{String: arg.PhoneNumber, Valid: true},
+		Role:           util.RoleAdmin,
+	})
+	if err != nil {
+		return db.User{}, fmt.Errorf("cannot create admin: %w", err)
+	}
+
+	// whoever runs the command vouches for the address
+	return store.VerifyUserEmail(ctx, db.VerifyUserEmailParams{
+		ID:              user.ID,
+		Email:           user.Email,
+		EmailVerifiedAt: time.Now(),
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCreateAdminArgs(t *testing.T) {
+	args := []string{"-username", "root", "-fullname", "Root Admin", "-email", "root@example.com", "-phone", "0123456789"}
+
+	arg, err := parseCreateAdminArgs(args, strings.NewReader("Secret-pass1\n"))
+	require.NoError(t, err)
+	require.Equal(t, createAdminParams{
+		Username:    "root",
+		Password:    "Secret-pass1",
+		Fullname:    "Root Admin",
+		Email:       "root@example.com",
+		PhoneNumber: "0123456789",
+	}, arg)
+
+	t.Setenv("ADMIN_PASSWORD", "From-env-pass1")
+	arg, err = parseCreateAdminArgs(args, strings.NewReader(""))
+	require.NoError(t, err)
+	require.Equal(t, "From-env-pass1", arg.Password)
+
+	_, err = parseCreateAdminArgs(args[:4], strings.NewReader(""))
+	require.Error(t, err)
+
+	_, err = parseCreateAdminArgs(append([]string{"-username", "root.admin"}, args[2:]...), strings.NewReader(""))
+	require.Error(t, err)
+}
+
+func TestCreateAdmin(t *testing.T) {
+	policy := util.PasswordPolicy{MinLength: 8, MinClasses: 2}
+	arg := createAdminParams{
+		Username:    "root",
+		Password:    "Secret-pass1",
+		Fullname:    "Root Admin",
+		Email:       "root@example.com",
+		PhoneNumber: "0123456789",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, params db.CreateUserParams) (db.User, error) {
+			require.Equal(t, util.RoleAdmin, params.Role)
+			require.NoError(t, util.CheckPassword(arg.Password, params.HashedPassword))
+
+			return db.User{ID: 1, Username: params.Username, Email: params.Email, Role: params.Role}, nil
+		})
+	store.EXPECT().VerifyUserEmail(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, params db.VerifyUserEmailParams) (db.User, error) {
+			require.Equal(t, int64(1), params.ID)
+			require.Equal(t, sql.NullString{String: arg.Email, Valid: true}, params.Email)
+
+			return db.User{ID: 1, Role: util.RoleAdmin, EmailVerifiedAt: params.EmailVerifiedAt}, nil
+		})
+
+	admin, err := createAdmin(context.Background(), store, policy, arg)
+	require.NoError(t, err)
+	require.Equal(t, util.RoleAdmin, admin.Role)
+	require.WithinDuration(t, time.Now(), admin.EmailVerifiedAt, time.Second)
+
+	// a weak password is refused before anything is written
+	arg.Password = "root"
+	_, err = createAdmin(context.Background(), store, policy, arg)
+	require.ErrorIs(t, err, util.ErrWeakPassword)
+}
@@ -0,0 +1,406 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"net/http"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// joinCodeBytes is the entropy of a join code, 5 bytes encode to 8 base32 characters
+const joinCodeBytes = 5
+
+// newJoinCode generates the code students use to enroll themselves in a class
+func newJoinCode() (string, error) {
+	buffer := make([]byte, joinCodeBytes)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buffer), nil
+}
+
+// canAccessHomework reports whether the user created the homework or is
+// enrolled in its class. Homework without a class is only visible to its teacher.
+func (server *Server) canAccessHomework(ctx *gin.Context, userID int64, homework db.Homework) (bool, error) {
+	if homework.TeacherID == userID {
+		return true, nil
+	}
+
+	if !homework.ClassID.Valid {
+		return false, nil
+	}
+
+	_, err := server.store.GetClassMember(ctx, db.GetClassMemberParams{
+		ClassID: homework.ClassID.Int64,
+		UserID:  userID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// getOwnClass loads a class for its teacher. It writes the error response itself.
+func (server *Server) getOwnClass(ctx *gin.Context, classID int64) (db.Class, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	class, err := server.store.GetClass(ctx, classID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Class{}, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Class{}, false
+	}
+
+	if class.TeacherID != authPayload.Userid {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return db.Class{}, false
+	}
+
+	return class, true
+}
+
+type createClassRequest struct {
+	Name string `json:"name" binding:"required,max=256"`
+}
+
+func (server *Server) createClass(ctx *gin.Context) {
+	var req createClassRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	joinCode, err := newJoinCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateClassParams{
+		TeacherID: authPayload.Userid,
+		Name:      req.Name,
+		JoinCode:  joinCode,
+	}
+
+	class, err := server.store.CreateClass(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, class)
+}
+
+type getClassRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type classResponse struct {
+	ID        int64  `json:"id"`
+	TeacherID int64  `json:"teacher_id"`
+	Name      string `json:"name"`
+}
+
+func (server *Server) getClass(ctx *gin.Context) {
+	var req getClassRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	class, err := server.store.GetClass(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// the join code is only shown to the teacher of the class
+	if class.TeacherID == authPayload.Userid {
+		ctx.JSON(http.StatusOK, class)
+		return
+	}
+
+	_, err = server.store.GetClassMember(ctx, db.GetClassMemberParams{
+		ClassID: class.ID,
+		UserID:  authPayload.Userid,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("permission denied!")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, classResponse{
+		ID:        class.ID,
+		TeacherID: class.TeacherID,
+		Name:      class.Name,
+	})
+}
+
+type listClassesRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=20"`
+}
+
+func (server *Server) listClasses(ctx *gin.Context) {
+	var req listClassesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ListClassesForUserParams{
+		UserID: authPayload.Userid,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	classes, err := server.store.ListClassesForUser(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]classResponse, 0, len(classes))
+	for _, class := range classes {
+		rsp = append(rsp, classResponse{
+			ID:        class.ID,
+			TeacherID: class.TeacherID,
+			Name:      class.Name,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) resetClassJoinCode(ctx *gin.Context) {
+	var req getClassRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.getOwnClass(ctx, req.ID); !ok {
+		return
+	}
+
+	joinCode, err := newJoinCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	class, err := server.store.UpdateClassJoinCode(ctx, db.UpdateClassJoinCodeParams{
+		ID:       req.ID,
+		JoinCode: joinCode,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, class)
+}
+
+type addClassMemberRequest struct {
+	UserID int64 `json:"user_id" binding:"required,min=1"`
+}
+
+func (server *Server) addClassMember(ctx *gin.Context) {
+	var reqURI getClassRequest
+	if err := ctx.ShouldBindUri(&reqURI); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req addClassMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.getOwnClass(ctx, reqURI.ID); !ok {
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, req.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.enroll(ctx, reqURI.ID, user.ID)
+}
+
+type joinClassRequest struct {
+	JoinCode string `json:"join_code" binding:"required"`
+}
+
+func (server *Server) joinClass(ctx *gin.Context) {
+	var req joinClassRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	class, err := server.store.GetClassByJoinCode(ctx, req.JoinCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("invalid join code!")
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.enroll(ctx, class.ID, authPayload.Userid)
+}
+
+// enroll adds the user to the class and writes the response
+func (server *Server) enroll(ctx *gin.Context, classID, userID int64) {
+	member, err := server.store.AddClassMember(ctx, db.AddClassMemberParams{
+		ClassID: classID,
+		UserID:  userID,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("user is already enrolled!")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+type listClassMembersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=20"`
+}
+
+func (server *Server) listClassMembers(ctx *gin.Context) {
+	var reqURI getClassRequest
+	if err := ctx.ShouldBindUri(&reqURI); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listClassMembersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.getOwnClass(ctx, reqURI.ID); !ok {
+		return
+	}
+
+	users, err := server.store.ListClassMembers(ctx, db.ListClassMembersParams{
+		ClassID: reqURI.ID,
+		Limit:   req.PageSize,
+		Offset:  (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]userResponse, 0, len(users))
+	for _, user := range users {
+		rsp = append(rsp, newUserResponse(user))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type removeClassMemberRequest struct {
+	ID     int64 `uri:"id" binding:"required,min=1"`
+	UserID int64 `uri:"user_id" binding:"required,min=1"`
+}
+
+// removeClassMember lets the teacher remove a student, or a student leave the class
+func (server *Server) removeClassMember(ctx *gin.Context) {
+	var req removeClassMemberRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	class, err := server.store.GetClass(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if class.TeacherID != authPayload.Userid && req.UserID != authPayload.Userid {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	err = server.store.RemoveClassMember(ctx, db.RemoveClassMemberParams{
+		ClassID: req.ID,
+		UserID:  req.UserID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateClassAPI(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+	student, _ := randomStudentUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": "class 1A"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateClass(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateClassParams) (db.Class, error) {
+						require.Equal(t, teacher.ID, arg.TeacherID)
+						require.Equal(t, "class 1A", arg.Name)
+						require.Len(t, arg.JoinCode, 8)
+						return db.Class{ID: 1, TeacherID: arg.TeacherID, Name: arg.Name, JoinCode: arg.JoinCode}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotTeacher",
+			body: gin.H{"name": "class 1A"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateClass(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MissingName",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateClass(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/classes/create", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestJoinClassAPI(t *testing.T) {
+	student, _ := randomStudentUser(t)
+	class := db.Class{
+		ID:        util.RandomInt(1, 100),
+		TeacherID: student.ID + 1,
+		Name:      util.RandomString(6),
+		JoinCode:  "ABCDEFGH",
+	}
+
+	testCases := []struct {
+		name          string
+		joinCode      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			joinCode: class.JoinCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetClassByJoinCode(gomock.Any(), gomock.Eq(class.JoinCode)).
+					Times(1).
+					Return(class, nil)
+				store.EXPECT().AddClassMember(gomock.Any(), gomock.Eq(db.AddClassMemberParams{
+					ClassID: class.ID,
+					UserID:  student.ID,
+				})).
+					Times(1).
+					Return(db.ClassMember{ClassID: class.ID, UserID: student.ID}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidJoinCode",
+			joinCode: "WRONG",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetClassByJoinCode(gomock.Any(), gomock.Eq("WRONG")).
+					Times(1).
+					Return(db.Class{}, sql.ErrNoRows)
+				store.EXPECT().AddClassMember(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyEnrolled",
+			joinCode: class.JoinCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetClassByJoinCode(gomock.Any(), gomock.Eq(class.JoinCode)).
+					Times(1).
+					Return(class, nil)
+				store.EXPECT().AddClassMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ClassMember{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"join_code": tc.joinCode})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/classes/join", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultEmailVerifyDuration = 24 * time.Hour
+
+// sendEmailVerification mails the user a link that verifies their current
+// address
+func (server *Server) sendEmailVerification(ctx context.Context, user db.User) error {
+	verifyToken, err := util.NewSecret()
+	if err != nil {
+		return err
+	}
+
+	duration := server.config.EmailVerifyDuration
+	if duration <= 0 {
+		duration = defaultEmailVerifyDuration
+	}
+
+	verification, err := server.store.CreateEmailVerification(ctx, db.CreateEmailVerificationParams{
+		UserID:    user.ID,
+		Email:     user.Email.String,
+		TokenHash: util.HashSecret(verifyToken),
+		ExpiresAt: time.Now().Add(duration),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/users/verify_email?token=%s",
+		strings.TrimSuffix(server.config.PublicURL, "/"), url.QueryEscape(verifyToken))
+	body := fmt.Sprintf("Hello %s,\n\nopen this link to verify your email address, it expires at %s:\n\n%s\n",
+		user.Fullname.String, verification.ExpiresAt.Format(time.RFC1123), link)
+
+	return server.mailer.Send(ctx, verification.Email, "Verify your email address", body)
+}
+
+type verifyEmailRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.VerifyEmailTx(ctx, db.VerifyEmailTxParams{
+		TokenHash:  util.HashSecret(req.Token),
+		VerifiedAt: time.Now(),
+	})
+	if err != nil {
+		if err == db.ErrEmailVerificationInvalid {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := newPrivateUserResponse(user)
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type resendEmailVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// resendEmailVerification sends a new verification link. Like forgotPassword
+// it answers the same way for unknown and already verified addresses.
+func (server *Server) resendEmailVerification(ctx *gin.Context) {
+	var req resendEmailVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserByEmail(ctx, sql.NullString{String: req.Email, Valid: true})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusOK, nil)
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !user.EmailVerifiedAt.IsZero() {
+		ctx.JSON(http.StatusOK, nil)
+		return
+	}
+
+	err = server.sendEmailVerification(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	mockmail "github.com/dongocanh96/class_manager_go/mail/mock"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyEmailAPI(t *testing.T) {
+	user, _ := randomStudentUser(t)
+	verifyToken, err := util.NewSecret()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?token=" + verifyToken,
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := user
+				verified.EmailVerifiedAt = time.Now()
+
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.VerifyEmailTxParams) (db.User, error) {
+						require.Equal(t, util.HashSecret(verifyToken), arg.TokenHash)
+						return verified, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp privateUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, user.ID, rsp.ID)
+				require.False(t, rsp.EmailVerifiedAt.IsZero())
+			},
+		},
+		{
+			name:  "InvalidToken",
+			query: "?token=" + verifyToken,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrEmailVerificationInvalid)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "MissingToken",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/verify_email"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestResendEmailVerificationAPI(t *testing.T) {
+	user, _ := randomStudentUser(t)
+
+	verified := user
+	verified.EmailVerifiedAt = time.Now()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, mailer *mockmail.MockMailer)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				var tokenHash string
+
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().CreateEmailVerification(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateEmailVerificationParams) (db.EmailVerification, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, user.Email.String, arg.Email)
+						tokenHash = arg.TokenHash
+
+						return db.EmailVerification{UserID: arg.UserID, Email: arg.Email, ExpiresAt: arg.ExpiresAt}, nil
+					})
+				mailer.EXPECT().Send(gomock.Any(), gomock.Eq(user.Email.String), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, to string, subject string, body string) error {
+						start := strings.Index(body, "http://localhost:8080/users/verify_email?")
+						require.GreaterOrEqual(t, start, 0)
+
+						link, err := url.Parse(strings.Fields(body[start:])[0])
+						require.NoError(t, err)
+						require.Equal(t, tokenHash, util.HashSecret(link.Query().Get("token")))
+
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyVerified",
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(verified, nil)
+				store.EXPECT().CreateEmailVerification(gomock.Any(), gomock.Any()).
+					Times(0)
+				mailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownEmail",
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				mailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockMailer(ctrl)
+			tc.buildStubs(store, mailer)
+
+			server := newTestServer(t, store)
+			server.mailer = mailer
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"email": user.Email.String})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/resend_verification", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestEmailVerificationDefaultDuration(t *testing.T) {
+	user, _ := randomStudentUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateEmailVerification(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreateEmailVerificationParams) (db.EmailVerification, error) {
+			require.WithinDuration(t, time.Now().Add(defaultEmailVerifyDuration), arg.ExpiresAt, time.Second)
+			return db.EmailVerification{UserID: arg.UserID, Email: arg.Email, ExpiresAt: arg.ExpiresAt}, nil
+		})
+
+	server := newTestServer(t, store)
+	server.config.EmailVerifyDuration = 0
+
+	err := server.sendEmailVerification(context.Background(), user)
+	require.NoError(t, err)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxFileKeyExtLength = 10
+
+// saveUploadedFile writes an uploaded multipart file to the file store under
+// a newly generated key and returns that key
+func (server *Server) saveUploadedFile(ctx *gin.Context, fileHeader *multipart.FileHeader, contentType string) (string, error) {
+	key, err := newFileKey(fileHeader.Filename)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	err = server.fileStore.Save(ctx, key, file, fileHeader.Size, contentType)
+	return key, err
+}
+
+// serveFile streams the stored file back to the client. Content-Type is
+// derived from the original file name, and Range/If-Range requests are
+// handled by http.ServeContent so interrupted downloads can be resumed.
+func (server *Server) serveFile(ctx *gin.Context, fileName string, key string, modTime time.Time) {
+	file, err := server.fileStore.Open(ctx, key)
+	if err != nil {
+		if err == storage.ErrFileNotFound {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	defer file.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": fileName})
+	ctx.Header("Content-Disposition", disposition)
+	http.ServeContent(ctx.Writer, ctx.Request, fileName, modTime, file)
+}
+
+// lastModified returns the latest of the given timestamps, used as the
+// Last-Modified value of a stored file.
+func lastModified(times ...time.Time) time.Time {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+
+	return latest
+}
+
+// newFileKey generates the key an uploaded file is stored under. Only the
+// extension of the client file name is kept, so two uploads never collide
+// and a crafted name can not reach the storage path.
+func newFileKey(fileName string) (string, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+
+	ext := strings.ToLower(filepath.Ext(displayFileName(fileName)))
+	if len(ext) > maxFileKeyExtLength || strings.ContainsAny(ext, ` /\:*?"<>|`) {
+		ext = ""
+	}
+
+	return id.String() + ext, nil
+}
+
+// displayFileName strips any directory part of a client supplied file name,
+// the result is only used as metadata shown back to users
+func displayFileName(fileName string) string {
+	fileName = filepath.Base(strings.ReplaceAll(fileName, `\`, "/"))
+	if fileName == "." || fileName == "/" {
+		return "file"
+	}
+
+	return fileName
+}
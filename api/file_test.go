@@ -0,0 +1,32 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileKey(t *testing.T) {
+	key1, err := newFileKey("solution.PDF")
+	require.NoError(t, err)
+	require.Regexp(t, `^[0-9a-f-]{36}\.pdf$`, key1)
+
+	key2, err := newFileKey("solution.PDF")
+	require.NoError(t, err)
+	require.NotEqual(t, key1, key2)
+
+	key, err := newFileKey("../../app.env")
+	require.NoError(t, err)
+	require.Regexp(t, `^[0-9a-f-]{36}\.env$`, key)
+
+	key, err = newFileKey("archive.verylongextension")
+	require.NoError(t, err)
+	require.Regexp(t, `^[0-9a-f-]{36}$`, key)
+}
+
+func TestDisplayFileName(t *testing.T) {
+	require.Equal(t, "solution.pdf", displayFileName("solution.pdf"))
+	require.Equal(t, "app.env", displayFileName("../../app.env"))
+	require.Equal(t, "app.env", displayFileName(`..\..\app.env`))
+	require.Equal(t, "file", displayFileName(""))
+}
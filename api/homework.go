@@ -3,10 +3,8 @@ package api
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"time"
 
 	db "github.com/dongocanh96/class_manager_go/db/sqlc"
@@ -16,44 +14,76 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// defaultMaxScore is used when a homework is created without a max score
+const defaultMaxScore = 100
+
 type createHomeworkRequest struct {
-	Subject string                `form:"subject" binding:"required,subject"`
-	Title   string                `form:"title" binding:"required,max=256"`
-	File    *multipart.FileHeader `form:"file" binding:"required"`
+	ClassID           int64                 `form:"class_id" binding:"required,min=1"`
+	Subject           string                `form:"subject" binding:"required,subject"`
+	Title             string                `form:"title" binding:"required,max=256"`
+	File              *multipart.FileHeader `form:"file" binding:"required"`
+	DueAt             time.Time             `form:"due_at"`
+	AllowLate         bool                  `form:"allow_late"`
+	LateGraceMinutes  int32                 `form:"late_grace_minutes" binding:"min=0"`
+	LatePenaltyPerDay int32                 `form:"late_penalty_per_day" binding:"min=0,max=100"`
+	LateCutoffAt      time.Time             `form:"late_cutoff_at"`
+	MaxScore          float64               `form:"max_score" binding:"min=0"`
 }
 
 func (server *Server) createHomework(ctx *gin.Context) {
 	var req createHomeworkRequest
-	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	if !bindUpload(ctx, &req, server.config.MaxHomeworkFileSize) {
 		return
 	}
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	if !authPayload.IsTeacher {
-		err := errors.New("user is not teacher!")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !validDueAt(ctx, req.DueAt) {
+		return
+	}
+
+	if !validLateCutoffAt(ctx, req.DueAt, req.LateCutoffAt) {
+		return
+	}
+
+	if _, ok := server.getOwnClass(ctx, req.ClassID); !ok {
 		return
 	}
 
-	savedPath := fmt.Sprintf("%s%s", server.config.Asset, req.File.Filename)
-	err := ctx.SaveUploadedFile(req.File, savedPath)
+	contentType, valid := server.validUpload(ctx, req.File, server.config.MaxHomeworkFileSize)
+	if !valid {
+		return
+	}
+
+	savedPath, err := server.saveUploadedFile(ctx, req.File, contentType)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
 	arg := db.CreateHomeworkParams{
-		TeacherID: authPayload.Userid,
-		Subject:   req.Subject,
-		Title:     req.Title,
-		FileName:  req.File.Filename,
-		SavedPath: savedPath,
+		TeacherID:         authPayload.Userid,
+		Subject:           req.Subject,
+		Title:             req.Title,
+		FileName:          displayFileName(req.File.Filename),
+		SavedPath:         savedPath,
+		DueAt:             sql.NullTime{Time: req.DueAt, Valid: !req.DueAt.IsZero()},
+		AllowLate:         req.AllowLate,
+		LateGraceMinutes:  req.LateGraceMinutes,
+		LatePenaltyPerDay: req.LatePenaltyPerDay,
+		LateCutoffAt:      sql.NullTime{Time: req.LateCutoffAt, Valid: !req.LateCutoffAt.IsZero()},
+		MaxScore:          req.MaxScore,
+		ClassID:           sql.NullInt64{Int64: req.ClassID, Valid: true},
+	}
+	if arg.MaxScore == 0 {
+		arg.MaxScore = defaultMaxScore
 	}
 
 	homework, err := server.store.CreateHomework(ctx, arg)
 	if err != nil {
+		// the stored file is not referenced by any row, remove it
+		server.fileStore.Delete(ctx, savedPath)
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -65,6 +95,35 @@ type getHomeworkRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
+// getAccessibleHomework loads a homework the user teaches or is enrolled in
+// the class of. It writes the error response itself.
+func (server *Server) getAccessibleHomework(ctx *gin.Context, authPayload *token.Payload, homeworkID int64) (db.Homework, bool) {
+	homework, err := server.store.GetHomework(ctx, homeworkID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Homework{}, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Homework{}, false
+	}
+
+	canAccess, err := server.canAccessHomework(ctx, authPayload.Userid, homework)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Homework{}, false
+	}
+
+	if !canAccess {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return db.Homework{}, false
+	}
+
+	return homework, true
+}
+
 func (server *Server) getHomework(ctx *gin.Context) {
 	var req getHomeworkRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
@@ -72,22 +131,33 @@ func (server *Server) getHomework(ctx *gin.Context) {
 		return
 	}
 
-	_ = ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-
-	homework, err := server.store.GetHomework(ctx, req.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	homework, ok := server.getAccessibleHomework(ctx, authPayload, req.ID)
+	if !ok {
 		return
 	}
 
 	ctx.JSON(http.StatusOK, homework)
 }
 
+func (server *Server) downloadHomeworkFile(ctx *gin.Context) {
+	var req getHomeworkRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	homework, ok := server.getAccessibleHomework(ctx, authPayload, req.ID)
+	if !ok {
+		return
+	}
+
+	server.serveFile(ctx, homework.FileName, homework.SavedPath, lastModified(homework.CreatedAt, homework.UpdatedAt))
+}
+
 type listHomeworkRequest struct {
 	PageID   int32 `form:"page_id" binding:"required,min=1"`
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=20"`
@@ -101,14 +171,15 @@ func (server *Server) listHomework(ctx *gin.Context) {
 		return
 	}
 
-	_ = ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	arg := db.ListHomeworksParams{
+	arg := db.ListHomeworksForUserParams{
+		UserID: authPayload.Userid,
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
 	}
 
-	homeworks, err := server.store.ListHomeworks(ctx, arg)
+	homeworks, err := server.store.ListHomeworksForUser(ctx, arg)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -123,7 +194,7 @@ func (server *Server) listHomework(ctx *gin.Context) {
 }
 
 type listHomeworkBySubjectRequest struct {
-	Subject  string `form:"subject" binding:"required,max=256"`
+	Subject  string `form:"subject" binding:"required,subject"`
 	PageID   int32  `form:"page_id" binding:"required,min=1"`
 	PageSize int32  `form:"page_size" binding:"required,min=5,max=20"`
 }
@@ -135,20 +206,16 @@ func (server *Server) listHomeworkBySubject(ctx *gin.Context) {
 		return
 	}
 
-	if !util.IsSupportedSubject(req.Subject) {
-		ctx.JSON(http.StatusBadRequest, errors.New("subject is not supported"))
-		return
-	}
-
-	_ = ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	arg := db.ListHomeworksBySubjectParams{
+	arg := db.ListHomeworksBySubjectForUserParams{
 		Subject: req.Subject,
+		UserID:  authPayload.Userid,
 		Limit:   req.PageSize,
 		Offset:  (req.PageID - 1) * req.PageSize,
 	}
 
-	homeworks, err := server.store.ListHomeworksBySubject(ctx, arg)
+	homeworks, err := server.store.ListHomeworksBySubjectForUser(ctx, arg)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -163,7 +230,9 @@ func (server *Server) listHomeworkBySubject(ctx *gin.Context) {
 }
 
 type updateHomeworkRequest struct {
-	File *multipart.FileHeader `form:"file" binding:"required"`
+	File    *multipart.FileHeader `form:"file"`
+	DueAt   time.Time             `form:"due_at"`
+	ClassID int64                 `form:"class_id" binding:"omitempty,min=1"`
 }
 
 func (server *Server) updateHomework(ctx *gin.Context) {
@@ -174,7 +243,12 @@ func (server *Server) updateHomework(ctx *gin.Context) {
 	}
 
 	var reqForm updateHomeworkRequest
-	if err := ctx.ShouldBind(&reqForm); err != nil {
+	if !bindUpload(ctx, &reqForm, server.config.MaxHomeworkFileSize) {
+		return
+	}
+
+	if reqForm.File == nil && reqForm.DueAt.IsZero() && reqForm.ClassID == 0 {
+		err := errors.New("file, due_at or class_id is required!")
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
@@ -192,7 +266,8 @@ func (server *Server) updateHomework(ctx *gin.Context) {
 		return
 	}
 
-	if homework.IsClosed {
+	// a closed homework is reopened by moving its due date
+	if homework.IsClosed && reqForm.DueAt.IsZero() {
 		err := errors.New("this homework is closed!")
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
@@ -204,23 +279,155 @@ func (server *Server) updateHomework(ctx *gin.Context) {
 		return
 	}
 
-	savedPath := fmt.Sprintf("%s%s", server.config.Asset, reqForm.File.Filename)
-	err = ctx.SaveUploadedFile(reqForm.File, savedPath)
+	if !validDueAt(ctx, reqForm.DueAt) {
+		return
+	}
+
+	arg := db.UpdateHomeworkTxParams{
+		ID:        reqURI.ID,
+		UpdatedAt: time.Now(),
+	}
+
+	if reqForm.ClassID != 0 {
+		if _, ok := server.getOwnClass(ctx, reqForm.ClassID); !ok {
+			return
+		}
+		arg.ClassID = sql.NullInt64{Int64: reqForm.ClassID, Valid: true}
+	}
+
+	if !reqForm.DueAt.IsZero() {
+		arg.DueAt = sql.NullTime{Time: reqForm.DueAt, Valid: true}
+	}
+
+	// the file is checked and stored before anything changes, so a bad
+	// upload leaves the homework as it was
+	if reqForm.File != nil {
+		contentType, valid := server.validUpload(ctx, reqForm.File, server.config.MaxHomeworkFileSize)
+		if !valid {
+			return
+		}
+
+		arg.SavedPath, err = server.saveUploadedFile(ctx, reqForm.File, contentType)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		arg.FileName = displayFileName(reqForm.File.Filename)
+	}
+
+	oldSavedPath := homework.SavedPath
+
+	homework, err = server.store.UpdateHomeworkTx(ctx, arg)
 	if err != nil {
+		if arg.SavedPath != "" {
+			// the stored file is not referenced by any row, remove it
+			server.fileStore.Delete(ctx, arg.SavedPath)
+		}
+
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	oldSavedPath := homework.SavedPath
+	if arg.SavedPath != "" {
+		err = server.fileStore.Delete(ctx, oldSavedPath)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
 
-	arg := db.UpdateHomeworkParams{
-		ID:        reqURI.ID,
-		FileName:  reqForm.File.Filename,
-		SavedPath: savedPath,
-		UpdatedAt: time.Now(),
+	ctx.JSON(http.StatusOK, homework)
+}
+
+// validDueAt checks that an optional due date lies in the future
+func validDueAt(ctx *gin.Context, dueAt time.Time) bool {
+	if !dueAt.IsZero() && !dueAt.After(time.Now()) {
+		err := errors.New("due date must be in the future!")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// validLateCutoffAt checks that an optional late cutoff does not come before
+// the due date
+func validLateCutoffAt(ctx *gin.Context, dueAt, cutoffAt time.Time) bool {
+	if !cutoffAt.IsZero() && cutoffAt.Before(dueAt) {
+		err := errors.New("late cutoff must not be before the due date!")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// submissionDeadline is the earlier of the due date and the time the
+// homework was closed, zero if there is neither
+func submissionDeadline(homework db.Homework) time.Time {
+	var deadline time.Time
+	if homework.DueAt.Valid {
+		deadline = homework.DueAt.Time
+	}
+
+	if homework.IsClosed && (deadline.IsZero() || homework.ClosedAt.Before(deadline)) {
+		deadline = homework.ClosedAt
+	}
+
+	return deadline
+}
+
+func latePolicy(homework db.Homework) util.LatePolicy {
+	return util.LatePolicy{
+		AllowLate:     homework.AllowLate,
+		GracePeriod:   time.Duration(homework.LateGraceMinutes) * time.Minute,
+		PenaltyPerDay: homework.LatePenaltyPerDay,
+		CutoffAt:      homework.LateCutoffAt.Time,
+	}
+}
+
+// evaluateLateness applies the homework late policy to a submission made at
+// submittedAt. It writes the error response itself when the homework no
+// longer accepts submissions.
+func evaluateLateness(ctx *gin.Context, homework db.Homework, submittedAt time.Time) (util.Lateness, bool) {
+	lateness, err := latePolicy(homework).Evaluate(submissionDeadline(homework), submittedAt)
+	if err != nil {
+		err := errors.New("homework is closed!")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return util.Lateness{}, false
+	}
+
+	return lateness, true
+}
+
+type updateHomeworkLatePolicyRequest struct {
+	AllowLate         bool      `json:"allow_late"`
+	LateGraceMinutes  int32     `json:"late_grace_minutes" binding:"min=0"`
+	LatePenaltyPerDay int32     `json:"late_penalty_per_day" binding:"min=0,max=100"`
+	LateCutoffAt      time.Time `json:"late_cutoff_at"`
+}
+
+func (server *Server) updateHomeworkLatePolicy(ctx *gin.Context) {
+	var reqURI getHomeworkRequest
+	if err := ctx.ShouldBindUri(&reqURI); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateHomeworkLatePolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
 
-	homework, err = server.store.UpdateHomework(ctx, arg)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	homework, err := server.store.GetHomework(ctx, reqURI.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -231,14 +438,37 @@ func (server *Server) updateHomework(ctx *gin.Context) {
 		return
 	}
 
-	err = os.Remove(oldSavedPath)
+	if homework.TeacherID != authPayload.Userid {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if !validLateCutoffAt(ctx, homework.DueAt.Time, req.LateCutoffAt) {
+		return
+	}
+
+	arg := db.UpdateHomeworkLatePolicyParams{
+		ID:                reqURI.ID,
+		AllowLate:         req.AllowLate,
+		LateGraceMinutes:  req.LateGraceMinutes,
+		LatePenaltyPerDay: req.LatePenaltyPerDay,
+		LateCutoffAt:      sql.NullTime{Time: req.LateCutoffAt, Valid: !req.LateCutoffAt.IsZero()},
+		UpdatedAt:         time.Now(),
+	}
+
+	homework, err = server.store.UpdateHomeworkLatePolicy(ctx, arg)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, homework)
-
 }
 
 func (server *Server) closeHomework(ctx *gin.Context) {
@@ -313,7 +543,7 @@ func (server *Server) deleteHomework(ctx *gin.Context) {
 		return
 	}
 
-	err = os.Remove(homework.SavedPath)
+	err = server.fileStore.Delete(ctx, homework.SavedPath)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -334,8 +564,7 @@ type createSolutionRequest struct {
 
 func (server *Server) createSolution(ctx *gin.Context) {
 	var req createSolutionRequest
-	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	if !bindUpload(ctx, &req, server.config.MaxSolutionFileSize) {
 		return
 	}
 
@@ -347,56 +576,46 @@ func (server *Server) createSolution(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	homework, err := server.store.GetHomework(ctx, reqURI.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
+	homework, ok := server.getAccessibleHomework(ctx, authPayload, reqURI.ID)
+	if !ok {
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	lateness, ok := evaluateLateness(ctx, homework, time.Now())
+	if !ok {
 		return
 	}
 
-	if homework.IsClosed {
-		err := errors.New("homework is closed!")
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	contentType, valid := server.validUpload(ctx, req.File, server.config.MaxSolutionFileSize)
+	if !valid {
 		return
 	}
 
-	savedPath := fmt.Sprintf("%s%s", server.config.Asset, req.File.Filename)
-	err = ctx.SaveUploadedFile(req.File, savedPath)
+	savedPath, err := server.saveUploadedFile(ctx, req.File, contentType)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
 	arg := db.CreateSolutionParams{
-		ProblemID: reqURI.ID,
-		UserID:    authPayload.Userid,
-		FileName:  req.File.Filename,
-		SavedPath: savedPath,
+		ProblemID:   reqURI.ID,
+		UserID:      authPayload.Userid,
+		FileName:    displayFileName(req.File.Filename),
+		SavedPath:   savedPath,
+		IsLate:      lateness.IsLate,
+		LateSeconds: int64(lateness.LateBy / time.Second),
+		LatePenalty: lateness.Penalty,
 	}
 
 	solution, err := server.store.CreateSolution(ctx, arg)
 	if err != nil {
+		// the stored file is not referenced by any row, remove it
+		server.fileStore.Delete(ctx, savedPath)
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	if authPayload.IsTeacher {
-		argCloseHomework := db.CloseHomeworkParams{
-			ID:       reqURI.ID,
-			IsClosed: true,
-			ClosedAt: time.Now(),
-		}
-		_, err := server.store.CloseHomework(ctx, argCloseHomework)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-	}
-
 	ctx.JSON(http.StatusOK, solution)
 }
 
@@ -418,31 +637,34 @@ func (server *Server) listSolutionsByProblem(ctx *gin.Context) {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	homework, err := server.store.GetHomework(ctx, reqURI.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
 
-	if !authPayload.IsTeacher {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if homework.TeacherID != authPayload.Userid && !hasPermission(authPayload.Role, permissionViewSolutions) {
 		err := errors.New("permission denied!")
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
 	arg := db.ListSolutionsByProblemParams{
-		ProblemID: reqURI.ID,
+		ProblemID: homework.ID,
 		Limit:     reqForm.PageSize,
 		Offset:    (reqForm.PageID - 1) * reqForm.PageSize,
 	}
 
 	solutions, err := server.store.ListSolutionsByProblem(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			if err == sql.ErrNoRows {
-				ctx.JSON(http.StatusNotFound, errorResponse(err))
-				return
-			}
-
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
 
 	ctx.JSON(http.StatusOK, solutions)
@@ -465,16 +687,26 @@ func (server *Server) getSolutionByProblemAndUser(ctx *gin.Context) {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	homework, err := server.store.GetHomework(ctx, reqURI.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 
-	if !authPayload.IsTeacher && authPayload.Userid != req.UserID {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !canViewSolution(authPayload, db.Solution{UserID: req.UserID}, homework) {
 		err := errors.New("permission denied!")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
 	arg := db.GetSolutionByProblemAndUserParams{
-		ProblemID: reqURI.ID,
+		ProblemID: homework.ID,
 		UserID:    req.UserID,
 	}
 
@@ -0,0 +1,598 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSolutionAPI(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+	student := randomUser(t)
+	student.ID = teacher.ID + 1
+	student.Role = util.RoleStudent
+
+	homework := randomHomework(t, teacher.ID)
+	homework.DueAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+
+	closedHomework := homework
+	closedHomework.IsClosed = true
+	closedHomework.ClosedAt = time.Now()
+
+	pastDueHomework := homework
+	pastDueHomework.DueAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+
+	lateHomework := pastDueHomework
+	lateHomework.DueAt = sql.NullTime{Time: time.Now().Add(-26 * time.Hour), Valid: true}
+	lateHomework.AllowLate = true
+	lateHomework.LateGraceMinutes = 60
+	lateHomework.LatePenaltyPerDay = 10
+
+	cutoffHomework := lateHomework
+	cutoffHomework.LateCutoffAt = sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+
+	testCases := []struct {
+		name          string
+		homeworkID    int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			homeworkID: homework.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+
+				store.EXPECT().GetClassMember(gomock.Any(), gomock.Eq(db.GetClassMemberParams{
+					ClassID: homework.ClassID.Int64,
+					UserID:  student.ID,
+				})).
+					Times(1).
+					Return(db.ClassMember{ClassID: homework.ClassID.Int64, UserID: student.ID}, nil)
+
+				store.EXPECT().CreateSolution(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Solution{ProblemID: homework.ID, UserID: student.ID}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "HomeworkClosed",
+			homeworkID: homework.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(closedHomework, nil)
+
+				store.EXPECT().GetClassMember(gomock.Any(), gomock.Eq(db.GetClassMemberParams{
+					ClassID: homework.ClassID.Int64,
+					UserID:  student.ID,
+				})).
+					Times(1).
+					Return(db.ClassMember{ClassID: homework.ClassID.Int64, UserID: student.ID}, nil)
+
+				store.EXPECT().CreateSolution(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "PastDueNotClosedYet",
+			homeworkID: homework.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(pastDueHomework, nil)
+
+				store.EXPECT().GetClassMember(gomock.Any(), gomock.Eq(db.GetClassMemberParams{
+					ClassID: homework.ClassID.Int64,
+					UserID:  student.ID,
+				})).
+					Times(1).
+					Return(db.ClassMember{ClassID: homework.ClassID.Int64, UserID: student.ID}, nil)
+
+				store.EXPECT().CreateSolution(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "LateAccepted",
+			homeworkID: homework.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(lateHomework, nil)
+
+				store.EXPECT().GetClassMember(gomock.Any(), gomock.Eq(db.GetClassMemberParams{
+					ClassID: homework.ClassID.Int64,
+					UserID:  student.ID,
+				})).
+					Times(1).
+					Return(db.ClassMember{ClassID: homework.ClassID.Int64, UserID: student.ID}, nil)
+
+				store.EXPECT().CreateSolution(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSolutionParams) (db.Solution, error) {
+						require.True(t, arg.IsLate)
+						require.InDelta(t, 26*60*60, arg.LateSeconds, 5)
+						require.Equal(t, int32(20), arg.LatePenalty)
+						return db.Solution{ProblemID: homework.ID, UserID: student.ID, IsLate: arg.IsLate}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "LateAfterCutoff",
+			homeworkID: homework.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(cutoffHomework, nil)
+
+				store.EXPECT().GetClassMember(gomock.Any(), gomock.Eq(db.GetClassMemberParams{
+					ClassID: homework.ClassID.Int64,
+					UserID:  student.ID,
+				})).
+					Times(1).
+					Return(db.ClassMember{ClassID: homework.ClassID.Int64, UserID: student.ID}, nil)
+
+				store.EXPECT().CreateSolution(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "NotEnrolled",
+			homeworkID: homework.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+
+				store.EXPECT().GetClassMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ClassMember{}, sql.ErrNoRows)
+
+				store.EXPECT().CreateSolution(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "HomeworkNotFound",
+			homeworkID: homework.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(db.Homework{}, sql.ErrNoRows)
+
+				store.EXPECT().CreateSolution(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			body, contentType := newMultipartBody(t, nil, "solution.txt", []byte(util.RandomString(32)))
+
+			url := fmt.Sprintf("/homeworks/%d/solutions/create", tc.homeworkID)
+			request, err := http.NewRequest(http.MethodPost, url, body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", contentType)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateHomeworkAPI(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+
+	closedHomework := randomHomework(t, teacher.ID)
+	closedHomework.DueAt = sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+	closedHomework.IsClosed = true
+	closedHomework.ClosedAt = closedHomework.DueAt.Time
+
+	dueAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	reopenedHomework := closedHomework
+	reopenedHomework.DueAt = sql.NullTime{Time: dueAt, Valid: true}
+	reopenedHomework.IsClosed = false
+	reopenedHomework.ClosedAt = time.Time{}
+
+	openHomework := randomHomework(t, teacher.ID)
+	openHomework.ID = closedHomework.ID
+
+	pdfContent := []byte("%PDF-1.4\n%âãÏÓ\n1 0 obj\n<<>>\nendobj\n")
+
+	testCases := []struct {
+		name          string
+		fields        map[string]string
+		fileName      string
+		content       []byte
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, files []string)
+	}{
+		{
+			name:   "ReopenWithNewDueAt",
+			fields: map[string]string{"due_at": dueAt.Format(time.RFC3339)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(closedHomework.ID)).
+					Times(1).
+					Return(closedHomework, nil)
+				store.EXPECT().UpdateHomeworkTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateHomeworkTxParams) (db.Homework, error) {
+						require.Equal(t, closedHomework.ID, arg.ID)
+						require.True(t, dueAt.Equal(arg.DueAt.Time))
+						require.False(t, arg.ClassID.Valid)
+						require.Empty(t, arg.SavedPath)
+						return reopenedHomework, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, files []string) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "ClosedWithoutDueAt",
+			fields: map[string]string{"class_id": "1"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(closedHomework.ID)).
+					Times(1).
+					Return(closedHomework, nil)
+				store.EXPECT().GetClass(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, files []string) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "PastDueAt",
+			fields: map[string]string{"due_at": time.Now().Add(-time.Minute).Format(time.RFC3339)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(closedHomework.ID)).
+					Times(1).
+					Return(closedHomework, nil)
+				store.EXPECT().UpdateHomeworkTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, files []string) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "FileClassAndDueAt",
+			fields:   map[string]string{"due_at": dueAt.Format(time.RFC3339), "class_id": "1"},
+			fileName: "homework.pdf",
+			content:  pdfContent,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(openHomework.ID)).
+					Times(1).
+					Return(openHomework, nil)
+				store.EXPECT().GetClass(gomock.Any(), gomock.Eq(int64(1))).
+					Times(1).
+					Return(db.Class{ID: 1, TeacherID: teacher.ID}, nil)
+				store.EXPECT().UpdateHomeworkTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateHomeworkTxParams) (db.Homework, error) {
+						require.Equal(t, openHomework.ID, arg.ID)
+						require.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, arg.ClassID)
+						require.True(t, dueAt.Equal(arg.DueAt.Time))
+						require.Equal(t, "homework.pdf", arg.FileName)
+						require.NotEmpty(t, arg.SavedPath)
+
+						homework := openHomework
+						homework.SavedPath = arg.SavedPath
+						return homework, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, files []string) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				// the old file is replaced by the new one
+				require.Len(t, files, 1)
+				require.NotEqual(t, openHomework.SavedPath, files[0])
+			},
+		},
+		{
+			name:     "UnsupportedFile",
+			fields:   map[string]string{"due_at": dueAt.Format(time.RFC3339)},
+			fileName: "image.pdf",
+			content:  []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(openHomework.ID)).
+					Times(1).
+					Return(openHomework, nil)
+				store.EXPECT().UpdateHomeworkTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, files []string) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+				require.Equal(t, []string{openHomework.SavedPath}, files)
+			},
+		},
+		{
+			name:     "TxError",
+			fields:   map[string]string{"due_at": dueAt.Format(time.RFC3339)},
+			fileName: "homework.pdf",
+			content:  pdfContent,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(openHomework.ID)).
+					Times(1).
+					Return(openHomework, nil)
+				store.EXPECT().UpdateHomeworkTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Homework{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, files []string) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				// the new file is removed, the old one is kept
+				require.Equal(t, []string{openHomework.SavedPath}, files)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.AllowedFileTypes = []string{"application/pdf"}
+			recorder := httptest.NewRecorder()
+
+			// the current file of the homework
+			err := os.WriteFile(filepath.Join(server.config.Asset, openHomework.SavedPath), []byte("old"), 0o600)
+			require.NoError(t, err)
+
+			body, contentType := newMultipartBody(t, tc.fields, tc.fileName, tc.content)
+
+			url := fmt.Sprintf("/homeworks/%d", closedHomework.ID)
+			request, err := http.NewRequest(http.MethodPut, url, body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", contentType)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+
+			entries, err := os.ReadDir(server.config.Asset)
+			require.NoError(t, err)
+
+			files := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+			}
+			tc.checkResponse(t, recorder, files)
+		})
+	}
+}
+
+func TestListSolutionsByProblemAPI(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+	otherTeacher, _ := randomTeacherUser(t)
+	otherTeacher.ID = teacher.ID + 1
+	admin := randomUser(t)
+	admin.ID = teacher.ID + 2
+	admin.Role = util.RoleAdmin
+
+	homework := randomHomework(t, teacher.ID)
+	solutions := []db.Solution{randomSolution(t, teacher.ID+3), randomSolution(t, teacher.ID+4)}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "TeacherOK",
+			user: teacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+				store.EXPECT().ListSolutionsByProblem(gomock.Any(), gomock.Eq(db.ListSolutionsByProblemParams{
+					ProblemID: homework.ID,
+					Limit:     5,
+					Offset:    0,
+				})).
+					Times(1).
+					Return(solutions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "AdminOK",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+				store.EXPECT().ListSolutionsByProblem(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(solutions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotHomeworkTeacher",
+			user: otherTeacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+				store.EXPECT().ListSolutionsByProblem(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "HomeworkNotFound",
+			user: teacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(db.Homework{}, sql.ErrNoRows)
+				store.EXPECT().ListSolutionsByProblem(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/homeworks/%d/solutions?page_id=1&page_size=5", homework.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.user.ID, tc.user.Username.String, tc.user.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomHomework(t *testing.T, teacherID int64) db.Homework {
+	return db.Homework{
+		ID:        util.RandomInt(1, 100),
+		TeacherID: teacherID,
+		Subject:   util.RandomSubject(),
+		Title:     util.RandomString(10),
+		ClassID:   sql.NullInt64{Int64: util.RandomInt(1, 100), Valid: true},
+		FileName:  "homework.pdf",
+		SavedPath: util.RandomString(10),
+		CreatedAt: time.Now(),
+	}
+}
+
+// newMultipartBody builds a multipart form with the given fields and a "file" part
+func newMultipartBody(t *testing.T, fields map[string]string, fileName string, content []byte) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	for key, value := range fields {
+		require.NoError(t, writer.WriteField(key, value))
+	}
+
+	if fileName != "" {
+		part, err := writer.CreateFormFile("file", fileName)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type inviteResponse struct {
+	ID        int64     `json:"id"`
+	Code      string    `json:"code,omitempty"`
+	Role      string    `json:"role"`
+	ClassID   int64     `json:"class_id,omitempty"`
+	CreatedBy int64     `json:"created_by"`
+	MaxUses   int32     `json:"max_uses"`
+	Uses      int32     `json:"uses"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newInviteResponse leaves out the code, it is only shown once when the
+// invite is created
+func newInviteResponse(invite db.Invite) inviteResponse {
+	return inviteResponse{
+		ID:        invite.ID,
+		Role:      invite.Role,
+		ClassID:   invite.ClassID.Int64,
+		CreatedBy: invite.CreatedBy,
+		MaxUses:   invite.MaxUses,
+		Uses:      invite.Uses,
+		ExpiresAt: invite.ExpiresAt,
+		RevokedAt: invite.RevokedAt,
+		CreatedAt: invite.CreatedAt,
+	}
+}
+
+// canManageInvite reports whether the logged in user created the invite or
+// may manage every invite
+func canManageInvite(authPayload *token.Payload, invite db.Invite) bool {
+	return invite.CreatedBy == authPayload.Userid || hasPermission(authPayload.Role, permissionManageInvites)
+}
+
+type createInviteRequest struct {
+	Role      string `json:"role" binding:"required,oneof=teacher student"`
+	ClassID   int64  `json:"class_id" binding:"omitempty,min=1"`
+	MaxUses   int32  `json:"max_uses" binding:"required,min=1,max=1000"`
+	ValidDays int32  `json:"valid_days" binding:"required,min=1,max=90"`
+}
+
+// createInvite lets admins invite teachers and students of any class, and
+// teachers invite students of their own classes
+func (server *Server) createInvite(ctx *gin.Context) {
+	var req createInviteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	canManageInvites := hasPermission(authPayload.Role, permissionManageInvites)
+
+	var classID sql.NullInt64
+	switch req.Role {
+	case util.RoleTeacher:
+		if !canManageInvites {
+			err := errors.New("permission denied!")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+	case util.RoleStudent:
+		if req.ClassID == 0 {
+			err := errors.New("student invites need a class!")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		class, err := server.store.GetClass(ctx, req.ClassID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if class.TeacherID != authPayload.Userid && !canManageInvites {
+			err := errors.New("permission denied!")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		classID = sql.NullInt64{Int64: class.ID, Valid: true}
+	}
+
+	code, err := util.NewSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	invite, err := server.store.CreateInvite(ctx, db.CreateInviteParams{
+		CodeHash:  util.HashSecret(code),
+		Role:      req.Role,
+		ClassID:   classID,
+		CreatedBy: authPayload.Userid,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: time.Now().AddDate(0, 0, int(req.ValidDays)),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := newInviteResponse(invite)
+	rsp.Code = code
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type listInvitesRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=20"`
+}
+
+// listInvites lists every invite for admins, and the own invites for others
+func (server *Server) listInvites(ctx *gin.Context) {
+	var req listInvitesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var invites []db.Invite
+	var err error
+	if hasPermission(authPayload.Role, permissionManageInvites) {
+		invites, err = server.store.ListInvites(ctx, db.ListInvitesParams{
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
+	} else {
+		invites, err = server.store.ListInvitesByCreator(ctx, db.ListInvitesByCreatorParams{
+			CreatedBy: authPayload.Userid,
+			Limit:     req.PageSize,
+			Offset:    (req.PageID - 1) * req.PageSize,
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]inviteResponse, 0, len(invites))
+	for _, invite := range invites {
+		rsp = append(rsp, newInviteResponse(invite))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type getInviteRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getManagedInvite loads an invite the logged in user may manage. It writes
+// the error response itself.
+func (server *Server) getManagedInvite(ctx *gin.Context, inviteID int64) (db.Invite, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	invite, err := server.store.GetInvite(ctx, inviteID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Invite{}, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Invite{}, false
+	}
+
+	if !canManageInvite(authPayload, invite) {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return db.Invite{}, false
+	}
+
+	return invite, true
+}
+
+// revokeInvite stops an invite from being redeemed, accounts already created
+// with it stay as they are
+func (server *Server) revokeInvite(ctx *gin.Context) {
+	var req getInviteRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.getManagedInvite(ctx, req.ID); !ok {
+		return
+	}
+
+	invite, err := server.store.RevokeInvite(ctx, db.RevokeInviteParams{
+		ID:        req.ID,
+		RevokedAt: time.Now(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("invite is already revoked!")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newInviteResponse(invite))
+}
+
+type listInviteRedemptionsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=20"`
+}
+
+// listInviteRedemptions shows who redeemed the invite and when
+func (server *Server) listInviteRedemptions(ctx *gin.Context) {
+	var reqURI getInviteRequest
+	if err := ctx.ShouldBindUri(&reqURI); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listInviteRedemptionsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.getManagedInvite(ctx, reqURI.ID); !ok {
+		return
+	}
+
+	redemptions, err := server.store.ListInviteRedemptions(ctx, db.ListInviteRedemptionsParams{
+		InviteID: reqURI.ID,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, redemptions)
+}
+
+type redeemInviteRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// redeemInvite enrolls the logged in user in the class of a student invite
+func (server *Server) redeemInvite(ctx *gin.Context) {
+	var req redeemInviteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	member, err := server.store.JoinClassWithInviteTx(ctx, db.JoinClassWithInviteTxParams{
+		CodeHash:   util.HashSecret(req.Code),
+		UserID:     authPayload.Userid,
+		RedeemedAt: time.Now(),
+	})
+	if err != nil {
+		if err == db.ErrInviteInvalid {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("user is already enrolled!")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
@@ -0,0 +1,599 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func randomInvite(createdBy int64, classID int64) db.Invite {
+	invite := db.Invite{
+		ID:        util.RandomInt(1, 1000),
+		CodeHash:  util.HashSecret(util.RandomString(32)),
+		Role:      util.RoleTeacher,
+		CreatedBy: createdBy,
+		MaxUses:   5,
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+	if classID != 0 {
+		invite.Role = util.RoleStudent
+		invite.ClassID = sql.NullInt64{Int64: classID, Valid: true}
+	}
+	return invite
+}
+
+func TestCreateInviteAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	teacher, _ := randomTeacherUser(t)
+	class := db.Class{ID: util.RandomInt(1, 100), TeacherID: teacher.ID, Name: util.RandomString(8)}
+	otherClass := db.Class{ID: class.ID + 1, TeacherID: teacher.ID + 1, Name: util.RandomString(8)}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "AdminInvitesTeacher",
+			user: admin,
+			body: gin.H{"role": util.RoleTeacher, "max_uses": 1, "valid_days": 7},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateInviteParams) (db.Invite, error) {
+						require.Equal(t, util.RoleTeacher, arg.Role)
+						require.False(t, arg.ClassID.Valid)
+						require.Equal(t, admin.ID, arg.CreatedBy)
+						require.Equal(t, int32(1), arg.MaxUses)
+						require.WithinDuration(t, time.Now().AddDate(0, 0, 7), arg.ExpiresAt, time.Second)
+
+						return db.Invite{
+							ID:        1,
+							CodeHash:  arg.CodeHash,
+							Role:      arg.Role,
+							CreatedBy: arg.CreatedBy,
+							MaxUses:   arg.MaxUses,
+							ExpiresAt: arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp inviteResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.Code)
+				require.Equal(t, util.RoleTeacher, rsp.Role)
+				require.NotContains(t, recorder.Body.String(), util.HashSecret(rsp.Code))
+			},
+		},
+		{
+			name: "TeacherInvitesTeacher",
+			user: teacher,
+			body: gin.H{"role": util.RoleTeacher, "max_uses": 1, "valid_days": 7},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "TeacherInvitesStudents",
+			user: teacher,
+			body: gin.H{"role": util.RoleStudent, "class_id": class.ID, "max_uses": 30, "valid_days": 14},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetClass(gomock.Any(), gomock.Eq(class.ID)).
+					Times(1).
+					Return(class, nil)
+				store.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateInviteParams) (db.Invite, error) {
+						require.Equal(t, util.RoleStudent, arg.Role)
+						require.Equal(t, sql.NullInt64{Int64: class.ID, Valid: true}, arg.ClassID)
+						return db.Invite{ID: 1, Role: arg.Role, ClassID: arg.ClassID}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp inviteResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, class.ID, rsp.ClassID)
+			},
+		},
+		{
+			name: "AdminInvitesStudents",
+			user: admin,
+			body: gin.H{"role": util.RoleStudent, "class_id": otherClass.ID, "max_uses": 30, "valid_days": 14},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetClass(gomock.Any(), gomock.Eq(otherClass.ID)).
+					Times(1).
+					Return(otherClass, nil)
+				store.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Invite{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OtherTeachersClass",
+			user: teacher,
+			body: gin.H{"role": util.RoleStudent, "class_id": otherClass.ID, "max_uses": 30, "valid_days": 14},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetClass(gomock.Any(), gomock.Eq(otherClass.ID)).
+					Times(1).
+					Return(otherClass, nil)
+				store.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "StudentInviteWithoutClass",
+			user: teacher,
+			body: gin.H{"role": util.RoleStudent, "max_uses": 30, "valid_days": 14},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetClass(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ClassNotFound",
+			user: teacher,
+			body: gin.H{"role": util.RoleStudent, "class_id": class.ID, "max_uses": 30, "valid_days": 14},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetClass(gomock.Any(), gomock.Eq(class.ID)).
+					Times(1).
+					Return(db.Class{}, sql.ErrNoRows)
+				store.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRole",
+			user: admin,
+			body: gin.H{"role": util.RoleAdmin, "max_uses": 1, "valid_days": 7},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooLong",
+			user: admin,
+			body: gin.H{"role": util.RoleTeacher, "max_uses": 1, "valid_days": 365},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInvite(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/invites/create", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.user.ID, tc.user.Username.String, tc.user.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRedeemInviteAPI(t *testing.T) {
+	student, _ := randomStudentUser(t)
+	code := util.RandomString(32)
+	member := db.ClassMember{ClassID: util.RandomInt(1, 100), UserID: student.ID}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().JoinClassWithInviteTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.JoinClassWithInviteTxParams) (db.ClassMember, error) {
+						require.Equal(t, util.HashSecret(code), arg.CodeHash)
+						require.Equal(t, student.ID, arg.UserID)
+						require.WithinDuration(t, time.Now(), arg.RedeemedAt, time.Second)
+						return member, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp db.ClassMember
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, member.ClassID, rsp.ClassID)
+			},
+		},
+		{
+			name: "InvalidInvite",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().JoinClassWithInviteTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ClassMember{}, db.ErrInviteInvalid)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), db.ErrInviteInvalid.Error())
+			},
+		},
+		{
+			name: "AlreadyEnrolled",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().JoinClassWithInviteTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ClassMember{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": code})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/invites/redeem", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListInvitesAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	teacher, _ := randomTeacherUser(t)
+	invites := []db.Invite{
+		randomInvite(teacher.ID, 0),
+		randomInvite(teacher.ID, util.RandomInt(1, 100)),
+	}
+
+	testCases := []struct {
+		name       string
+		user       db.User
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Admin",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListInvites(gomock.Any(), gomock.Eq(db.ListInvitesParams{Limit: 5, Offset: 0})).
+					Times(1).
+					Return(invites, nil)
+				store.EXPECT().ListInvitesByCreator(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+		{
+			name: "Teacher",
+			user: teacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListInvites(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().ListInvitesByCreator(gomock.Any(), gomock.Eq(db.ListInvitesByCreatorParams{
+					CreatedBy: teacher.ID,
+					Limit:     5,
+					Offset:    0,
+				})).
+					Times(1).
+					Return(invites, nil)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/invites?page_id=1&page_size=5", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.user.ID, tc.user.Username.String, tc.user.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var rsp []inviteResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+			require.Len(t, rsp, len(invites))
+			require.Empty(t, rsp[0].Code)
+			require.NotContains(t, recorder.Body.String(), invites[0].CodeHash)
+		})
+	}
+}
+
+func TestRevokeInviteAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	teacher, _ := randomTeacherUser(t)
+	otherTeacher, _ := randomTeacherUser(t)
+	otherTeacher.ID = teacher.ID + 1
+	invite := randomInvite(teacher.ID, util.RandomInt(1, 100))
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Creator",
+			user: teacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvite(gomock.Any(), gomock.Eq(invite.ID)).
+					Times(1).
+					Return(invite, nil)
+				store.EXPECT().RevokeInvite(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.RevokeInviteParams) (db.Invite, error) {
+						require.Equal(t, invite.ID, arg.ID)
+						require.WithinDuration(t, time.Now(), arg.RevokedAt, time.Second)
+
+						revoked := invite
+						revoked.RevokedAt = arg.RevokedAt
+						return revoked, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp inviteResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.False(t, rsp.RevokedAt.IsZero())
+			},
+		},
+		{
+			name: "Admin",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvite(gomock.Any(), gomock.Eq(invite.ID)).
+					Times(1).
+					Return(invite, nil)
+				store.EXPECT().RevokeInvite(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(invite, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OtherTeacher",
+			user: otherTeacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvite(gomock.Any(), gomock.Eq(invite.ID)).
+					Times(1).
+					Return(invite, nil)
+				store.EXPECT().RevokeInvite(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyRevoked",
+			user: teacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvite(gomock.Any(), gomock.Eq(invite.ID)).
+					Times(1).
+					Return(invite, nil)
+				store.EXPECT().RevokeInvite(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Invite{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvite(gomock.Any(), gomock.Eq(invite.ID)).
+					Times(1).
+					Return(db.Invite{}, sql.ErrNoRows)
+				store.EXPECT().RevokeInvite(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/invites/%d", invite.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.user.ID, tc.user.Username.String, tc.user.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListInviteRedemptionsAPI(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+	student, _ := randomStudentUser(t)
+	student.ID = teacher.ID + 1
+	invite := randomInvite(teacher.ID, util.RandomInt(1, 100))
+	redemptions := []db.InviteRedemption{
+		{InviteID: invite.ID, UserID: student.ID, RedeemedAt: time.Now()},
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: teacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvite(gomock.Any(), gomock.Eq(invite.ID)).
+					Times(1).
+					Return(invite, nil)
+				store.EXPECT().ListInviteRedemptions(gomock.Any(), gomock.Eq(db.ListInviteRedemptionsParams{
+					InviteID: invite.ID,
+					Limit:    5,
+					Offset:   0,
+				})).
+					Times(1).
+					Return(redemptions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []db.InviteRedemption
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp, 1)
+				require.Equal(t, student.ID, rsp[0].UserID)
+			},
+		},
+		{
+			name: "Redeemer",
+			user: student,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInvite(gomock.Any(), gomock.Eq(invite.ID)).
+					Times(1).
+					Return(invite, nil)
+				store.EXPECT().ListInviteRedemptions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/invites/%d/redemptions?page_id=1&page_size=5", invite.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.user.ID, tc.user.Username.String, tc.user.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
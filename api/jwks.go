@@ -0,0 +1,24 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/gin-gonic/gin"
+)
+
+// getJWKS publishes the keys access tokens are verified with so other services
+// can check them without calling this one. Tokens of the PASETO makers can not
+// be checked that way.
+func (server *Server) getJWKS(ctx *gin.Context) {
+	publisher, ok := server.tokenMaker.(token.KeyPublisher)
+	if !ok {
+		err := errors.New("token keys are not published!")
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, publisher.JWKS())
+}
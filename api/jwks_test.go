@@ -0,0 +1,42 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJWKSAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var jwks token.JSONWebKeySet
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &jwks))
+	require.Len(t, jwks.Keys, 1)
+
+	accessToken, _, err := server.tokenMaker.CreateToken(1, "user", util.RoleStudent, uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	jwtToken, _, err := new(jwt.Parser).ParseUnverified(accessToken, &token.Payload{})
+	require.NoError(t, err)
+	require.Equal(t, jwks.Keys[0].KeyID, jwtToken.Header["kid"])
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLoginMaxAttempts      = 5
+	defaultLoginMaxAttemptsPerIP = 50
+	defaultLoginLockout          = time.Minute
+	defaultLoginMaxLockout       = time.Hour
+)
+
+var errInvalidCredentials = errors.New("invalid credentials!")
+
+// dummyPasswordHash is checked against when the username is unknown, so that
+// answer takes as long as a wrong password
+var dummyPasswordHash, _ = util.HashPassword("dummy password")
+
+func loginUsernameKey(username string) string {
+	return "username:" + username
+}
+
+func loginIPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// loginLockedUntil returns until when logins for the username or from the ip
+// are locked, the zero time when they are not
+func (server *Server) loginLockedUntil(ctx context.Context, username string, ip string) (time.Time, error) {
+	var lockedUntil time.Time
+
+	for _, key := range []string{loginUsernameKey(username), loginIPKey(ip)} {
+		failure, err := server.store.GetLoginFailure(ctx, key)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return time.Time{}, err
+		}
+
+		if failure.LockedUntil.After(time.Now()) && failure.LockedUntil.After(lockedUntil) {
+			lockedUntil = failure.LockedUntil
+		}
+	}
+
+	return lockedUntil, nil
+}
+
+// recordLoginFailure counts a failed login for the username and the ip, and
+// locks them out once they are over their limit. Every further failure
+// doubles the lockout.
+func (server *Server) recordLoginFailure(ctx context.Context, username string, ip string) error {
+	maxLockout := server.config.LoginMaxLockout
+	if maxLockout <= 0 {
+		maxLockout = defaultLoginMaxLockout
+	}
+
+	limits := []struct {
+		key         string
+		maxAttempts int
+	}{
+		{loginUsernameKey(username), server.config.LoginMaxAttempts},
+		{loginIPKey(ip), server.config.LoginMaxAttemptsPerIP},
+	}
+	if limits[0].maxAttempts <= 0 {
+		limits[0].maxAttempts = defaultLoginMaxAttempts
+	}
+	if limits[1].maxAttempts <= 0 {
+		limits[1].maxAttempts = defaultLoginMaxAttemptsPerIP
+	}
+
+	now := time.Now()
+	for _, limit := range limits {
+		// failures older than the longest lockout are forgotten
+		failure, err := server.store.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+			Key:          limit.key,
+			LastFailedAt: now,
+			WindowStart:  now.Add(-maxLockout),
+		})
+		if err != nil {
+			return err
+		}
+
+		lockout := loginLockout(int(failure.FailedCount), limit.maxAttempts, server.config.LoginLockout, maxLockout)
+		if lockout == 0 {
+			continue
+		}
+
+		err = server.store.LockLogin(ctx, db.LockLoginParams{
+			Key:         limit.key,
+			LockedUntil: now.Add(lockout),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loginLockout returns how long to lock logins out after failedCount failures
+func loginLockout(failedCount int, maxAttempts int, lockout time.Duration, maxLockout time.Duration) time.Duration {
+	if failedCount < maxAttempts {
+		return 0
+	}
+	if lockout <= 0 {
+		lockout = defaultLoginLockout
+	}
+
+	exponent := float64(failedCount - maxAttempts)
+	duration := float64(lockout) * math.Pow(2, exponent)
+	if duration > float64(maxLockout) {
+		return maxLockout
+	}
+	return time.Duration(duration)
+}
+
+// abortLockedLogin answers a login attempt made during a lockout
+func abortLockedLogin(ctx *gin.Context, lockedUntil time.Time) {
+	retryAfter := int(math.Ceil(time.Until(lockedUntil).Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	err := fmt.Errorf("too many failed login attempts, try again in %d seconds!", retryAfter)
+	ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+}
+
+type unlockUserRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// unlockUser clears the failed logins of a user, lifting a lockout. Lockouts
+// of client ips run out on their own.
+func (server *Server) unlockUser(ctx *gin.Context) {
+	var req unlockUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.store.DeleteLoginFailure(ctx, loginUsernameKey(user.Username.String))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+}
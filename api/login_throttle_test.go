@@ -0,0 +1,184 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginLockout(t *testing.T) {
+	testCases := []struct {
+		failedCount int
+		lockout     time.Duration
+	}{
+		{failedCount: 1, lockout: 0},
+		{failedCount: 4, lockout: 0},
+		{failedCount: 5, lockout: time.Minute},
+		{failedCount: 6, lockout: 2 * time.Minute},
+		{failedCount: 8, lockout: 8 * time.Minute},
+		{failedCount: 100, lockout: time.Hour},
+	}
+
+	for _, tc := range testCases {
+		lockout := loginLockout(tc.failedCount, 5, time.Minute, time.Hour)
+		require.Equal(t, tc.lockout, lockout, "after %d failures", tc.failedCount)
+	}
+}
+
+func TestLoginThrottleClientIP(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		ip             string
+	}{
+		{
+			// by default nobody is trusted to tell the client ip
+			name: "SpoofedForwardedFor",
+			ip:   "192.0.2.1",
+		},
+		{
+			name:           "TrustedProxy",
+			trustedProxies: []string{"192.0.2.1"},
+			ip:             "203.0.113.7",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			username := util.RandomString(8)
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetLoginFailure(gomock.Any(), gomock.Eq(loginUsernameKey(username))).
+				Times(1).
+				Return(db.LoginFailure{}, sql.ErrNoRows)
+			store.EXPECT().GetLoginFailure(gomock.Any(), gomock.Eq(loginIPKey(tc.ip))).
+				Times(1).
+				Return(db.LoginFailure{}, sql.ErrNoRows)
+			store.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+			store.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any()).
+				Times(2).
+				DoAndReturn(func(ctx context.Context, arg db.RecordLoginFailureParams) (db.LoginFailure, error) {
+					require.Contains(t, []string{loginUsernameKey(username), loginIPKey(tc.ip)}, arg.Key)
+					return db.LoginFailure{Key: arg.Key, FailedCount: 1}, nil
+				})
+
+			server := newTestServer(t, store)
+			server.config.TrustedProxies = tc.trustedProxies
+			require.NoError(t, server.setupRouter())
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"username": username, "password": util.RandomString(8)})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.RemoteAddr = "192.0.2.1:1234"
+			request.Header.Set("X-Forwarded-For", "203.0.113.7")
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		})
+	}
+}
+
+func TestUnlockUserAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	student, _ := randomStudentUser(t)
+	student.ID = admin.ID + 1
+
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			authUser: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(student, nil)
+				store.EXPECT().DeleteLoginFailure(gomock.Any(), gomock.Eq(loginUsernameKey(student.Username.String))).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotAdmin",
+			authUser: student,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			authUser: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%d/lockout", student.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.authUser.ID, tc.authUser.Username.String, tc.authUser.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
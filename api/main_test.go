@@ -1,28 +1,54 @@
 package api
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
 
 	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/mail"
+	"github.com/dongocanh96/class_manager_go/storage"
 	"github.com/dongocanh96/class_manager_go/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
+// testSessions backs the session check of test servers, addAuthorization
+// registers the session of every access token it creates
+var testSessions = map[uuid.UUID]db.GetSessionAuthRow{}
+
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		SignUpKeyForTeacher: "5WC7CnJ99KBhyPF",
-		PrivateKeyLocation:  "../private.pem",
-		PublicKeyLocation:   "../public.pem",
-		AccessTokenDuration: time.Minute * 15,
-		Asset:               "./asset/",
+		PrivateKeyLocation:    "../private.pem",
+		AccessTokenDuration:   time.Minute * 15,
+		PasswordResetDuration: time.Minute * 15,
+		ImportInviteDuration:  time.Hour * 24 * 7,
+		EmailVerifyDuration:   time.Hour * 24,
+		MFAChallengeDuration:  time.Minute * 5,
+		PasswordMinLength:     6,
+		PasswordMinClasses:    1,
+		PublicURL:             "http://localhost:8080",
+		Asset:                 t.TempDir(),
 	}
 
-	server, err := NewServer(config, store)
+	fileStore, err := storage.NewLocalStore(config.Asset)
+	require.NoError(t, err)
+
+	mailer, err := mail.NewLogMailer("")
 	require.NoError(t, err)
 
+	server, err := NewServer(config, store, fileStore, mailer)
+	require.NoError(t, err)
+
+	server.sessions.load = func(ctx context.Context, id uuid.UUID) (db.GetSessionAuthRow, error) {
+		if session, ok := testSessions[id]; ok {
+			return session, nil
+		}
+		return store.GetSessionAuth(ctx, id)
+	}
+
 	return server
 }
 
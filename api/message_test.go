@@ -28,7 +28,7 @@ func TestCreateMessage(t *testing.T) {
 	testCases := []struct {
 		name          string
 		body          gin.H
-		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker)
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(recoder *httptest.ResponseRecorder)
 	}{
@@ -38,9 +38,9 @@ func TestCreateMessage(t *testing.T) {
 				"to_user_id": user2.ID,
 				"content":    message.Content,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -64,9 +64,9 @@ func TestCreateMessage(t *testing.T) {
 		{
 			name: "MissingRequestParamError",
 			body: gin.H{},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -81,7 +81,7 @@ func TestCreateMessage(t *testing.T) {
 				"to_user_id": user2.ID,
 				"content":    message.Content,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
@@ -96,9 +96,9 @@ func TestCreateMessage(t *testing.T) {
 				"to_user_id": user2.ID,
 				"content":    message.Content,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user2.ID, user2.Username.String, user2.IsTeacher,
+					authorizationTypeBearer, user2.ID, user2.Username.String, user2.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -113,9 +113,9 @@ func TestCreateMessage(t *testing.T) {
 				"to_user_id": user2.ID,
 				"content":    message.Content,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -133,9 +133,9 @@ func TestCreateMessage(t *testing.T) {
 				"to_user_id": user2.ID,
 				"content":    message.Content,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -153,9 +153,9 @@ func TestCreateMessage(t *testing.T) {
 				"to_user_id": user2.ID,
 				"content":    message.Content,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -240,16 +240,16 @@ func TestGetMessage(t *testing.T) {
 	testCases := []struct {
 		name          string
 		messageID     int64
-		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker)
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(recoder *httptest.ResponseRecorder)
 	}{
 		{
 			name:      "OK",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -266,9 +266,9 @@ func TestGetMessage(t *testing.T) {
 		{
 			name:      "ReadedOk",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user2.ID, user2.Username.String, user2.IsTeacher,
+					authorizationTypeBearer, user2.ID, user2.Username.String, user2.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -306,9 +306,9 @@ func TestGetMessage(t *testing.T) {
 		{
 			name:      "InvalidID",
 			messageID: 0,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -320,7 +320,7 @@ func TestGetMessage(t *testing.T) {
 		{
 			name:      "NoAuthorization",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 			},
@@ -331,9 +331,9 @@ func TestGetMessage(t *testing.T) {
 		{
 			name:      "UnAuthorizedUser",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, 0, "UnAuthorizedUser", user1.IsTeacher,
+					authorizationTypeBearer, 0, "UnAuthorizedUser", user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -349,9 +349,9 @@ func TestGetMessage(t *testing.T) {
 		{
 			name:      "MessageNotFound",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -367,9 +367,9 @@ func TestGetMessage(t *testing.T) {
 		{
 			name:      "InternalServerError",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -385,9 +385,9 @@ func TestGetMessage(t *testing.T) {
 		{
 			name:      "ReadGetInternalServerError",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user2.ID, user2.Username.String, user2.IsTeacher,
+					authorizationTypeBearer, user2.ID, user2.Username.String, user2.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -450,7 +450,7 @@ func TestListMessage(t *testing.T) {
 	testCases := []struct {
 		name          string
 		query         Query
-		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker)
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(recoder *httptest.ResponseRecorder)
 	}{
@@ -461,9 +461,9 @@ func TestListMessage(t *testing.T) {
 				pageID:   1,
 				pageSize: n,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -491,9 +491,9 @@ func TestListMessage(t *testing.T) {
 				pageID:   1,
 				pageSize: n,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -510,9 +510,9 @@ func TestListMessage(t *testing.T) {
 				pageID:   -1,
 				pageSize: n,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -529,9 +529,9 @@ func TestListMessage(t *testing.T) {
 				pageID:   1,
 				pageSize: 1000,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -583,7 +583,7 @@ func TestUpdateMessage(t *testing.T) {
 		name          string
 		messageID     int64
 		body          gin.H
-		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker)
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -593,9 +593,9 @@ func TestUpdateMessage(t *testing.T) {
 			body: gin.H{
 				"content": content,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -633,9 +633,9 @@ func TestUpdateMessage(t *testing.T) {
 			body: gin.H{
 				"content": content,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -654,9 +654,9 @@ func TestUpdateMessage(t *testing.T) {
 			body: gin.H{
 				"content": content,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user2.ID, user2.Username.String, user2.IsTeacher,
+					authorizationTypeBearer, user2.ID, user2.Username.String, user2.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -706,16 +706,16 @@ func TestDeleteMessage(t *testing.T) {
 	testCases := []struct {
 		name          string
 		messageID     int64
-		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker)
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name:      "OK",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -733,9 +733,9 @@ func TestDeleteMessage(t *testing.T) {
 		{
 			name:      "UnauthorizedUser",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user2.ID, user2.Username.String, user2.IsTeacher,
+					authorizationTypeBearer, user2.ID, user2.Username.String, user2.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -753,9 +753,9 @@ func TestDeleteMessage(t *testing.T) {
 		{
 			name:      "NotFound",
 			messageID: message.ID,
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, user1.ID, user1.Username.String, user1.IsTeacher,
+					authorizationTypeBearer, user1.ID, user1.Username.String, user1.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -808,7 +808,7 @@ func randomUser(t *testing.T) (user db.User) {
 		Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
 		Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
 		PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
-		IsTeacher:      util.RandomBoolean(),
+		Role:           util.RandomRole(),
 	}
 
 	return
@@ -1,13 +1,16 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/dongocanh96/class_manager_go/token"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
@@ -16,7 +19,11 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenMaker token.JWTMaker) gin.HandlerFunc {
+// authMiddleware accepts an access token only while its session is active and
+// the password has not changed since it was issued. The user and the role are
+// taken from the session, not the token, so a rename, promotion or demotion
+// applies right away.
+func authMiddleware(tokenMaker token.Maker, sessions *sessionCache) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
@@ -46,6 +53,35 @@ func authMiddleware(tokenMaker token.JWTMaker) gin.HandlerFunc {
 			return
 		}
 
+		// refresh tokens are not bound to a session and can not be used here
+		if payload.SessionID == uuid.Nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(token.ErrInvalidToken))
+			return
+		}
+
+		session, err := sessions.get(ctx, payload.SessionID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(token.ErrRevokedToken))
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		// some tokens only keep whole seconds, a login in the second the
+		// password was changed must not be taken for an older one
+		passwordChangedAt := session.PasswordChangedAt.Truncate(time.Second)
+		if session.IsBlocked || time.Now().After(session.ExpiresAt) || payload.IssuedAt.Before(passwordChangedAt) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(token.ErrRevokedToken))
+			return
+		}
+
+		payload.Userid = session.UserID
+		payload.Username = session.Username
+		payload.Role = session.Role
+
 		ctx.Set(authorizationPayloadKey, payload)
 		ctx.Next()
 	}
@@ -1,31 +1,49 @@
 package api
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
 	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/dongocanh96/class_manager_go/util"
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
 func addAuthorization(
 	t *testing.T,
 	request *http.Request,
-	tokenMaker token.JWTMaker,
+	tokenMaker token.Maker,
 	authorizationType string,
 	userid int64,
 	username string,
-	isteacher bool,
+	role string,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateToken(userid, username, isteacher, duration)
+	token, payload, err := tokenMaker.CreateToken(userid, username, role, uuid.New(), duration)
 	require.NotEmpty(t, payload)
 	require.NoError(t, err)
 
+	testSessions[payload.SessionID] = db.GetSessionAuthRow{
+		ID:        payload.SessionID,
+		Username:  username,
+		FamilyID:  payload.SessionID,
+		ExpiresAt: time.Now().Add(time.Hour),
+		UserID:    userid,
+		Role:      role,
+	}
+
 	authorizationHeader := fmt.Sprintf("%s %s", authorizationType, token)
 	request.Header.Set(authorizationHeaderKey, authorizationHeader)
 }
@@ -33,13 +51,13 @@ func addAuthorization(
 func TestAuthMiddle(t *testing.T) {
 	testCases := []struct {
 		name          string
-		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker)
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, 1, "user", true, time.Minute*15)
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, 1, "user", util.RoleStudent, time.Minute*15)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -47,7 +65,7 @@ func TestAuthMiddle(t *testing.T) {
 		},
 		{
 			name: "NoAuthorization",
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -55,8 +73,8 @@ func TestAuthMiddle(t *testing.T) {
 		},
 		{
 			name: "UnsupportedAuthorization",
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
-				addAuthorization(t, request, tokenMaker, "unsupport", 1, "user", true, time.Minute*15)
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, "unsupport", 1, "user", util.RoleStudent, time.Minute*15)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -64,8 +82,8 @@ func TestAuthMiddle(t *testing.T) {
 		},
 		{
 			name: "InvalidAuthorizationFormat",
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
-				addAuthorization(t, request, tokenMaker, "", 1, "user", true, time.Minute*15)
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, "", 1, "user", util.RoleStudent, time.Minute*15)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -73,8 +91,8 @@ func TestAuthMiddle(t *testing.T) {
 		},
 		{
 			name: "ExpiredToken",
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, 1, "user", true, -time.Minute)
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, 1, "user", util.RoleStudent, -time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -86,12 +104,15 @@ func TestAuthMiddle(t *testing.T) {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			server := newTestServer(t, nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := newTestServer(t, mockdb.NewMockStore(ctrl))
 
 			authPath := "/auth"
 			server.router.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, server.sessions),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -107,3 +128,207 @@ func TestAuthMiddle(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthMiddlewareRevocation(t *testing.T) {
+	sessionID := uuid.New()
+
+	testCases := []struct {
+		name          string
+		sessionID     uuid.UUID
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "RoleFromSession",
+			sessionID: sessionID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSessionAuth(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionAuthRow{
+						ID:        sessionID,
+						ExpiresAt: time.Now().Add(time.Hour),
+						Role:      util.RoleTeacher,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp gin.H
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, util.RoleTeacher, rsp["role"])
+			},
+		},
+		{
+			name:      "RefreshToken",
+			sessionID: uuid.Nil,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSessionAuth(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "SessionDeleted",
+			sessionID: sessionID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSessionAuth(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionAuthRow{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "SessionBlocked",
+			sessionID: sessionID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSessionAuth(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionAuthRow{
+						ID:        sessionID,
+						IsBlocked: true,
+						ExpiresAt: time.Now().Add(time.Hour),
+						Role:      util.RoleStudent,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "PasswordChanged",
+			sessionID: sessionID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSessionAuth(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionAuthRow{
+						ID:                sessionID,
+						ExpiresAt:         time.Now().Add(time.Hour),
+						Role:              util.RoleStudent,
+						PasswordChangedAt: time.Now().Add(time.Minute),
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			authPath := "/auth"
+			server.router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker, server.sessions),
+				func(ctx *gin.Context) {
+					authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+					ctx.JSON(http.StatusOK, gin.H{"role": authPayload.Role})
+				},
+			)
+
+			accessToken, _, err := server.tokenMaker.CreateToken(1, "user", util.RoleStudent, tc.sessionID, time.Minute)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthMiddlewarePasetoPasswordChanged(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	localMaker, err := token.NewPasetoLocalMaker([]byte(util.RandomString(32)))
+	require.NoError(t, err)
+	publicMaker, err := token.NewPasetoPublicMaker(privateKey)
+	require.NoError(t, err)
+
+	makers := map[string]token.Maker{
+		token.TypePasetoLocal:  localMaker,
+		token.TypePasetoPublic: publicMaker,
+	}
+
+	for name, maker := range makers {
+		maker := maker
+
+		t.Run(name, func(t *testing.T) {
+			sessionID := uuid.New()
+			accessToken, payload, err := maker.CreateToken(1, "user", util.RoleStudent, sessionID, time.Minute)
+			require.NoError(t, err)
+
+			testCases := []struct {
+				name              string
+				passwordChangedAt time.Time
+				status            int
+			}{
+				{
+					// the token keeps whole seconds, the login came after the
+					// change within the same second
+					name:              "SameSecond",
+					passwordChangedAt: payload.IssuedAt.Add(400 * time.Millisecond),
+					status:            http.StatusOK,
+				},
+				{
+					name:              "Later",
+					passwordChangedAt: payload.IssuedAt.Add(time.Second),
+					status:            http.StatusUnauthorized,
+				},
+			}
+
+			for _, tc := range testCases {
+				ctrl := gomock.NewController(t)
+
+				store := mockdb.NewMockStore(ctrl)
+				store.EXPECT().GetSessionAuth(gomock.Any(), gomock.Eq(sessionID)).
+					Times(1).
+					Return(db.GetSessionAuthRow{
+						ID:                sessionID,
+						ExpiresAt:         time.Now().Add(time.Hour),
+						Role:              util.RoleStudent,
+						PasswordChangedAt: tc.passwordChangedAt,
+					}, nil)
+
+				server := newTestServer(t, store)
+				server.tokenMaker = maker
+
+				authPath := "/auth"
+				server.router.GET(
+					authPath,
+					authMiddleware(server.tokenMaker, server.sessions),
+					func(ctx *gin.Context) {
+						ctx.JSON(http.StatusOK, nil)
+					},
+				)
+
+				recorder := httptest.NewRecorder()
+				request, err := http.NewRequest(http.MethodGet, authPath, nil)
+				require.NoError(t, err)
+
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+				server.router.ServeHTTP(recorder, request)
+				require.Equal(t, tc.status, recorder.Code, tc.name)
+
+				ctrl.Finish()
+			}
+		})
+	}
+}
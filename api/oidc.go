@@ -0,0 +1,288 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/oidc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcStateCookie   = "oidc_state"
+	oidcLoginDuration = 10 * time.Minute
+	maxUsernameLength = 20
+)
+
+var (
+	errOIDCNotConfigured = errors.New("single sign-on is not configured!")
+	errOIDCLoginInvalid  = errors.New("single sign-on login is invalid or expired!")
+)
+
+// oidcRedirectURL returns where the provider sends users back to, by default
+// the callback under the public url of the server
+func oidcRedirectURL(config util.Config) string {
+	if config.OIDCRedirectURL != "" {
+		return config.OIDCRedirectURL
+	}
+	return strings.TrimSuffix(config.PublicURL, "/") + "/oidc/callback"
+}
+
+// setOIDCStateCookie binds a login to the browser that started it, so a
+// callback url of somebody else's login can not be used to log a user in
+func (server *Server) setOIDCStateCookie(ctx *gin.Context, state string, maxAge int) {
+	secure := strings.HasPrefix(server.config.PublicURL, "https://")
+
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcStateCookie, state, maxAge, "/oidc", "", secure, true)
+}
+
+// oidcLogin redirects the user to the provider
+func (server *Server) oidcLogin(ctx *gin.Context) {
+	if server.oidcProvider == nil {
+		ctx.JSON(http.StatusNotFound, errorResponse(errOIDCNotConfigured))
+		return
+	}
+
+	state, err := util.NewSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	nonce, err := util.NewSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	codeVerifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authURL, err := server.oidcProvider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.CreateOIDCLogin(ctx, db.CreateOIDCLoginParams{
+		StateHash:    util.HashSecret(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(oidcLoginDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.setOIDCStateCookie(ctx, state, int(oidcLoginDuration/time.Second))
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+type oidcCallbackRequest struct {
+	Code             string `form:"code"`
+	State            string `form:"state" binding:"required"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// oidcCallback redeems the code the provider sent the user back with and logs
+// in the user the identity belongs to
+func (server *Server) oidcCallback(ctx *gin.Context) {
+	if server.oidcProvider == nil {
+		ctx.JSON(http.StatusNotFound, errorResponse(errOIDCNotConfigured))
+		return
+	}
+
+	var req oidcCallbackRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	state, err := ctx.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(state), []byte(req.State)) != 1 {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errOIDCLoginInvalid))
+		return
+	}
+	server.setOIDCStateCookie(ctx, "", -1)
+
+	login, err := server.store.UseOIDCLogin(ctx, db.UseOIDCLoginParams{
+		StateHash: util.HashSecret(req.State),
+		UsedAt:    time.Now(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errOIDCLoginInvalid))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if req.Error != "" {
+		err := fmt.Errorf("single sign-on failed: %s %s", req.Error, req.ErrorDescription)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if req.Code == "" {
+		err := errors.New("code is required!")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	idToken, err := server.oidcProvider.Exchange(ctx, req.Code, login.CodeVerifier)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	claims, err := server.oidcProvider.VerifyIDToken(ctx, idToken, login.Nonce)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	user, err := server.oidcUser(ctx, claims)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.canLogin(ctx, user) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+		return
+	}
+
+	server.finishLogin(ctx, user)
+}
+
+// oidcUser returns the user the identity is linked to. An identity seen for
+// the first time is linked to the user with the same email when both the
+// provider and this server verified it, otherwise it gets a new student
+// account.
+func (server *Server) oidcUser(ctx context.Context, claims *oidc.Claims) (db.User, error) {
+	issuer := server.oidcProvider.Issuer()
+
+	identity, err := server.store.GetUserIdentity(ctx, db.GetUserIdentityParams{
+		Issuer:  issuer,
+		Subject: claims.Subject,
+	})
+	if err == nil {
+		return server.store.GetUser(ctx, identity.UserID)
+	}
+	if err != sql.ErrNoRows {
+		return db.User{}, err
+	}
+
+	email := sql.NullString{String: claims.Email, Valid: claims.Email != "" && claims.EmailVerified}
+	if email.Valid {
+		user, err := server.store.GetUserByEmail(ctx, email)
+		if err != nil && err != sql.ErrNoRows {
+			return db.User{}, err
+		}
+
+		if err == nil {
+			// somebody may have signed up with an address they do not own
+			if user.EmailVerifiedAt.IsZero() {
+				email = sql.NullString{}
+			} else {
+				_, err = server.store.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+					UserID:  user.ID,
+					Issuer:  issuer,
+					Subject: claims.Subject,
+					Email:   claims.Email,
+				})
+				return user, err
+			}
+		}
+	}
+
+	username, err := server.oidcUsername(ctx, claims)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	// the account logs in at the provider, nobody knows its password
+	password, err := util.NewSecret()
+	if err != nil {
+		return db.User{}, err
+	}
+
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		return db.User{}, err
+	}
+
+	arg := db.ProvisionOIDCUserTxParams{
+		User: db.CreateUserParams{
+			Username:       sql.NullString{String: username, Valid: true},
+			HashedPassword: hashedPassword,
+			Fullname:       sql.NullString{String: claims.Name, Valid: claims.Name != ""},
+			Email:          email,
+			Role:           util.RoleStudent,
+		},
+		Issuer:  issuer,
+		Subject: claims.Subject,
+	}
+	if email.Valid {
+		arg.EmailVerifiedAt = time.Now()
+	}
+
+	return server.store.ProvisionOIDCUserTx(ctx, arg)
+}
+
+// oidcUsername picks a free username for a new account, based on the
+// username or the email the provider knows the user by
+func (server *Server) oidcUsername(ctx context.Context, claims *oidc.Claims) (string, error) {
+	base := alphanumeric(claims.PreferredUsername)
+	if base == "" {
+		base = alphanumeric(strings.Split(claims.Email, "@")[0])
+	}
+	if base == "" {
+		base = util.RoleStudent
+	}
+	if len(base) > maxUsernameLength {
+		base = base[:maxUsernameLength]
+	}
+
+	username := base
+	for i := 0; i < 10; i++ {
+		_, err := server.store.GetByUsername(ctx, sql.NullString{String: username, Valid: true})
+		if err == sql.ErrNoRows {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		username = fmt.Sprintf("%s%d", base, util.RandomInt(1000, 9999))
+	}
+
+	return "", errors.New("cannot find a free username")
+}
+
+// alphanumeric drops every character a username may not have
+func alphanumeric(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
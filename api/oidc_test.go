@@ -0,0 +1,437 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/oidc"
+	"github.com/dongocanh96/class_manager_go/oidc/oidctest"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOIDCProvider(t *testing.T, server *Server) *oidctest.Server {
+	provider := oidctest.NewServer("class_manager", util.RandomString(16))
+	t.Cleanup(provider.Close)
+
+	var err error
+	server.oidcProvider, err = oidc.NewProvider(oidc.Config{
+		Issuer:       provider.Issuer(),
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  oidcRedirectURL(server.config),
+	})
+	require.NoError(t, err)
+
+	return provider
+}
+
+// startOIDCLogin starts a login at the server, lets the provider log the user
+// in and returns the callback request the provider redirected to
+func startOIDCLogin(t *testing.T, server *Server) *http.Request {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/oidc/login", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, oidcStateCookie, cookies[0].Name)
+	require.True(t, cookies[0].HttpOnly)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "/oidc/callback", callback.Path)
+
+	request, err = http.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	require.NoError(t, err)
+	request.AddCookie(cookies[0])
+
+	return request
+}
+
+func TestOIDCLoginAPI(t *testing.T) {
+	user, _ := randomStudentUser(t)
+	user.EmailVerifiedAt = time.Now()
+
+	totpUser, _ := randomTOTPUser(t)
+
+	unverified, _ := randomStudentUser(t)
+
+	identity := oidctest.User{
+		Subject:           util.RandomString(12),
+		Email:             util.RandomEmail(),
+		EmailVerified:     true,
+		Name:              util.RandomString(8),
+		PreferredUsername: "jane.doe",
+	}
+
+	unverifiedIdentity := identity
+	unverifiedIdentity.EmailVerified = false
+
+	testCases := []struct {
+		name          string
+		identity      oidctest.User
+		buildStubs    func(store *mockdb.MockStore, issuer string)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "LinkedIdentity",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Eq(db.GetUserIdentityParams{
+					Issuer:  issuer,
+					Subject: identity.Subject,
+				})).
+					Times(1).
+					Return(db.UserIdentity{UserID: user.ID, Issuer: issuer, Subject: identity.Subject}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().ProvisionOIDCUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.AccessToken)
+				require.NotEmpty(t, rsp.RefreshToken)
+				require.Equal(t, user.ID, rsp.User.ID)
+			},
+		},
+		{
+			name:     "LinkByVerifiedEmail",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(sql.NullString{String: identity.Email, Valid: true})).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().CreateUserIdentity(gomock.Any(), gomock.Eq(db.CreateUserIdentityParams{
+					UserID:  user.ID,
+					Issuer:  issuer,
+					Subject: identity.Subject,
+					Email:   identity.Email,
+				})).
+					Times(1).
+					Return(db.UserIdentity{}, nil)
+				store.EXPECT().ProvisionOIDCUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "EmailNotVerifiedHere",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(unverified, nil)
+				store.EXPECT().CreateUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().ProvisionOIDCUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ProvisionOIDCUserTxParams) (db.User, error) {
+						require.False(t, arg.User.Email.Valid)
+						require.True(t, arg.EmailVerifiedAt.IsZero())
+						return db.User{ID: unverified.ID + 1, Username: arg.User.Username, Role: arg.User.Role}, nil
+					})
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "ProvisionStudent",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				// the username is taken once
+				store.EXPECT().GetByUsername(gomock.Any(), gomock.Eq(sql.NullString{String: "janedoe", Valid: true})).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().ProvisionOIDCUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ProvisionOIDCUserTxParams) (db.User, error) {
+						require.Equal(t, util.RoleStudent, arg.User.Role)
+						require.Regexp(t, "^janedoe[0-9]{4}$", arg.User.Username.String)
+						require.Equal(t, identity.Name, arg.User.Fullname.String)
+						require.Equal(t, identity.Email, arg.User.Email.String)
+						require.NotEmpty(t, arg.User.HashedPassword)
+						require.WithinDuration(t, time.Now(), arg.EmailVerifiedAt, time.Second)
+						require.Equal(t, issuer, arg.Issuer)
+						require.Equal(t, identity.Subject, arg.Subject)
+
+						return db.User{
+							ID:              util.RandomInt(1, 100),
+							Username:        arg.User.Username,
+							Fullname:        arg.User.Fullname,
+							Email:           arg.User.Email,
+							Role:            arg.User.Role,
+							EmailVerifiedAt: arg.EmailVerifiedAt,
+						}, nil
+					})
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, util.RoleStudent, rsp.User.Role)
+				require.Equal(t, identity.Email, rsp.User.Email)
+			},
+		},
+		{
+			name:     "ProviderEmailNotVerified",
+			identity: unverifiedIdentity,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().ProvisionOIDCUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ProvisionOIDCUserTxParams) (db.User, error) {
+						require.Equal(t, "janedoe", arg.User.Username.String)
+						require.False(t, arg.User.Email.Valid)
+						return db.User{ID: 1, Username: arg.User.Username, Role: arg.User.Role}, nil
+					})
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "MFARequired",
+			identity: identity,
+			buildStubs: func(store *mockdb.MockStore, issuer string) {
+				store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{UserID: totpUser.ID}, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(totpUser.ID)).
+					Times(1).
+					Return(totpUser, nil)
+				store.EXPECT().CreateMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{UserID: totpUser.ID}, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp mfaChallengeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, rsp.MFARequired)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			provider := newTestOIDCProvider(t, server)
+			provider.SetUser(tc.identity)
+
+			stubOIDCLogin(t, store)
+			tc.buildStubs(store, provider.Issuer())
+
+			request := startOIDCLogin(t, server)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// stubOIDCLogin keeps the login the server starts and hands it back once for
+// its state
+func stubOIDCLogin(t *testing.T, store *mockdb.MockStore) {
+	var login db.OidcLogin
+
+	store.EXPECT().CreateOIDCLogin(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreateOIDCLoginParams) (db.OidcLogin, error) {
+			require.WithinDuration(t, time.Now().Add(oidcLoginDuration), arg.ExpiresAt, time.Second)
+
+			login = db.OidcLogin{
+				StateHash:    arg.StateHash,
+				Nonce:        arg.Nonce,
+				CodeVerifier: arg.CodeVerifier,
+				ExpiresAt:    arg.ExpiresAt,
+			}
+			return login, nil
+		})
+	store.EXPECT().UseOIDCLogin(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, arg db.UseOIDCLoginParams) (db.OidcLogin, error) {
+			if arg.StateHash != login.StateHash || !login.UsedAt.IsZero() {
+				return db.OidcLogin{}, sql.ErrNoRows
+			}
+
+			login.UsedAt = arg.UsedAt
+			return login, nil
+		})
+}
+
+func TestOIDCCallbackRejectedAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		tamper        func(t *testing.T, request *http.Request)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "WrongStateCookie",
+			tamper: func(t *testing.T, request *http.Request) {
+				request.Header.Del("Cookie")
+				request.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: "other"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errOIDCLoginInvalid.Error())
+			},
+		},
+		{
+			name: "NoStateCookie",
+			tamper: func(t *testing.T, request *http.Request) {
+				request.Header.Del("Cookie")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "WrongCode",
+			tamper: func(t *testing.T, request *http.Request) {
+				query := request.URL.Query()
+				query.Set("code", "other")
+				request.URL.RawQuery = query.Encode()
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "invalid_grant")
+			},
+		},
+		{
+			name: "ProviderError",
+			tamper: func(t *testing.T, request *http.Request) {
+				query := request.URL.Query()
+				query.Del("code")
+				query.Set("error", "access_denied")
+				request.URL.RawQuery = query.Encode()
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "access_denied")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			provider := newTestOIDCProvider(t, server)
+			provider.SetUser(oidctest.User{Subject: util.RandomString(12)})
+
+			stubOIDCLogin(t, store)
+			store.EXPECT().GetUserIdentity(gomock.Any(), gomock.Any()).
+				Times(0)
+			store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+				Times(0)
+
+			request := startOIDCLogin(t, server)
+			tc.tamper(t, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOIDCNotConfiguredAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateOIDCLogin(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/oidc/login", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+)
+
+// checkNewPassword applies the password policy to a new password of the user.
+// The history is only looked at for existing users. It writes the error
+// response itself.
+func (server *Server) checkNewPassword(ctx *gin.Context, password string, user db.User) bool {
+	err := server.passwordPolicy.Check(password, user.Username.String, user.Email.String)
+	if err == nil && user.ID != 0 {
+		err = server.checkPasswordHistory(ctx, password, user)
+	}
+	if err != nil {
+		if errors.Is(err, util.ErrWeakPassword) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+func (server *Server) checkPasswordHistory(ctx *gin.Context, password string, user db.User) error {
+	if server.passwordPolicy.History < 1 {
+		return nil
+	}
+
+	hashes := []string{user.HashedPassword}
+
+	if keep := server.keepPasswordHistory(); keep > 0 {
+		history, err := server.store.ListPasswordHistory(ctx, db.ListPasswordHistoryParams{
+			UserID: user.ID,
+			Limit:  keep,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, old := range history {
+			hashes = append(hashes, old.HashedPassword)
+		}
+	}
+
+	for _, hash := range hashes {
+		if util.CheckPassword(password, hash) == nil {
+			return fmt.Errorf("%w: it was used recently", util.ErrWeakPassword)
+		}
+	}
+
+	return nil
+}
+
+// keepPasswordHistory is how many old passwords the history of a user keeps,
+// the current password is not part of it
+func (server *Server) keepPasswordHistory() int32 {
+	if server.passwordPolicy.History <= 1 {
+		return 0
+	}
+	return int32(server.passwordPolicy.History - 1)
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultPasswordResetDuration = 15 * time.Minute
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// forgotPassword mails a one time reset token to the owner of the email. It
+// answers the same way whether or not the email belongs to a user, so it can
+// not be used to find out who has an account.
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserByEmail(ctx, sql.NullString{String: req.Email, Valid: true})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusOK, nil)
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// deactivated users can not log in, so there is nothing to reset
+	if !user.DeletedAt.IsZero() {
+		ctx.JSON(http.StatusOK, nil)
+		return
+	}
+
+	resetToken, err := util.NewSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	duration := server.config.PasswordResetDuration
+	if duration <= 0 {
+		duration = defaultPasswordResetDuration
+	}
+
+	reset, err := server.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		UserID:    user.ID,
+		TokenHash: util.HashSecret(resetToken),
+		ExpiresAt: time.Now().Add(duration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	body := fmt.Sprintf("Hello %s,\n\nuse this token to reset your password, it expires at %s:\n\n%s\n\nIf you did not ask for a password reset you can ignore this email.\n",
+		user.Fullname.String, reset.ExpiresAt.Format(time.RFC1123), resetToken)
+
+	err = server.mailer.Send(ctx, user.Email.String, "Reset your password", body)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// resetPassword sets a new password with a mailed reset token. The token can
+// only be used once, and every session of the user is revoked.
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	tokenHash := util.HashSecret(req.Token)
+
+	// the token is only used up by the reset itself, it is looked up first to
+	// check the password against the user it belongs to
+	reset, err := server.store.GetPasswordReset(ctx, db.GetPasswordResetParams{
+		TokenHash: tokenHash,
+		Now:       time.Now(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(db.ErrPasswordResetInvalid))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, reset.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.checkNewPassword(ctx, req.NewPassword, user) {
+		return
+	}
+
+	hashPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err = server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash:      tokenHash,
+		HashedPassword: hashPassword,
+		ResetAt:        time.Now(),
+		KeepHistory:    server.keepPasswordHistory(),
+	})
+	if err != nil {
+		if err == db.ErrPasswordResetInvalid {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.sessions.invalidateUser(user.Username.String)
+
+	ctx.JSON(http.StatusOK, nil)
+}
@@ -0,0 +1,381 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	mockmail "github.com/dongocanh96/class_manager_go/mail/mock"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type eqResetPasswordTxParamsMatcher struct {
+	token    string
+	password string
+}
+
+func (e eqResetPasswordTxParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.ResetPasswordTxParams)
+	if !ok {
+		return false
+	}
+
+	if arg.TokenHash != util.HashSecret(e.token) || arg.KeepHistory != 2 {
+		return false
+	}
+
+	return util.CheckPassword(e.password, arg.HashedPassword) == nil
+}
+
+func (e eqResetPasswordTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches token %v and password %v", e.token, e.password)
+}
+
+func EqResetPasswordTxParams(token string, password string) gomock.Matcher {
+	return eqResetPasswordTxParamsMatcher{token, password}
+}
+
+func TestForgotPasswordAPI(t *testing.T) {
+	user, _ := randomStudentUser(t)
+
+	deactivated := user
+	deactivated.DeletedAt = time.Now()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore, mailer *mockmail.MockMailer)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"email": user.Email.String},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				var tokenHash string
+
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.WithinDuration(t, time.Now().Add(15*time.Minute), arg.ExpiresAt, time.Second)
+						tokenHash = arg.TokenHash
+
+						return db.PasswordReset{UserID: arg.UserID, TokenHash: arg.TokenHash, ExpiresAt: arg.ExpiresAt}, nil
+					})
+				mailer.EXPECT().Send(gomock.Any(), gomock.Eq(user.Email.String), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, to string, subject string, body string) error {
+						// the mail carries the token, the database only its hash
+						require.NotContains(t, body, tokenHash)
+
+						found := false
+						for _, line := range strings.Split(body, "\n") {
+							if util.HashSecret(line) == tokenHash {
+								found = true
+							}
+						}
+						require.True(t, found)
+
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Deactivated",
+			body: gin.H{"email": user.Email.String},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(deactivated, nil)
+				store.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(0)
+				mailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownEmail",
+			body: gin.H{"email": user.Email.String},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(0)
+				mailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidEmail",
+			body: gin.H{"email": "not-an-email"},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MailerError",
+			body: gin.H{"email": user.Email.String},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordReset{}, nil)
+				mailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("connection refused"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockMailer(ctrl)
+			tc.buildStubs(store, mailer)
+
+			server := newTestServer(t, store)
+			server.mailer = mailer
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/forgot_password", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestForgotPasswordDefaultDuration(t *testing.T) {
+	user, _ := randomStudentUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().CreatePasswordReset(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+			require.WithinDuration(t, time.Now().Add(defaultPasswordResetDuration), arg.ExpiresAt, time.Second)
+			return db.PasswordReset{UserID: arg.UserID, ExpiresAt: arg.ExpiresAt}, nil
+		})
+
+	server := newTestServer(t, store)
+	server.config.PasswordResetDuration = 0
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"email": user.Email.String})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/users/forgot_password", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, password := randomStudentUser(t)
+	resetToken, err := util.NewSecret()
+	require.NoError(t, err)
+	newPassword := util.RandomString(8)
+
+	oldPassword := util.RandomString(8)
+	oldHash, err := util.HashPassword(oldPassword)
+	require.NoError(t, err)
+
+	reset := db.PasswordReset{UserID: user.ID, TokenHash: util.HashSecret(resetToken)}
+
+	stubReset := func(store *mockdb.MockStore) {
+		store.EXPECT().GetPasswordReset(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(ctx context.Context, arg db.GetPasswordResetParams) (db.PasswordReset, error) {
+				require.Equal(t, reset.TokenHash, arg.TokenHash)
+				return reset, nil
+			})
+		store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+			Times(1).
+			Return(user, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": resetToken, "new_password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubReset(store)
+				store.EXPECT().ListPasswordHistory(gomock.Any(), gomock.Eq(db.ListPasswordHistoryParams{
+					UserID: user.ID,
+					Limit:  2,
+				})).
+					Times(1).
+					Return([]db.PasswordHistory{{UserID: user.ID, HashedPassword: oldHash}}, nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), EqResetPasswordTxParams(resetToken, newPassword)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: gin.H{"token": resetToken, "new_password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPasswordReset(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PasswordReset{}, sql.ErrNoRows)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "TokenUsedMeanwhile",
+			body: gin.H{"token": resetToken, "new_password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubReset(store)
+				store.EXPECT().ListPasswordHistory(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrPasswordResetInvalid)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ShortPassword",
+			body: gin.H{"token": resetToken, "new_password": "abc"},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubReset(store)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CurrentPassword",
+			body: gin.H{"token": resetToken, "new_password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubReset(store)
+				store.EXPECT().ListPasswordHistory(gomock.Any(), gomock.Any()).
+					Times(1)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "used recently")
+			},
+		},
+		{
+			name: "OldPassword",
+			body: gin.H{"token": resetToken, "new_password": oldPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubReset(store)
+				store.EXPECT().ListPasswordHistory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.PasswordHistory{{UserID: user.ID, HashedPassword: oldHash}}, nil)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Username",
+			body: gin.H{"token": resetToken, "new_password": user.Username.String},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubReset(store)
+				store.EXPECT().ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.passwordPolicy.History = 3
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/reset_password", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+)
+
+// permission is an action a role may be allowed to do. Checks that depend on
+// the resource, like owning a homework, stay in the handlers.
+type permission string
+
+const (
+	permissionManageHomework  permission = "homework:manage"
+	permissionManageClass     permission = "class:manage"
+	permissionViewSolutions   permission = "solution:view_all"
+	permissionExportGradebook permission = "gradebook:export"
+	permissionManageStudents  permission = "user:manage_students"
+	permissionManageUsers     permission = "user:manage_all"
+	permissionManageRoles     permission = "user:manage_roles"
+	permissionManageSubjects  permission = "subject:manage"
+	permissionManageInvites   permission = "invite:manage_all"
+)
+
+// rolePermissions lists what each role may do on top of what every logged in
+// user can do with their own account, classes and submissions
+var rolePermissions = map[string]map[permission]bool{
+	util.RoleAdmin: {
+		permissionViewSolutions:  true,
+		permissionManageStudents: true,
+		permissionManageUsers:    true,
+		permissionManageRoles:    true,
+		permissionManageSubjects: true,
+		permissionManageInvites:  true,
+	},
+	util.RoleTeacher: {
+		permissionManageHomework:  true,
+		permissionManageClass:     true,
+		permissionExportGradebook: true,
+		permissionManageStudents:  true,
+	},
+	util.RoleStudent:  {},
+	util.RoleGuardian: {},
+}
+
+func hasPermission(role string, perm permission) bool {
+	return rolePermissions[role][perm]
+}
+
+// requirePermission aborts the request unless the role in the access token
+// grants the permission. It must run after authMiddleware.
+func requirePermission(perm permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		if !hasPermission(authPayload.Role, perm) {
+			err := errors.New("permission denied!")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// canManageUser reports whether the logged in user may change or delete the
+// account of user. Teachers may manage students, admins anyone.
+func canManageUser(authPayload *token.Payload, user db.User) bool {
+	if authPayload.Userid == user.ID {
+		return true
+	}
+
+	if hasPermission(authPayload.Role, permissionManageUsers) {
+		return true
+	}
+
+	return hasPermission(authPayload.Role, permissionManageStudents) && user.Role == util.RoleStudent
+}
@@ -1,72 +1,86 @@
 package api
 
 import (
-	"crypto/rsa"
+	"context"
 	"fmt"
-	"io/ioutil"
 
 	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/mail"
+	"github.com/dongocanh96/class_manager_go/oidc"
+	"github.com/dongocanh96/class_manager_go/storage"
 	"github.com/dongocanh96/class_manager_go/token"
 	"github.com/dongocanh96/class_manager_go/util"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
-	"github.com/golang-jwt/jwt/v4"
 )
 
 type Server struct {
-	config     util.Config
-	store      db.Store
-	tokenMaker token.JWTMaker
-	router     *gin.Engine
+	config         util.Config
+	store          db.Store
+	fileStore      storage.FileStore
+	mailer         mail.Mailer
+	tokenMaker     token.Maker
+	passwordPolicy util.PasswordPolicy
+	oidcProvider   *oidc.Provider
+	subjects       *subjectCache
+	sessions       *sessionCache
+	router         *gin.Engine
 }
 
-func getKeyPairData(config util.Config) (privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) {
-	privateKeyByte, err := ioutil.ReadFile(config.PrivateKeyLocation)
+func NewServer(config util.Config, store db.Store, fileStore storage.FileStore, mailer mail.Mailer) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config)
 	if err != nil {
-		panic(err)
-	}
-
-	privatekey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyByte)
-	if err != nil {
-		panic(err)
-	}
-
-	publicKeyByte, err := ioutil.ReadFile(config.PublicKeyLocation)
-	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("cannot create token %w", err)
 	}
 
-	publickey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyByte)
+	passwordPolicy, err := util.NewPasswordPolicy(config)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("cannot create password policy %w", err)
 	}
 
-	return privatekey, publickey
-}
-
-func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenMaker, err := token.NewJWTMaker(getKeyPairData(config))
-	if err != nil {
-		return nil, fmt.Errorf("cannot create token %w", err)
+	server := &Server{
+		config:         config,
+		store:          store,
+		fileStore:      fileStore,
+		mailer:         mailer,
+		tokenMaker:     tokenMaker,
+		passwordPolicy: passwordPolicy,
+		subjects:       newSubjectCache(store),
+		sessions:       newSessionCache(store.GetSessionAuth),
 	}
 
-	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: *tokenMaker,
+	// single sign-on is offered when a provider is configured
+	if config.OIDCIssuer != "" {
+		server.oidcProvider, err = oidc.NewProvider(oidc.Config{
+			Issuer:       config.OIDCIssuer,
+			ClientID:     config.OIDCClientID,
+			ClientSecret: config.OIDCClientSecret,
+			RedirectURL:  oidcRedirectURL(config),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot create oidc provider %w", err)
+		}
 	}
 
+	subjectValidatorCache.Store(server.subjects)
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("subject", validSubject)
+		v.RegisterValidation("role", validRole)
 	}
 
-	server.setupRouter()
+	if err := server.setupRouter(); err != nil {
+		return nil, fmt.Errorf("cannot set up router %w", err)
+	}
 
 	return server, nil
 }
 
 func (server *Server) Start(address string) error {
+	if maker, ok := server.tokenMaker.(*token.JWTMaker); ok {
+		go maker.Watch(context.Background(), server.config.KeyReloadInterval)
+	}
+
 	return server.router.Run(address)
 }
 
@@ -74,34 +88,68 @@ func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
 
-func (server *Server) setupRouter() {
+func (server *Server) setupRouter() error {
 	router := gin.Default()
 
+	// the client ip is taken from X-Forwarded-For only when the request comes
+	// from one of these proxies, otherwise any client could pick the ip the
+	// login throttle counts against
+	if err := router.SetTrustedProxies(server.config.TrustedProxies); err != nil {
+		return err
+	}
+
+	router.GET("/.well-known/jwks.json", server.getJWKS)
+
 	//user function
 	router.POST("/users/create", server.createUser)
 	router.POST("/users/login", server.loginUser)
+	router.POST("/users/login/mfa", server.loginMFA)
 	router.GET("/users/:id", server.getUser)
 	router.GET("/users", server.listUser)
 
+	router.POST("/users/logout", server.logoutUser)
+	router.POST("/users/forgot_password", server.forgotPassword)
+	router.POST("/users/reset_password", server.resetPassword)
+	router.GET("/users/verify_email", server.verifyEmail)
+	router.POST("/users/resend_verification", server.resendEmailVerification)
 	router.POST("/tokens/renew_access", server.renewAccessToken)
+	router.GET("/oidc/login", server.oidcLogin)
+	router.GET("/oidc/callback", server.oidcCallback)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.sessions))
 
+	authRoutes.GET("/users/all", requirePermission(permissionManageUsers), server.listAllUsers)
+	authRoutes.POST("/users/import", requirePermission(permissionManageStudents), server.importUsers)
 	authRoutes.PUT("/users/:id/update_info", server.updateUserInfo)
 	authRoutes.PUT("/users/:id/update_password", server.updateUserPassword)
+	authRoutes.PUT("/users/:id/role", requirePermission(permissionManageRoles), server.updateUserRole)
 	authRoutes.DELETE("/users/:id", server.deleteUser)
+	authRoutes.POST("/users/:id/reactivate", requirePermission(permissionManageUsers), server.reactivateUser)
+	authRoutes.DELETE("/users/:id/purge", requirePermission(permissionManageUsers), server.purgeUser)
+	authRoutes.DELETE("/users/:id/lockout", requirePermission(permissionManageUsers), server.unlockUser)
+	authRoutes.POST("/users/:id/totp", server.beginTOTPEnrollment)
+	authRoutes.POST("/users/:id/totp/confirm", server.confirmTOTPEnrollment)
+	authRoutes.POST("/users/:id/totp/recovery_codes", server.regenerateRecoveryCodes)
+	authRoutes.POST("/users/:id/totp/disable", server.disableTOTP)
 	authRoutes.GET("/users/:id/homeworks", server.listHomeworkByTeacher)
 	authRoutes.GET("/users/:id/solutions", server.listSolutionsByUser)
+	authRoutes.GET("/users/:id/sessions", server.listSessions)
+	authRoutes.GET("/users/:id/gradebook", requirePermission(permissionExportGradebook), server.getGradebook)
 	authRoutes.GET("/users/:id/sended_messages", server.listSendedMessage)
 	authRoutes.GET("/users/:id/recieved_messages", server.listReceivedMessages)
 
+	//session function
+	authRoutes.DELETE("/sessions/:id", server.revokeSession)
+
 	//homework function
-	authRoutes.POST("/homeworks/create", server.createHomework)
+	authRoutes.POST("/homeworks/create", requirePermission(permissionManageHomework), server.createHomework)
 	authRoutes.GET("/homeworks/:id", server.getHomework)
+	authRoutes.GET("/homeworks/:id/file", server.downloadHomeworkFile)
 	authRoutes.GET("/homeworks", server.listHomework)
 	authRoutes.GET("/homeworks/subject", server.listHomeworkBySubject)
 	authRoutes.PUT("/homeworks/:id", server.updateHomework)
 	authRoutes.PUT("/homeworks/:id/close", server.closeHomework)
+	authRoutes.PUT("/homeworks/:id/late_policy", server.updateHomeworkLatePolicy)
 	authRoutes.DELETE("/homeworks/:id", server.deleteHomework)
 	authRoutes.POST("/homeworks/:id/solutions/create", server.createSolution)
 	authRoutes.GET("homeworks/:id/solutions", server.listSolutionsByProblem)
@@ -109,8 +157,35 @@ func (server *Server) setupRouter() {
 
 	//solution function
 	authRoutes.GET("/solutions/:id", server.getSolutionByID)
+	authRoutes.GET("/solutions/:id/file", server.downloadSolutionFile)
 	authRoutes.PUT("/solutions/:id", server.updateSolution)
 	authRoutes.DELETE("/solutions/:id", server.deleteSolution)
+	authRoutes.PUT("/solutions/:id/grade", server.gradeSolution)
+	authRoutes.PUT("/solutions/:id/grade/release", server.releaseGrade)
+	authRoutes.GET("/solutions/:id/grade/file", server.downloadFeedbackFile)
+
+	//class function
+	authRoutes.POST("/classes/create", requirePermission(permissionManageClass), server.createClass)
+	authRoutes.POST("/classes/join", server.joinClass)
+	authRoutes.GET("/classes", server.listClasses)
+	authRoutes.GET("/classes/:id", server.getClass)
+	authRoutes.PUT("/classes/:id/join_code", server.resetClassJoinCode)
+	authRoutes.POST("/classes/:id/members", server.addClassMember)
+	authRoutes.GET("/classes/:id/members", server.listClassMembers)
+	authRoutes.DELETE("/classes/:id/members/:user_id", server.removeClassMember)
+
+	//invite function
+	authRoutes.POST("/invites/create", server.createInvite)
+	authRoutes.POST("/invites/redeem", server.redeemInvite)
+	authRoutes.GET("/invites", server.listInvites)
+	authRoutes.GET("/invites/:id/redemptions", server.listInviteRedemptions)
+	authRoutes.DELETE("/invites/:id", server.revokeInvite)
+
+	//subject function
+	authRoutes.GET("/subjects", server.listSubjects)
+	authRoutes.POST("/subjects/create", requirePermission(permissionManageSubjects), server.createSubject)
+	authRoutes.PUT("/subjects/:id", requirePermission(permissionManageSubjects), server.updateSubject)
+	authRoutes.DELETE("/subjects/:id", requirePermission(permissionManageSubjects), server.deleteSubject)
 
 	//message function
 	authRoutes.POST("/messages/create", server.createMessage)
@@ -120,4 +195,5 @@ func (server *Server) setupRouter() {
 	authRoutes.DELETE("/messages/:id/delete", server.deleteMessage)
 
 	server.router = router
+	return nil
 }
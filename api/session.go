@@ -0,0 +1,257 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// sessionCacheTTL bounds how long a session revoked through another server
+// instance keeps being accepted
+const sessionCacheTTL = 30 * time.Second
+
+// sessionCacheSize is the number of entries above which stale ones are dropped
+const sessionCacheSize = 10000
+
+type sessionLoader func(ctx context.Context, id uuid.UUID) (db.GetSessionAuthRow, error)
+
+// sessionCache keeps the state authMiddleware needs about a session so the
+// sessions table is not read on every request
+type sessionCache struct {
+	load    sessionLoader
+	mu      sync.Mutex
+	entries map[uuid.UUID]sessionCacheEntry
+}
+
+type sessionCacheEntry struct {
+	session  db.GetSessionAuthRow
+	loadedAt time.Time
+}
+
+func newSessionCache(load sessionLoader) *sessionCache {
+	return &sessionCache{
+		load:    load,
+		entries: make(map[uuid.UUID]sessionCacheEntry),
+	}
+}
+
+// get returns the session, reloading it when the cached copy is stale
+func (cache *sessionCache) get(ctx context.Context, id uuid.UUID) (db.GetSessionAuthRow, error) {
+	cache.mu.Lock()
+	entry, ok := cache.entries[id]
+	cache.mu.Unlock()
+
+	if ok && time.Since(entry.loadedAt) < sessionCacheTTL {
+		return entry.session, nil
+	}
+
+	session, err := cache.load(ctx, id)
+	if err != nil {
+		return db.GetSessionAuthRow{}, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if len(cache.entries) >= sessionCacheSize {
+		for key, entry := range cache.entries {
+			if time.Since(entry.loadedAt) >= sessionCacheTTL {
+				delete(cache.entries, key)
+			}
+		}
+	}
+	cache.entries[id] = sessionCacheEntry{session: session, loadedAt: time.Now()}
+
+	return session, nil
+}
+
+// invalidateFamily drops the sessions of one login
+func (cache *sessionCache) invalidateFamily(familyID uuid.UUID) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for key, entry := range cache.entries {
+		if entry.session.FamilyID == familyID {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+// invalidateUser drops every session of the user
+func (cache *sessionCache) invalidateUser(username string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for key, entry := range cache.entries {
+		if entry.session.Username == username {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+type sessionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreateAt  time.Time `json:"create_at"`
+}
+
+func newSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: session.ExpiresAt,
+		CreateAt:  session.CreateAt,
+	}
+}
+
+type logoutUserRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// logoutUser ends the session the refresh token belongs to, including the
+// rotated refresh tokens of the same login
+func (server *Server) logoutUser(ctx *gin.Context) {
+	var req logoutUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	refreshPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if session.RefreshToken != req.RefreshToken {
+		err := errors.New("mismatched session token!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	err = server.store.DeleteSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.sessions.invalidateFamily(session.FamilyID)
+
+	ctx.JSON(http.StatusOK, nil)
+}
+
+func (server *Server) listSessions(ctx *gin.Context) {
+	var req getUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if authPayload.Userid != req.ID && !hasPermission(authPayload.Role, permissionManageUsers) {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	sessions, err := server.store.ListSessionsByUsername(ctx, user.Username.String)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		rsp = append(rsp, newSessionResponse(session))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type revokeSessionRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// revokeSession blocks a session so its refresh and access tokens can no
+// longer be used, the session stays listed to show it was revoked
+func (server *Server) revokeSession(ctx *gin.Context) {
+	var req revokeSessionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	session, err := server.store.GetSession(ctx, uuid.MustParse(req.ID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !hasPermission(authPayload.Role, permissionManageUsers) {
+		owner, err := server.store.GetByUsername(ctx, sql.NullString{String: session.Username, Valid: true})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if owner.ID != authPayload.Userid {
+			err := errors.New("permission denied!")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+	}
+
+	// older refresh tokens of the same login must not be usable either
+	err = server.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.sessions.invalidateFamily(session.FamilyID)
+
+	session.IsBlocked = true
+
+	ctx.JSON(http.StatusOK, newSessionResponse(session))
+}
@@ -3,10 +3,8 @@ package api
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"time"
 
 	db "github.com/dongocanh96/class_manager_go/db/sqlc"
@@ -18,6 +16,43 @@ type getSolutionRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
+// canViewSolution reports whether the user may see the solution. It is shown
+// to its author, the teacher of the homework and whoever may view them all.
+func canViewSolution(authPayload *token.Payload, solution db.Solution, homework db.Homework) bool {
+	return solution.UserID == authPayload.Userid ||
+		homework.TeacherID == authPayload.Userid ||
+		hasPermission(authPayload.Role, permissionViewSolutions)
+}
+
+// getViewableSolution loads a solution with its homework for a user that may
+// see it. It writes the error response itself.
+func (server *Server) getViewableSolution(ctx *gin.Context, authPayload *token.Payload, solutionID int64) (db.Solution, db.Homework, bool) {
+	solution, err := server.store.GetSolutionByID(ctx, solutionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Solution{}, db.Homework{}, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Solution{}, db.Homework{}, false
+	}
+
+	homework, err := server.store.GetHomework(ctx, solution.ProblemID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Solution{}, db.Homework{}, false
+	}
+
+	if !canViewSolution(authPayload, solution, homework) {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return db.Solution{}, db.Homework{}, false
+	}
+
+	return solution, homework, true
+}
+
 func (server *Server) getSolutionByID(ctx *gin.Context) {
 	var req getSolutionRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
@@ -27,24 +62,42 @@ func (server *Server) getSolutionByID(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	solution, err := server.store.GetSolutionByID(ctx, req.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
+	solution, homework, ok := server.getViewableSolution(ctx, authPayload, req.ID)
+	if !ok {
+		return
+	}
 
+	rsp := solutionResponse{Solution: solution}
+
+	grade, err := server.store.GetGradeBySolution(ctx, solution.ID)
+	if err != nil && err != sql.ErrNoRows {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	if !authPayload.IsTeacher && authPayload.Userid != solution.UserID {
-		err := errors.New("permission denied!")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if err == nil && canSeeGrade(authPayload, grade, homework) {
+		gradeRsp := newGradeResponse(grade, homework, solution)
+		rsp.Grade = &gradeRsp
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) downloadSolutionFile(ctx *gin.Context) {
+	var req getSolutionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, solution)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	solution, _, ok := server.getViewableSolution(ctx, authPayload, req.ID)
+	if !ok {
+		return
+	}
+
+	server.serveFile(ctx, solution.FileName, solution.SavedPath, lastModified(solution.SubmitedAt, solution.UpdatedAt))
 }
 
 type updateSolutionRequest struct {
@@ -59,8 +112,7 @@ func (server *Server) updateSolution(ctx *gin.Context) {
 	}
 
 	var req updateSolutionRequest
-	if err := ctx.ShouldBind(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	if !bindUpload(ctx, &req, server.config.MaxSolutionFileSize) {
 		return
 	}
 
@@ -83,8 +135,35 @@ func (server *Server) updateSolution(ctx *gin.Context) {
 		return
 	}
 
-	savedPath := fmt.Sprintf("%s%s", server.config.Asset, req.File.Filename)
-	err = ctx.SaveUploadedFile(req.File, savedPath)
+	homework, err := server.store.GetHomework(ctx, solution.ProblemID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	grade, err := server.store.GetGradeBySolution(ctx, solution.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err == nil && grade.IsReleased {
+		err := errors.New("solution is already graded!")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	lateness, ok := evaluateLateness(ctx, homework, time.Now())
+	if !ok {
+		return
+	}
+
+	contentType, valid := server.validUpload(ctx, req.File, server.config.MaxSolutionFileSize)
+	if !valid {
+		return
+	}
+
+	savedPath, err := server.saveUploadedFile(ctx, req.File, contentType)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -93,19 +172,25 @@ func (server *Server) updateSolution(ctx *gin.Context) {
 	oldSavedPath := solution.SavedPath
 
 	arg := db.UpdateSolutionParams{
-		ID:        reqURI.ID,
-		FileName:  req.File.Filename,
-		SavedPath: savedPath,
-		UpdatedAt: time.Now(),
+		ID:          reqURI.ID,
+		FileName:    displayFileName(req.File.Filename),
+		SavedPath:   savedPath,
+		UpdatedAt:   time.Now(),
+		IsLate:      lateness.IsLate,
+		LateSeconds: int64(lateness.LateBy / time.Second),
+		LatePenalty: lateness.Penalty,
 	}
 
 	solution, err = server.store.UpdateSolution(ctx, arg)
 	if err != nil {
+		// the stored file is not referenced by any row, remove it
+		server.fileStore.Delete(ctx, savedPath)
+
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	err = os.Remove(oldSavedPath)
+	err = server.fileStore.Delete(ctx, oldSavedPath)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -140,7 +225,36 @@ func (server *Server) deleteSolution(ctx *gin.Context) {
 		return
 	}
 
-	err = os.Remove(solution.SavedPath)
+	grade, err := server.store.GetGradeBySolution(ctx, solution.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err == nil {
+		if grade.IsReleased {
+			err := errors.New("solution is already graded!")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		// a draft grade goes away together with the solution
+		err = server.store.DeleteGrade(ctx, grade.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if grade.FeedbackSavedPath != "" {
+			err = server.fileStore.Delete(ctx, grade.FeedbackSavedPath)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+		}
+	}
+
+	err = server.fileStore.Delete(ctx, solution.SavedPath)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadSolutionFile(t *testing.T) {
+	student := randomUser(t)
+	student.Role = util.RoleStudent
+	otherStudent := randomUser(t)
+	otherStudent.ID = student.ID + 1
+	otherStudent.Role = util.RoleStudent
+	teacher, _ := randomTeacherUser(t)
+	otherTeacher, _ := randomTeacherUser(t)
+	otherTeacher.ID = teacher.ID + 1
+
+	content := util.RandomString(64)
+	homework := randomHomework(t, teacher.ID)
+	solution := randomSolution(t, student.ID)
+	solution.ProblemID = homework.ID
+
+	testCases := []struct {
+		name          string
+		solutionID    int64
+		setupRequest  func(request *http.Request)
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "OwnerOK",
+			solutionID:   solution.ID,
+			setupRequest: func(request *http.Request) {},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(solution, nil)
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, content, recorder.Body.String())
+				require.Equal(t, "text/plain; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Equal(t, `attachment; filename=solution.txt`, recorder.Header().Get("Content-Disposition"))
+				require.Equal(t, "bytes", recorder.Header().Get("Accept-Ranges"))
+			},
+		},
+		{
+			name:       "TeacherPartialContent",
+			solutionID: solution.ID,
+			setupRequest: func(request *http.Request) {
+				request.Header.Set("Range", "bytes=10-19")
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(solution, nil)
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPartialContent, recorder.Code)
+				require.Equal(t, content[10:20], recorder.Body.String())
+				require.Equal(t, fmt.Sprintf("bytes 10-19/%d", len(content)), recorder.Header().Get("Content-Range"))
+			},
+		},
+		{
+			name:         "UnauthorizedUser",
+			solutionID:   solution.ID,
+			setupRequest: func(request *http.Request) {},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, otherStudent.ID, otherStudent.Username.String, otherStudent.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(solution, nil)
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "OtherTeacher",
+			solutionID:   solution.ID,
+			setupRequest: func(request *http.Request) {},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, otherTeacher.ID, otherTeacher.Username.String, otherTeacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(solution, nil)
+				store.EXPECT().GetHomework(gomock.Any(), gomock.Eq(homework.ID)).
+					Times(1).
+					Return(homework, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "NoAuthorization",
+			solutionID:   solution.ID,
+			setupRequest: func(request *http.Request) {},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:         "NotFound",
+			solutionID:   solution.ID,
+			setupRequest: func(request *http.Request) {},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSolutionByID(gomock.Any(), gomock.Eq(solution.ID)).
+					Times(1).
+					Return(db.Solution{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			err := server.fileStore.Save(context.Background(), solution.SavedPath,
+				strings.NewReader(content), int64(len(content)), "text/plain")
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/solutions/%d/file", tc.solutionID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupRequest(request)
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomSolution(t *testing.T, userID int64) db.Solution {
+	return db.Solution{
+		ID:         util.RandomInt(1, 100),
+		ProblemID:  util.RandomInt(1, 100),
+		UserID:     userID,
+		FileName:   "solution.txt",
+		SavedPath:  util.RandomString(10),
+		SubmitedAt: time.Now(),
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// subjectCacheTTL bounds how long a subject change made by another server
+// instance can go unnoticed by the subject validator
+const subjectCacheTTL = time.Minute
+
+// subjectCache keeps the subject names in memory so the subject validator
+// does not hit the database on every request
+type subjectCache struct {
+	store    db.Store
+	mu       sync.Mutex
+	names    map[string]bool
+	loadedAt time.Time
+}
+
+func newSubjectCache(store db.Store) *subjectCache {
+	return &subjectCache{store: store}
+}
+
+// has reports whether the subject exists, reloading the list when it is stale
+func (cache *subjectCache) has(ctx context.Context, name string) (bool, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.names == nil || time.Since(cache.loadedAt) > subjectCacheTTL {
+		subjects, err := cache.store.ListSubjects(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		cache.names = make(map[string]bool, len(subjects))
+		for _, subject := range subjects {
+			cache.names[subject.Name] = true
+		}
+		cache.loadedAt = time.Now()
+	}
+
+	return cache.names[name], nil
+}
+
+// invalidate forces the next lookup to reload the list
+func (cache *subjectCache) invalidate() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.names = nil
+}
+
+func (server *Server) listSubjects(ctx *gin.Context) {
+	subjects, err := server.store.ListSubjects(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, subjects)
+}
+
+type createSubjectRequest struct {
+	Name string `json:"name" binding:"required,max=256"`
+}
+
+func (server *Server) createSubject(ctx *gin.Context) {
+	var req createSubjectRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subject, err := server.store.CreateSubject(ctx, req.Name)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("subject already exists!")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.subjects.invalidate()
+	ctx.JSON(http.StatusOK, subject)
+}
+
+type getSubjectRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateSubjectRequest struct {
+	Name string `json:"name" binding:"required,max=256"`
+}
+
+// updateSubject renames a subject, homeworks follow through ON UPDATE CASCADE
+func (server *Server) updateSubject(ctx *gin.Context) {
+	var reqURI getSubjectRequest
+	if err := ctx.ShouldBindUri(&reqURI); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateSubjectRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subject, err := server.store.UpdateSubject(ctx, db.UpdateSubjectParams{
+		ID:   reqURI.ID,
+		Name: req.Name,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("subject already exists!")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.subjects.invalidate()
+	ctx.JSON(http.StatusOK, subject)
+}
+
+func (server *Server) deleteSubject(ctx *gin.Context) {
+	var req getSubjectRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := server.store.GetSubject(ctx, req.ID); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err := server.store.DeleteSubject(ctx, req.ID)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				err := errors.New("subject is used by a homework!")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.subjects.invalidate()
+	ctx.JSON(http.StatusOK, nil)
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSubjectAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	user, _ := randomTeacherUser(t)
+	name := util.RandomString(8)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSubject(gomock.Any(), gomock.Eq(name)).
+					Times(1).
+					Return(db.Subject{ID: 7, Name: name}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var subject db.Subject
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &subject))
+				require.Equal(t, name, subject.Name)
+			},
+		},
+		{
+			name: "NotAdmin",
+			user: user,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSubject(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "DuplicateName",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateSubject(gomock.Any(), gomock.Eq(name)).
+					Times(1).
+					Return(db.Subject{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"name": name})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/subjects/create", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.user.ID, tc.user.Username.String, tc.user.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteSubjectAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	subject := db.Subject{ID: util.RandomInt(1, 100), Name: util.RandomSubject()}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSubject(gomock.Any(), gomock.Eq(subject.ID)).
+					Times(1).
+					Return(subject, nil)
+				store.EXPECT().DeleteSubject(gomock.Any(), gomock.Eq(subject.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSubject(gomock.Any(), gomock.Eq(subject.ID)).
+					Times(1).
+					Return(db.Subject{}, sql.ErrNoRows)
+				store.EXPECT().DeleteSubject(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "UsedByHomework",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSubject(gomock.Any(), gomock.Eq(subject.ID)).
+					Times(1).
+					Return(subject, nil)
+				store.EXPECT().DeleteSubject(gomock.Any(), gomock.Eq(subject.ID)).
+					Times(1).
+					Return(&pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/subjects/%d", subject.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, admin.ID, admin.Username.String, admin.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"time"
 
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type renewAccessTokenRequest struct {
@@ -14,10 +16,16 @@ type renewAccessTokenRequest struct {
 }
 
 type renewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiredAt time.Time `json:"access_token_expired_at"`
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiredAt  time.Time `json:"access_token_expired_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiredAt time.Time `json:"refresh_token_expired_at"`
 }
 
+// renewAccessToken exchanges a refresh token for a new access and refresh
+// token pair. The old refresh token can only be used once, presenting it again
+// means it leaked, so every session of that login is blocked.
 func (server *Server) renewAccessToken(ctx *gin.Context) {
 	var req renewAccessTokenRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -42,6 +50,11 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	if !session.RotatedAt.IsZero() {
+		server.blockSessionFamily(ctx, session)
+		return
+	}
+
 	if session.IsBlocked {
 		err := errors.New("blocked session!")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
@@ -66,10 +79,37 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	// the role may have changed since login, use the current one
+	user, err := server.store.GetUser(ctx, refreshPayload.Userid)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// the new refresh token keeps the expiry of the login, rotation does not
+	// extend how long a session lives
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateToken(
+		refreshPayload.Userid,
+		refreshPayload.Username,
+		user.Role,
+		uuid.Nil,
+		time.Until(session.ExpiresAt),
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
 		refreshPayload.Userid,
 		refreshPayload.Username,
-		refreshPayload.IsTeacher,
+		user.Role,
+		newRefreshPayload.ID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -77,9 +117,50 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
-	rsp := loginUserResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiredAt: accessPayload.ExpiredAt,
+	newSession, err := server.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		OldID: session.ID,
+		NewSession: db.CreateSessionParams{
+			ID:           newRefreshPayload.ID,
+			Username:     session.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    ctx.Request.UserAgent(),
+			ClientIp:     ctx.ClientIP(),
+			IsBlocked:    false,
+			ExpiresAt:    session.ExpiresAt,
+			FamilyID:     session.FamilyID,
+		},
+	})
+	if err != nil {
+		// another request rotated the same token first
+		if err == db.ErrSessionRotated {
+			server.blockSessionFamily(ctx, session)
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := renewAccessTokenResponse{
+		SessionID:             newSession.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiredAt:  accessPayload.ExpiredAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiredAt: newSession.ExpiresAt,
 	}
 	ctx.JSON(http.StatusOK, rsp)
 }
+
+// blockSessionFamily handles a reused refresh token by blocking every session
+// issued from the same login. It writes the error response itself.
+func (server *Server) blockSessionFamily(ctx *gin.Context, session db.Session) {
+	err := server.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.sessions.invalidateFamily(session.FamilyID)
+
+	err = errors.New("refresh token reuse detected!")
+	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+}
@@ -0,0 +1,526 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTOTPIssuer           = "Class manager"
+	defaultMFAChallengeDuration = 5 * time.Minute
+	recoveryCodeCount           = 10
+)
+
+var (
+	errInvalidMFACode      = errors.New("two-factor code is invalid!")
+	errMFAChallengeInvalid = errors.New("two-factor challenge is invalid or expired!")
+	errTOTPEnabled         = errors.New("two-factor authentication is already enabled!")
+	errTOTPNotEnabled      = errors.New("two-factor authentication is not enabled!")
+)
+
+// mfaRequired reports whether the user has to enter a code after the password
+func (server *Server) mfaRequired(user db.User) bool {
+	return !user.TotpEnabledAt.IsZero() || server.teacherMFARequired(user)
+}
+
+// teacherMFARequired reports whether the user is a teacher who may not log in
+// without a second factor
+func (server *Server) teacherMFARequired(user db.User) bool {
+	return server.config.RequireTeacherMFA && user.Role == util.RoleTeacher
+}
+
+func (server *Server) totpURI(user db.User) string {
+	issuer := server.config.TOTPIssuer
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+	return util.TOTPURI(issuer, user.Username.String, user.TotpSecret)
+}
+
+// newRecoveryCodes returns a fresh set of recovery codes and their hashes
+func newRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.NewRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, util.HashSecret(code))
+	}
+
+	return codes, hashes, nil
+}
+
+type mfaChallengeResponse struct {
+	MFARequired        bool      `json:"mfa_required"`
+	MFAToken           string    `json:"mfa_token"`
+	MFATokenExpiredAt  time.Time `json:"mfa_token_expired_at"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+	TOTPSecret         string    `json:"totp_secret,omitempty"`
+	ProvisioningURI    string    `json:"provisioning_uri,omitempty"`
+}
+
+// createMFAChallenge hands out the token a user exchanges for a session
+// together with a code. Teachers who have to use two-factor authentication but
+// did not set it up yet get a secret to enroll with, the first code confirms it.
+func (server *Server) createMFAChallenge(ctx context.Context, user db.User) (mfaChallengeResponse, error) {
+	enrolling := user.TotpEnabledAt.IsZero()
+
+	if enrolling && user.TotpSecret == "" {
+		secret, err := util.NewTOTPSecret()
+		if err != nil {
+			return mfaChallengeResponse{}, err
+		}
+
+		user, err = server.store.SetUserTOTPSecret(ctx, db.SetUserTOTPSecretParams{
+			ID:         user.ID,
+			TotpSecret: secret,
+		})
+		if err != nil {
+			return mfaChallengeResponse{}, err
+		}
+	}
+
+	mfaToken, err := util.NewSecret()
+	if err != nil {
+		return mfaChallengeResponse{}, err
+	}
+
+	duration := server.config.MFAChallengeDuration
+	if duration <= 0 {
+		duration = defaultMFAChallengeDuration
+	}
+
+	challenge, err := server.store.CreateMFAChallenge(ctx, db.CreateMFAChallengeParams{
+		UserID:    user.ID,
+		TokenHash: util.HashSecret(mfaToken),
+		ExpiresAt: time.Now().Add(duration),
+	})
+	if err != nil {
+		return mfaChallengeResponse{}, err
+	}
+
+	rsp := mfaChallengeResponse{
+		MFARequired:       true,
+		MFAToken:          mfaToken,
+		MFATokenExpiredAt: challenge.ExpiresAt,
+	}
+	if enrolling {
+		rsp.EnrollmentRequired = true
+		rsp.TOTPSecret = user.TotpSecret
+		rsp.ProvisioningURI = server.totpURI(user)
+	}
+
+	return rsp, nil
+}
+
+// checkSecondFactor checks the totp code or the recovery code of the user and
+// returns the step of a valid totp code. Codes of an enabled secret are used
+// up, a code of a pending secret is used up by enabling it.
+func (server *Server) checkSecondFactor(ctx context.Context, user db.User, code string, recoveryCode string) (int64, error) {
+	if recoveryCode != "" {
+		if user.TotpEnabledAt.IsZero() {
+			return 0, errInvalidMFACode
+		}
+
+		_, err := server.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+			UserID:   user.ID,
+			CodeHash: util.HashSecret(util.NormalizeRecoveryCode(recoveryCode)),
+			UsedAt:   time.Now(),
+		})
+		if err == sql.ErrNoRows {
+			return 0, errInvalidMFACode
+		}
+		return 0, err
+	}
+
+	step, err := util.CheckTOTP(user.TotpSecret, code, time.Now())
+	if err != nil {
+		if err == util.ErrInvalidTOTP {
+			return 0, errInvalidMFACode
+		}
+		return 0, err
+	}
+
+	if user.TotpEnabledAt.IsZero() {
+		return step, nil
+	}
+
+	// a code is accepted once, even within its time step
+	_, err = server.store.UseUserTOTPStep(ctx, db.UseUserTOTPStepParams{
+		ID:           user.ID,
+		TotpLastStep: step,
+	})
+	if err == sql.ErrNoRows {
+		return 0, errInvalidMFACode
+	}
+	return step, err
+}
+
+type loginMFARequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode,omitempty,numeric,len=6"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// loginMFA is the second step of loginUser, it exchanges the challenge token
+// and a code for the session
+func (server *Server) loginMFA(ctx *gin.Context) {
+	var req loginMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	challenge, err := server.store.GetMFAChallenge(ctx, db.GetMFAChallengeParams{
+		TokenHash: util.HashSecret(req.MFAToken),
+		Now:       time.Now(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errMFAChallengeInvalid))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, challenge.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// the user may have been deactivated since the challenge was created
+	if !user.DeletedAt.IsZero() {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errMFAChallengeInvalid))
+		return
+	}
+
+	// wrong codes count as failed logins, so they can not be guessed
+	lockedUntil, err := server.loginLockedUntil(ctx, user.Username.String, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !lockedUntil.IsZero() {
+		abortLockedLogin(ctx, lockedUntil)
+		return
+	}
+
+	step, err := server.checkSecondFactor(ctx, user, req.Code, req.RecoveryCode)
+	if err != nil {
+		if err == errInvalidMFACode {
+			err = server.recordLoginFailure(ctx, user.Username.String, ctx.ClientIP())
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidMFACode))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.store.DeleteLoginFailure(ctx, loginUsernameKey(user.Username.String))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.UseMFAChallenge(ctx, db.UseMFAChallengeParams{
+		ID:     challenge.ID,
+		UsedAt: time.Now(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errMFAChallengeInvalid))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var recoveryCodes []string
+	if user.TotpEnabledAt.IsZero() {
+		var hashes []string
+		recoveryCodes, hashes, err = newRecoveryCodes()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		user, err = server.store.EnableTOTPTx(ctx, db.EnableTOTPTxParams{
+			UserID:             user.ID,
+			EnabledAt:          time.Now(),
+			Step:               step,
+			RecoveryCodeHashes: hashes,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	rsp, err := server.createLoginSession(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp.RecoveryCodes = recoveryCodes
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// getOwnUser loads the user of the uri, who has to be the logged in user
+func (server *Server) getOwnUser(ctx *gin.Context) (db.User, bool) {
+	var req getUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.User{}, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Userid != req.ID {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return db.User{}, false
+	}
+
+	user, err := server.store.GetUser(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.User{}, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.User{}, false
+	}
+
+	return user, true
+}
+
+type totpEnrollmentResponse struct {
+	TOTPSecret      string `json:"totp_secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// beginTOTPEnrollment gives the user a new secret, it is used for logins once
+// a code of it is confirmed
+func (server *Server) beginTOTPEnrollment(ctx *gin.Context) {
+	user, ok := server.getOwnUser(ctx)
+	if !ok {
+		return
+	}
+
+	if !user.TotpEnabledAt.IsZero() {
+		ctx.JSON(http.StatusForbidden, errorResponse(errTOTPEnabled))
+		return
+	}
+
+	secret, err := util.NewTOTPSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err = server.store.SetUserTOTPSecret(ctx, db.SetUserTOTPSecretParams{
+		ID:         user.ID,
+		TotpSecret: secret,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := totpEnrollmentResponse{
+		TOTPSecret:      user.TotpSecret,
+		ProvisioningURI: server.totpURI(user),
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type totpCodeRequest struct {
+	Code string `json:"code" binding:"required,numeric,len=6"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTPEnrollment enables the pending secret of the user and returns
+// their recovery codes, the only time they are shown
+func (server *Server) confirmTOTPEnrollment(ctx *gin.Context) {
+	var req totpCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, ok := server.getOwnUser(ctx)
+	if !ok {
+		return
+	}
+
+	if !user.TotpEnabledAt.IsZero() {
+		ctx.JSON(http.StatusForbidden, errorResponse(errTOTPEnabled))
+		return
+	}
+
+	step, err := server.checkSecondFactor(ctx, user, req.Code, "")
+	if err != nil {
+		if err == errInvalidMFACode {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.EnableTOTPTx(ctx, db.EnableTOTPTxParams{
+		UserID:             user.ID,
+		EnabledAt:          time.Now(),
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// regenerateRecoveryCodes replaces every recovery code of the user, used or
+// not, after checking a code of their authenticator
+func (server *Server) regenerateRecoveryCodes(ctx *gin.Context) {
+	var req totpCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, ok := server.getOwnUser(ctx)
+	if !ok {
+		return
+	}
+
+	if user.TotpEnabledAt.IsZero() {
+		ctx.JSON(http.StatusForbidden, errorResponse(errTOTPNotEnabled))
+		return
+	}
+
+	_, err := server.checkSecondFactor(ctx, user, req.Code, "")
+	if err != nil {
+		if err == errInvalidMFACode {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.store.ReplaceRecoveryCodesTx(ctx, db.ReplaceRecoveryCodesTxParams{
+		UserID:     user.ID,
+		CodeHashes: hashes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+type disableTOTPRequest struct {
+	Password string `json:"password"`
+}
+
+// disableTOTP turns two-factor authentication off. Users confirm it with their
+// password, admins can turn it off for a user who lost their authenticator
+// and their recovery codes.
+func (server *Server) disableTOTP(ctx *gin.Context) {
+	var reqURI getUserRequest
+	var reqJSON disableTOTPRequest
+
+	if err := ctx.ShouldBindUri(&reqURI); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&reqJSON); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	self := authPayload.Userid == reqURI.ID
+	if !self && !hasPermission(authPayload.Role, permissionManageUsers) {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, reqURI.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if self {
+		if server.teacherMFARequired(user) {
+			err := errors.New("teachers must use two-factor authentication!")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		if err := util.CheckPassword(reqJSON.Password, user.HashedPassword); err != nil {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+			return
+		}
+	}
+
+	user, err = server.store.DisableTOTPTx(ctx, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := newPrivateUserResponse(user)
+	ctx.JSON(http.StatusOK, rsp)
+}
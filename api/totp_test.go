@@ -0,0 +1,781 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// randomTOTPUser returns a teacher with two-factor authentication enabled
+func randomTOTPUser(t *testing.T) (user db.User, password string) {
+	user, password = randomTeacherUser(t)
+
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+
+	user.EmailVerifiedAt = time.Now()
+	user.TotpSecret = secret
+	user.TotpEnabledAt = time.Now()
+	user.TotpLastStep = util.TOTPStep(time.Now()) - 10
+	return
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := util.TOTPCode(secret, util.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+func TestLoginRequiresMFAAPI(t *testing.T) {
+	totpUser, totpPassword := randomTOTPUser(t)
+	teacher, teacherPassword := randomTeacherUser(t)
+
+	testCases := []struct {
+		name              string
+		user              db.User
+		password          string
+		requireTeacherMFA bool
+		buildStubs        func(store *mockdb.MockStore)
+		checkResponse     func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "TOTPEnabled",
+			user:     totpUser,
+			password: totpPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateMFAChallengeParams) (db.MfaChallenge, error) {
+						require.Equal(t, totpUser.ID, arg.UserID)
+						require.WithinDuration(t, time.Now().Add(5*time.Minute), arg.ExpiresAt, time.Second)
+						return db.MfaChallenge{UserID: arg.UserID, TokenHash: arg.TokenHash, ExpiresAt: arg.ExpiresAt}, nil
+					})
+				store.EXPECT().SetUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp mfaChallengeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, rsp.MFARequired)
+				require.NotEmpty(t, rsp.MFAToken)
+				require.False(t, rsp.EnrollmentRequired)
+				require.Empty(t, rsp.TOTPSecret)
+				require.NotContains(t, recorder.Body.String(), "access_token")
+			},
+		},
+		{
+			name:              "TeacherMustEnroll",
+			user:              teacher,
+			password:          teacherPassword,
+			requireTeacherMFA: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.SetUserTOTPSecretParams) (db.User, error) {
+						enrolling := teacher
+						enrolling.TotpSecret = arg.TotpSecret
+						return enrolling, nil
+					})
+				store.EXPECT().CreateMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{UserID: teacher.ID}, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp mfaChallengeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, rsp.MFARequired)
+				require.True(t, rsp.EnrollmentRequired)
+				require.NotEmpty(t, rsp.TOTPSecret)
+				require.Contains(t, rsp.ProvisioningURI, "secret="+rsp.TOTPSecret)
+			},
+		},
+		{
+			name:     "TeacherNotRequired",
+			user:     teacher,
+			password: teacherPassword,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "access_token")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			stubLoginNotLocked(store)
+			store.EXPECT().GetByUsername(gomock.Any(), gomock.Eq(tc.user.Username)).
+				Times(1).
+				Return(tc.user, nil)
+			store.EXPECT().DeleteLoginFailure(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.RequireTeacherMFA = tc.requireTeacherMFA
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"username": tc.user.Username.String,
+				"password": tc.password,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoginMFAAPI(t *testing.T) {
+	user, _ := randomTOTPUser(t)
+	mfaToken, err := util.NewSecret()
+	require.NoError(t, err)
+
+	challenge := db.MfaChallenge{
+		ID:        util.RandomInt(1, 100),
+		UserID:    user.ID,
+		TokenHash: util.HashSecret(mfaToken),
+		ExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+
+	enrolling := user
+	enrolling.TotpEnabledAt = time.Time{}
+	enrolling.TotpLastStep = 0
+
+	recoveryCode, err := util.NewRecoveryCode()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          func() gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func() gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": currentTOTPCode(t, user.TotpSecret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubMFAChallenge(store, challenge, user)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UseUserTOTPStepParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.ID)
+						require.InDelta(t, util.TOTPStep(time.Now()), arg.TotpLastStep, 1)
+						return user, nil
+					})
+				stubMFASuccess(store, challenge, user)
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.AccessToken)
+				require.NotEmpty(t, rsp.RefreshToken)
+				require.Empty(t, rsp.RecoveryCodes)
+				require.True(t, rsp.User.TOTPEnabled)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: func() gin.H {
+				return gin.H{"mfa_token": mfaToken, "recovery_code": " " + recoveryCode + " "}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubMFAChallenge(store, challenge, user)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UseRecoveryCodeParams) (db.RecoveryCode, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, util.HashSecret(recoveryCode), arg.CodeHash)
+						return db.RecoveryCode{UserID: arg.UserID, CodeHash: arg.CodeHash, UsedAt: arg.UsedAt}, nil
+					})
+				stubMFASuccess(store, challenge, user)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Enrollment",
+			body: func() gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": currentTOTPCode(t, user.TotpSecret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubMFAChallenge(store, challenge, enrolling)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				stubMFASuccess(store, challenge, user)
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.EnableTOTPTxParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Len(t, arg.RecoveryCodeHashes, recoveryCodeCount)
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp loginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.AccessToken)
+				require.Len(t, rsp.RecoveryCodes, recoveryCodeCount)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: func() gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": "000000"}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubMFAChallenge(store, challenge, user)
+				stubMFAFailure(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errInvalidMFACode.Error())
+			},
+		},
+		{
+			name: "ReplayedCode",
+			body: func() gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": currentTOTPCode(t, user.TotpSecret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubMFAChallenge(store, challenge, user)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				stubMFAFailure(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: func() gin.H {
+				return gin.H{"mfa_token": mfaToken, "recovery_code": recoveryCode}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubMFAChallenge(store, challenge, user)
+				store.EXPECT().UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, sql.ErrNoRows)
+				stubMFAFailure(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidChallenge",
+			body: func() gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": currentTOTPCode(t, user.TotpSecret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MfaChallenge{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errMFAChallengeInvalid.Error())
+			},
+		},
+		{
+			name: "Locked",
+			body: func() gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": currentTOTPCode(t, user.TotpSecret)}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().GetLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{LockedUntil: time.Now().Add(time.Minute)}, nil)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name: "MissingCode",
+			body: func() gin.H {
+				return gin.H{"mfa_token": mfaToken}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body())
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login/mfa", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// stubMFAChallenge finds the challenge of the user and lets the login through
+// the lockout check
+func stubMFAChallenge(store *mockdb.MockStore, challenge db.MfaChallenge, user db.User) {
+	store.EXPECT().GetMFAChallenge(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.GetMFAChallengeParams) (db.MfaChallenge, error) {
+			if arg.TokenHash != challenge.TokenHash {
+				return db.MfaChallenge{}, sql.ErrNoRows
+			}
+			return challenge, nil
+		})
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+		Times(1).
+		Return(user, nil)
+	stubLoginNotLocked(store)
+}
+
+// stubMFASuccess uses up the challenge and creates the session
+func stubMFASuccess(store *mockdb.MockStore, challenge db.MfaChallenge, user db.User) {
+	store.EXPECT().DeleteLoginFailure(gomock.Any(), gomock.Eq(loginUsernameKey(user.Username.String))).
+		Times(1).
+		Return(nil)
+	store.EXPECT().UseMFAChallenge(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.UseMFAChallengeParams) (db.MfaChallenge, error) {
+			if arg.ID != challenge.ID {
+				return db.MfaChallenge{}, sql.ErrNoRows
+			}
+			return challenge, nil
+		})
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+		Times(1)
+}
+
+// stubMFAFailure counts the wrong code as a failed login
+func stubMFAFailure(store *mockdb.MockStore) {
+	store.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(db.LoginFailure{FailedCount: 1}, nil)
+	store.EXPECT().UseMFAChallenge(gomock.Any(), gomock.Any()).
+		Times(0)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).
+		Times(0)
+}
+
+func TestTOTPEnrollmentAPI(t *testing.T) {
+	user, _ := randomTeacherUser(t)
+	other, _ := randomStudentUser(t)
+	other.ID = user.ID + 1
+	enabled, _ := randomTOTPUser(t)
+	enabled.ID = user.ID
+
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+	pending := user
+	pending.TotpSecret = secret
+
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		path          string
+		body          func() gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Begin",
+			authUser: user,
+			path:     "totp",
+			body:     func() gin.H { return gin.H{} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().SetUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.SetUserTOTPSecretParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.ID)
+						require.Len(t, arg.TotpSecret, 32)
+
+						pending := user
+						pending.TotpSecret = arg.TotpSecret
+						return pending, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp totpEnrollmentResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.NotEmpty(t, rsp.TOTPSecret)
+				require.Contains(t, rsp.ProvisioningURI, "otpauth://totp/")
+				require.Contains(t, rsp.ProvisioningURI, user.Username.String)
+			},
+		},
+		{
+			name:     "BeginAlreadyEnabled",
+			authUser: user,
+			path:     "totp",
+			body:     func() gin.H { return gin.H{} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().SetUserTOTPSecret(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "BeginOtherUser",
+			authUser: other,
+			path:     "totp",
+			body:     func() gin.H { return gin.H{} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "Confirm",
+			authUser: user,
+			path:     "totp/confirm",
+			body:     func() gin.H { return gin.H{"code": currentTOTPCode(t, secret)} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.EnableTOTPTxParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.InDelta(t, util.TOTPStep(time.Now()), arg.Step, 1)
+						require.Len(t, arg.RecoveryCodeHashes, recoveryCodeCount)
+						return enabled, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp recoveryCodesResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.RecoveryCodes, recoveryCodeCount)
+			},
+		},
+		{
+			name:     "ConfirmWrongCode",
+			authUser: user,
+			path:     "totp/confirm",
+			body:     func() gin.H { return gin.H{"code": "000000"} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "ConfirmWithoutSecret",
+			authUser: user,
+			path:     "totp/confirm",
+			body:     func() gin.H { return gin.H{"code": "123456"} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().EnableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "RegenerateRecoveryCodes",
+			authUser: user,
+			path:     "totp/recovery_codes",
+			body:     func() gin.H { return gin.H{"code": currentTOTPCode(t, enabled.TotpSecret)} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().UseUserTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().ReplaceRecoveryCodesTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReplaceRecoveryCodesTxParams) error {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Len(t, arg.CodeHashes, recoveryCodeCount)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp recoveryCodesResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp.RecoveryCodes, recoveryCodeCount)
+			},
+		},
+		{
+			name:     "RegenerateNotEnabled",
+			authUser: user,
+			path:     "totp/recovery_codes",
+			body:     func() gin.H { return gin.H{"code": "123456"} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().ReplaceRecoveryCodesTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body())
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/users/%d/%s", user.ID, tc.path)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.authUser.ID, tc.authUser.Username.String, tc.authUser.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDisableTOTPAPI(t *testing.T) {
+	user, password := randomTOTPUser(t)
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	admin.ID = user.ID + 1
+	student, _ := randomStudentUser(t)
+	student.ID = user.ID + 2
+
+	disabled := user
+	disabled.TotpSecret = ""
+	disabled.TotpEnabledAt = time.Time{}
+
+	testCases := []struct {
+		name              string
+		authUser          db.User
+		body              gin.H
+		requireTeacherMFA bool
+		buildStubs        func(store *mockdb.MockStore)
+		checkResponse     func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			authUser: user,
+			body:     gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(disabled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp privateUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.False(t, rsp.TOTPEnabled)
+			},
+		},
+		{
+			name:     "WrongPassword",
+			authUser: user,
+			body:     gin.H{"password": "incorrect"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:              "RequiredForTeachers",
+			authUser:          user,
+			body:              gin.H{"password": password},
+			requireTeacherMFA: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:              "AdminReset",
+			authUser:          admin,
+			body:              gin.H{},
+			requireTeacherMFA: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(disabled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUser",
+			authUser: student,
+			body:     gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().DisableTOTPTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.RequireTeacherMFA = tc.requireTeacherMFA
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/users/%d/totp/disable", user.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.authUser.ID, tc.authUser.Username.String, tc.authUser.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
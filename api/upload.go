@@ -0,0 +1,114 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// sniffLength is the number of leading bytes http.DetectContentType looks at
+	sniffLength = 512
+
+	// uploadFormOverhead is the room left in an upload request for the other
+	// form fields and the multipart headers around the file
+	uploadFormOverhead = 64 << 10
+)
+
+// bindUpload binds a multipart request with a file of at most maxSize bytes.
+// The body is cut off a little past maxSize, so an oversized upload is
+// rejected while it is read instead of after gin has buffered all of it. It
+// writes the error response itself.
+func bindUpload(ctx *gin.Context, req interface{}, maxSize int64) bool {
+	if maxSize > 0 {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize+uploadFormOverhead)
+	}
+
+	if err := ctx.ShouldBind(req); err != nil {
+		if isBodyTooLarge(err) {
+			err := fmt.Errorf("file is too large, maximum size is %d bytes", maxSize)
+			ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(err))
+			return false
+		}
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// isBodyTooLarge reports whether err comes from a body cut off by
+// http.MaxBytesReader. The multipart reader does not always wrap the error,
+// so only its message is left to go by.
+func isBodyTooLarge(err error) bool {
+	return strings.Contains(err.Error(), "http: request body too large")
+}
+
+// validUpload checks an uploaded file against maxSize and the allowed content
+// types. The content type is sniffed from the leading bytes of the file, the
+// extension and the client supplied header are not trusted. It writes the
+// error response itself and returns the detected content type.
+func (server *Server) validUpload(ctx *gin.Context, fileHeader *multipart.FileHeader, maxSize int64) (string, bool) {
+	if maxSize > 0 && fileHeader.Size > maxSize {
+		err := fmt.Errorf("file is too large, maximum size is %d bytes", maxSize)
+		ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(err))
+		return "", false
+	}
+
+	contentType, err := sniffContentType(fileHeader)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return "", false
+	}
+
+	if !server.isAllowedFileType(contentType) {
+		err := fmt.Errorf("unsupported file type %s", contentType)
+		ctx.JSON(http.StatusUnsupportedMediaType, errorResponse(err))
+		return "", false
+	}
+
+	return contentType, true
+}
+
+func sniffContentType(fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buffer := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	return http.DetectContentType(buffer[:n]), nil
+}
+
+// isAllowedFileType reports whether contentType is in config.AllowedFileTypes,
+// parameters such as charset are ignored. An empty list allows every type.
+func (server *Server) isAllowedFileType(contentType string) bool {
+	if len(server.config.AllowedFileTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range server.config.AllowedFileTypes {
+		if allowed == mediaType {
+			return true
+		}
+	}
+
+	return false
+}
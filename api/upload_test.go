@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidUpload(t *testing.T) {
+	pdfContent := []byte("%PDF-1.4\n%âãÏÓ\n1 0 obj\n<<>>\nendobj\n")
+	pngContent := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	textContent := []byte("just some plain text")
+
+	testCases := []struct {
+		name          string
+		fileName      string
+		content       []byte
+		maxSize       int64
+		checkResponse func(t *testing.T, contentType string, valid bool, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			fileName: "homework.pdf",
+			content:  pdfContent,
+			maxSize:  1024,
+			checkResponse: func(t *testing.T, contentType string, valid bool, recorder *httptest.ResponseRecorder) {
+				require.True(t, valid)
+				require.Equal(t, "application/pdf", contentType)
+			},
+		},
+		{
+			name:     "AllowedTypeWithParams",
+			fileName: "notes.txt",
+			content:  textContent,
+			maxSize:  1024,
+			checkResponse: func(t *testing.T, contentType string, valid bool, recorder *httptest.ResponseRecorder) {
+				require.True(t, valid)
+				require.Equal(t, "text/plain; charset=utf-8", contentType)
+			},
+		},
+		{
+			name:     "TooLarge",
+			fileName: "homework.pdf",
+			content:  pdfContent,
+			maxSize:  8,
+			checkResponse: func(t *testing.T, contentType string, valid bool, recorder *httptest.ResponseRecorder) {
+				require.False(t, valid)
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+		{
+			name:     "UnsupportedType",
+			fileName: "image.png",
+			content:  pngContent,
+			maxSize:  1024,
+			checkResponse: func(t *testing.T, contentType string, valid bool, recorder *httptest.ResponseRecorder) {
+				require.False(t, valid)
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+		{
+			name:     "SpoofedExtension",
+			fileName: "image.pdf",
+			content:  pngContent,
+			maxSize:  1024,
+			checkResponse: func(t *testing.T, contentType string, valid bool, recorder *httptest.ResponseRecorder) {
+				require.False(t, valid)
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := newTestServer(t, mockdb.NewMockStore(ctrl))
+			server.config.AllowedFileTypes = []string{"application/pdf", "text/plain"}
+
+			recorder := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(recorder)
+
+			fileHeader := newTestFileHeader(t, tc.fileName, tc.content)
+			contentType, valid := server.validUpload(ctx, fileHeader, tc.maxSize)
+			tc.checkResponse(t, contentType, valid, recorder)
+		})
+	}
+}
+
+func TestUploadBodyTooLarge(t *testing.T) {
+	const maxSize = 1024
+
+	testCases := []struct {
+		name   string
+		method string
+		url    string
+		role   string
+		size   int64
+	}{
+		{
+			name:   "CreateHomework",
+			method: http.MethodPost,
+			url:    "/homeworks/create",
+			role:   util.RoleTeacher,
+			size:   maxSize,
+		},
+		{
+			name:   "UpdateHomework",
+			method: http.MethodPut,
+			url:    "/homeworks/1",
+			role:   util.RoleTeacher,
+			size:   maxSize,
+		},
+		{
+			name:   "CreateSolution",
+			method: http.MethodPost,
+			url:    "/homeworks/1/solutions/create",
+			role:   util.RoleStudent,
+			size:   maxSize,
+		},
+		{
+			name:   "UpdateSolution",
+			method: http.MethodPut,
+			url:    "/solutions/1",
+			role:   util.RoleStudent,
+			size:   maxSize,
+		},
+		{
+			name:   "GradeSolution",
+			method: http.MethodPut,
+			url:    "/solutions/1/grade",
+			role:   util.RoleTeacher,
+			size:   maxSize,
+		},
+		{
+			name:   "ImportUsers",
+			method: http.MethodPost,
+			url:    "/users/import",
+			role:   util.RoleTeacher,
+			size:   maxImportFileSize,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// no store calls are expected, the request ends while binding
+			server := newTestServer(t, mockdb.NewMockStore(ctrl))
+			server.config.MaxHomeworkFileSize = maxSize
+			server.config.MaxSolutionFileSize = maxSize
+			recorder := httptest.NewRecorder()
+
+			content := bytes.Repeat([]byte("a"), int(tc.size+uploadFormOverhead+1))
+			body, contentType := newMultipartBody(t, nil, "file.txt", content)
+
+			request, err := http.NewRequest(tc.method, tc.url, body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", contentType)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, 1, "user", tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+		})
+	}
+}
+
+func newTestFileHeader(t *testing.T, fileName string, content []byte) *multipart.FileHeader {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(int64(body.Len()))
+	require.NoError(t, err)
+
+	return form.File["file"][0]
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"log"
 	"net/http"
 	"time"
 
@@ -21,7 +23,8 @@ type userResponse struct {
 	Fullname          string    `json:"fullname"`
 	Email             string    `json:"email"`
 	PhoneNumber       string    `json:"phone_number"`
-	IsTeacher         bool      `json:"is_teacher"`
+	Role              string    `json:"role"`
+	EmailVerifiedAt   time.Time `json:"email_verified_at"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -33,19 +36,38 @@ func newUserResponse(user db.User) userResponse {
 		Fullname:          user.Fullname.String,
 		Email:             user.Email.String,
 		PhoneNumber:       user.PhoneNumber.String,
-		IsTeacher:         user.IsTeacher,
+		Role:              user.Role,
+		EmailVerifiedAt:   user.EmailVerifiedAt,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
 }
 
+// privateUserResponse adds the account state that is only shown to the user
+// themselves and to whoever manages users
+type privateUserResponse struct {
+	userResponse
+	TOTPEnabled bool      `json:"totp_enabled"`
+	IsActive    bool      `json:"is_active"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+func newPrivateUserResponse(user db.User) privateUserResponse {
+	return privateUserResponse{
+		userResponse: newUserResponse(user),
+		TOTPEnabled:  !user.TotpEnabledAt.IsZero(),
+		IsActive:     user.DeletedAt.IsZero(),
+		DeletedAt:    user.DeletedAt,
+	}
+}
+
 type createUserRequest struct {
 	Username    string `json:"username" binding:"required,alphanum"`
-	Password    string `json:"password" binding:"required,min=6"`
+	Password    string `json:"password" binding:"required"`
 	Fullname    string `json:"fullname" binding:"required"`
-	Email       string `json:"email" binding:"required"`
+	Email       string `json:"email" binding:"required,email"`
 	PhoneNumber string `json:"phone_number" binding:"required"`
-	TeacherKey  string `json:"teacher_key"`
+	InviteCode  string `json:"invite_code"`
 }
 
 func (server *Server) createUser(ctx *gin.Context) {
@@ -55,16 +77,18 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	var IsTeacher bool
-	if req.TeacherKey == server.config.SignUpKeyForTeacher {
-		IsTeacher = true
-	} else {
-		IsTeacher = false
+	newUser := db.User{
+		Username: sql.NullString{String: req.Username, Valid: true},
+		Email:    sql.NullString{String: req.Email, Valid: true},
+	}
+	if !server.checkNewPassword(ctx, req.Password, newUser) {
+		return
 	}
 
 	hashPassword, err := util.HashPassword(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
 	arg := db.CreateUserParams{
 		Username:       sql.NullString{String: req.Username, Valid: true},
@@ -72,11 +96,25 @@ func (server *Server) createUser(ctx *gin.Context) {
 		Fullname:       sql.NullString{String: req.Fullname, Valid: true},
 		Email:          sql.NullString{String: req.Email, Valid: true},
 		PhoneNumber:    sql.NullString{String: req.PhoneNumber, Valid: true},
-		IsTeacher:      IsTeacher,
+		Role:           util.RoleStudent,
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	// an invite decides the role of the account, without one it is a student
+	var user db.User
+	if req.InviteCode == "" {
+		user, err = server.store.CreateUser(ctx, arg)
+	} else {
+		user, err = server.store.SignUpWithInviteTx(ctx, db.SignUpWithInviteTxParams{
+			User:       arg,
+			CodeHash:   util.HashSecret(req.InviteCode),
+			RedeemedAt: time.Now(),
+		})
+	}
 	if err != nil {
+		if err == db.ErrInviteInvalid {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
 			case "unique_violation":
@@ -88,7 +126,13 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
-	rsp := newUserResponse(user)
+	// the account exists either way, a failed mail is fixed by asking for a
+	// new link
+	if err := server.sendEmailVerification(ctx, user); err != nil {
+		log.Printf("cannot send verification mail to user %d: %v", user.ID, err)
+	}
+
+	rsp := newPrivateUserResponse(user)
 	ctx.JSON(http.StatusOK, rsp)
 }
 
@@ -98,12 +142,13 @@ type loginUserRequest struct {
 }
 
 type loginUserResponse struct {
-	SessionID             uuid.UUID    `json:"session_id"`
-	AccessToken           string       `json:"access_token"`
-	AccessTokenExpiredAt  time.Time    `json:"access_token_expired_at"`
-	RefreshToken          string       `json:"refresh_token"`
-	RefreshTokenExpiredAt time.Time    `json:"refresh_token_expired_at"`
-	User                  userResponse `json:"user"`
+	SessionID             uuid.UUID           `json:"session_id"`
+	AccessToken           string              `json:"access_token"`
+	AccessTokenExpiredAt  time.Time           `json:"access_token_expired_at"`
+	RefreshToken          string              `json:"refresh_token"`
+	RefreshTokenExpiredAt time.Time           `json:"refresh_token_expired_at"`
+	User                  privateUserResponse `json:"user"`
+	RecoveryCodes         []string            `json:"recovery_codes,omitempty"`
 }
 
 func (server *Server) loginUser(ctx *gin.Context) {
@@ -113,46 +158,119 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	lockedUntil, err := server.loginLockedUntil(ctx, req.Username, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !lockedUntil.IsZero() {
+		abortLockedLogin(ctx, lockedUntil)
+		return
+	}
+
 	username := sql.NullString{String: req.Username, Valid: true}
 
+	// unknown usernames and wrong passwords get the same answer, so logins can
+	// not be used to find out which accounts exist
 	user, err := server.store.GetByUsername(ctx, username)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hashedPassword := user.HashedPassword
+	if err == sql.ErrNoRows {
+		hashedPassword = dummyPasswordHash
+	}
+
+	// an account that can not log in gets the same answer too, and only once
+	// the password is right
+	if err != nil || util.CheckPassword(req.Password, hashedPassword) != nil || !server.canLogin(ctx, user) {
+		err = server.recordLoginFailure(ctx, req.Username, ctx.ClientIP())
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
 		return
 	}
 
-	err = util.CheckPassword(req.Password, user.HashedPassword)
+	err = server.store.DeleteLoginFailure(ctx, loginUsernameKey(req.Username))
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
-		user.ID,
-		user.Username.String,
-		user.IsTeacher,
-		server.config.AccessTokenDuration,
-	)
+	server.finishLogin(ctx, user)
+}
+
+// canLogin reports whether a user whose identity was checked may log in.
+// Deactivated accounts may not, and neither may unverified ones when
+// verification is required. Those are mailed a new link, it is how their
+// owner learns why the login failed.
+func (server *Server) canLogin(ctx context.Context, user db.User) bool {
+	if !user.DeletedAt.IsZero() {
+		return false
+	}
+
+	if server.config.RequireVerifiedEmail && user.EmailVerifiedAt.IsZero() {
+		if err := server.sendEmailVerification(ctx, user); err != nil {
+			log.Printf("cannot send verification mail to user %d: %v", user.ID, err)
+		}
+		return false
+	}
+
+	return true
+}
+
+// finishLogin answers the login of a user who passed canLogin, with the
+// session or with the challenge for their second factor
+func (server *Server) finishLogin(ctx *gin.Context, user db.User) {
+	if server.mfaRequired(user) {
+		rsp, err := server.createMFAChallenge(ctx, user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	rsp, err := server.createLoginSession(ctx, user)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// createLoginSession issues the refresh and access token of a user that
+// passed every login check
+func (server *Server) createLoginSession(ctx *gin.Context, user db.User) (loginUserResponse, error) {
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 		user.ID,
 		user.Username.String,
-		user.IsTeacher,
+		user.Role,
+		uuid.Nil,
 		server.config.RefreshTokenDuration,
 	)
+	if err != nil {
+		return loginUserResponse{}, err
+	}
 
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
+		user.ID,
+		user.Username.String,
+		user.Role,
+		refreshPayload.ID,
+		server.config.AccessTokenDuration,
+	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return loginUserResponse{}, err
 	}
 
 	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
@@ -163,10 +281,10 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ClientIp:     ctx.ClientIP(),
 		IsBlocked:    false,
 		ExpiresAt:    refreshPayload.ExpiredAt,
+		FamilyID:     refreshPayload.ID,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return loginUserResponse{}, err
 	}
 
 	rsp := loginUserResponse{
@@ -175,9 +293,9 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		AccessTokenExpiredAt:  accessPayload.ExpiredAt,
 		RefreshToken:          refreshToken,
 		RefreshTokenExpiredAt: refreshPayload.ExpiredAt,
-		User:                  newUserResponse(user),
+		User:                  newPrivateUserResponse(user),
 	}
-	ctx.JSON(http.StatusOK, rsp)
+	return rsp, nil
 }
 
 type getUserRequest struct {
@@ -211,6 +329,7 @@ type listUserRequest struct {
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=20"`
 }
 
+// listUser lists the active users
 func (server *Server) listUser(ctx *gin.Context) {
 	var req listUserRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
@@ -240,10 +359,35 @@ func (server *Server) listUser(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, rsps)
 }
 
+// listAllUsers lists every user, the deactivated ones included
+func (server *Server) listAllUsers(ctx *gin.Context) {
+	var req listUserRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	users, err := server.store.ListAllUsers(ctx, db.ListAllUsersParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]privateUserResponse, 0, len(users))
+	for _, user := range users {
+		rsp = append(rsp, newPrivateUserResponse(user))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
 type updateUserInfoRequest struct {
 	Username    string `json:"username"`
 	Fullname    string `json:"fullname"`
-	Email       string `json:"email"`
+	Email       string `json:"email" binding:"omitempty,email"`
 	PhoneNumber string `json:"phone_number"`
 }
 
@@ -274,20 +418,10 @@ func (server *Server) updateUserInfo(ctx *gin.Context) {
 		return
 	}
 
-	if authPayload.Userid != user.ID {
-		// student can not update another student's infos
-		if !authPayload.IsTeacher {
-			err := errors.New("permission denied!")
-			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
-
-		// teacher can not update another teacher's infos
-		if user.IsTeacher {
-			err := errors.New("permission denied!")
-			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
+	if !canManageUser(authPayload, user) {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
 	}
 
 	var validUsername, validFullname, validEmail, validPhoneNumber bool
@@ -305,7 +439,7 @@ func (server *Server) updateUserInfo(ctx *gin.Context) {
 	}
 
 	// student can not change their username && fullname
-	if !authPayload.IsTeacher && (validFullname || validUsername) {
+	if !hasPermission(authPayload.Role, permissionManageStudents) && (validFullname || validUsername) {
 		err := errors.New("permission denied!")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
@@ -337,7 +471,14 @@ func (server *Server) updateUserInfo(ctx *gin.Context) {
 		return
 	}
 
-	rsp := newUserResponse(responseUser.User)
+	// a new address has to be verified again
+	if validEmail && reqJSON.Email != user.Email.String {
+		if err := server.sendEmailVerification(ctx, responseUser.User); err != nil {
+			log.Printf("cannot send verification mail to user %d: %v", user.ID, err)
+		}
+	}
+
+	rsp := newPrivateUserResponse(responseUser.User)
 	ctx.JSON(http.StatusOK, rsp)
 }
 
@@ -388,32 +529,93 @@ func (server *Server) updateUserPassword(ctx *gin.Context) {
 		return
 	}
 
+	if !server.checkNewPassword(ctx, reqJSON.NewPassword, user) {
+		return
+	}
+
 	hashPassword, err := util.HashPassword(reqJSON.NewPassword)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	arg := db.UpdateUserPasswordParams{
+	arg := db.UpdateUserPasswordTxParams{
 		ID:                reqURI.ID,
 		HashedPassword:    hashPassword,
 		PasswordChangedAt: time.Now(),
+		KeepHistory:       server.keepPasswordHistory(),
 	}
 
-	user, err = server.store.UpdateUserPassword(ctx, arg)
+	// every session is deleted, the user has to log in again on other devices
+	user, err = server.store.UpdateUserPasswordTx(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	server.sessions.invalidateUser(user.Username.String)
 
-	rsp := newUserResponse(user)
+	rsp := newPrivateUserResponse(user)
 	ctx.JSON(http.StatusOK, rsp)
 }
 
+type updateUserRoleRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateUserRoleRequestJSON struct {
+	Role string `json:"role" binding:"required,role"`
+}
+
+// updateUserRole lets an admin promote or demote a user. authMiddleware reads
+// the role of the session, so it applies to tokens already issued.
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var reqURI updateUserRoleRequestURI
+	if err := ctx.ShouldBindUri(&reqURI); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var reqJSON updateUserRoleRequestJSON
+	if err := ctx.ShouldBindJSON(&reqJSON); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// an admin demoting themselves could leave nobody able to manage roles
+	if authPayload.Userid == reqURI.ID {
+		err := errors.New("can not change your own role!")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+		ID:   reqURI.ID,
+		Role: reqJSON.Role,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.sessions.invalidateUser(user.Username.String)
+
+	ctx.JSON(http.StatusOK, newPrivateUserResponse(user))
+}
+
 type deleteUserRequest struct {
 	ID int64 `uri:"id" binding:"required"`
 }
 
+var errUserDeactivated = errors.New("user is deactivated!")
+
+// deleteUser deactivates the user. They can no longer log in, but their
+// records stay until purgeUser deletes them for good.
 func (server *Server) deleteUser(ctx *gin.Context) {
 	var req deleteUserRequest
 
@@ -435,27 +637,104 @@ func (server *Server) deleteUser(ctx *gin.Context) {
 
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	if authPayload.Userid != user.ID {
-		// student can not delete another account
-		if !authPayload.IsTeacher {
-			err := errors.New("permission denied!")
-			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !canManageUser(authPayload, user) {
+		err := errors.New("permission denied!")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// the records of the user stay, so the account can be reactivated
+	_, err = server.store.DeactivateUserTx(ctx, db.DeactivateUserParams{
+		ID:        req.ID,
+		DeletedAt: time.Now(),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusForbidden, errorResponse(errUserDeactivated))
 			return
 		}
 
-		// teacher can not delete another teacher's account
-		if user.IsTeacher {
-			err := errors.New("permission denied!")
-			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.sessions.invalidateUser(user.Username.String)
+	ctx.JSON(http.StatusOK, nil)
+}
+
+// reactivateUser lets a deactivated user log in again
+func (server *Server) reactivateUser(ctx *gin.Context) {
+	var req deleteUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, err := server.store.GetUser(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.ReactivateUser(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("user is not deactivated!")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPrivateUserResponse(user))
+}
+
+// purgeUser deletes a deactivated user for good, together with their
+// homeworks, solutions, grades, messages and classes and the files of them
+func (server *Server) purgeUser(ctx *gin.Context) {
+	var req deleteUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
 
-	err = server.store.DeleteUser(ctx, req.ID)
+	if user.DeletedAt.IsZero() {
+		err := errors.New("user must be deactivated before it is purged!")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	savedPaths, err := server.store.PurgeUserTx(ctx, req.ID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	server.sessions.invalidateUser(user.Username.String)
+
+	// the records are gone either way, a file left behind is only wasted space
+	for _, savedPath := range savedPaths {
+		if err := server.fileStore.Delete(ctx, savedPath); err != nil {
+			log.Printf("cannot delete file %s of purged user %d: %v", savedPath, user.ID, err)
+		}
+	}
+
 	ctx.JSON(http.StatusOK, nil)
 }
 
@@ -495,7 +774,7 @@ func (server *Server) listHomeworkByTeacher(ctx *gin.Context) {
 		return
 	}
 
-	if !user.IsTeacher {
+	if user.Role != util.RoleTeacher {
 		err := errors.New("this is not a teacher!")
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
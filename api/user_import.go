@@ -0,0 +1,376 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/lib/pq"
+)
+
+const (
+	maxImportFileSize = 1 << 20
+	maxImportRows     = 500
+
+	defaultImportInviteDuration = 7 * 24 * time.Hour
+
+	// importCredentialsPassword returns a generated password of every user in
+	// the report, importCredentialsInvite mails every user a token to set
+	// their own password
+	importCredentialsPassword = "password"
+	importCredentialsInvite   = "invite"
+
+	importStatusValid   = "valid"
+	importStatusInvalid = "invalid"
+	importStatusCreated = "created"
+)
+
+// importColumns are the columns of an import file, its header row names them
+// in any order
+var importColumns = []string{"username", "fullname", "email", "phone"}
+
+type importUsersRequest struct {
+	File        *multipart.FileHeader `form:"file" binding:"required"`
+	Credentials string                `form:"credentials" binding:"required,oneof=password invite"`
+	ClassID     int64                 `form:"class_id" binding:"omitempty,min=1"`
+	DryRun      bool                  `form:"dry_run"`
+}
+
+// importUserRow is a row of an import file, it has the rules of createUser
+type importUserRow struct {
+	Line        int
+	Username    string `binding:"required,alphanum"`
+	Fullname    string `binding:"required"`
+	Email       string `binding:"required,email"`
+	PhoneNumber string `binding:"required"`
+}
+
+type importUserResult struct {
+	Line     int      `json:"line"`
+	Username string   `json:"username"`
+	Status   string   `json:"status"`
+	Errors   []string `json:"errors,omitempty"`
+	UserID   int64    `json:"user_id,omitempty"`
+	Password string   `json:"password,omitempty"`
+	Invited  bool     `json:"invited,omitempty"`
+}
+
+type importUsersResponse struct {
+	DryRun  bool               `json:"dry_run"`
+	Created int                `json:"created"`
+	Rows    []importUserResult `json:"rows"`
+}
+
+// importUsers creates student accounts from a csv file. Every row is checked
+// first, and the users are only created when all of them are valid, in one
+// transaction. A dry run only checks the rows.
+func (server *Server) importUsers(ctx *gin.Context) {
+	var req importUsersRequest
+	if !bindUpload(ctx, &req, maxImportFileSize) {
+		return
+	}
+
+	classID := sql.NullInt64{}
+	if req.ClassID != 0 {
+		if _, ok := server.getOwnClass(ctx, req.ClassID); !ok {
+			return
+		}
+		classID = sql.NullInt64{Int64: req.ClassID, Valid: true}
+	}
+
+	if req.File.Size > maxImportFileSize {
+		err := fmt.Errorf("file is too large, maximum size is %d bytes", maxImportFileSize)
+		ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(err))
+		return
+	}
+
+	contentType, err := sniffContentType(req.File)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !strings.HasPrefix(contentType, "text/plain") {
+		err := fmt.Errorf("unsupported file type %s", contentType)
+		ctx.JSON(http.StatusUnsupportedMediaType, errorResponse(err))
+		return
+	}
+
+	rows, err := readImportFile(req.File)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rsp := importUsersResponse{
+		DryRun: req.DryRun,
+		Rows:   make([]importUserResult, len(rows)),
+	}
+
+	valid, err := server.checkImportRows(ctx, rows, rsp.Rows)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !valid {
+		ctx.JSON(http.StatusBadRequest, rsp)
+		return
+	}
+	if req.DryRun {
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	inviteDuration := server.config.ImportInviteDuration
+	if inviteDuration <= 0 {
+		inviteDuration = defaultImportInviteDuration
+	}
+
+	arg := db.ImportUsersTxParams{
+		Users:          make([]db.ImportUserParams, len(rows)),
+		ClassID:        classID,
+		ResetExpiresAt: time.Now().Add(inviteDuration),
+	}
+	// the report shows generated passwords, invited users get a reset token
+	// and nobody knows their password until they set it
+	secrets := make([]string, len(rows))
+	for i, row := range rows {
+		var password string
+		if req.Credentials == importCredentialsPassword {
+			password, err = server.passwordPolicy.Generate()
+		} else {
+			password, err = util.NewSecret()
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		secrets[i] = password
+
+		hashedPassword, err := util.HashPassword(password)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		arg.Users[i].User = db.CreateUserParams{
+			Username:       sql.NullString{String: row.Username, Valid: true},
+			HashedPassword: hashedPassword,
+			Fullname:       sql.NullString{String: row.Fullname, Valid: true},
+			Email:          sql.NullString{String: row.Email, Valid: true},
+			PhoneNumber:    sql.NullString{String: row.PhoneNumber, Valid: true},
+			Role:           util.RoleStudent,
+		}
+
+		if req.Credentials == importCredentialsInvite {
+			secrets[i], err = util.NewSecret()
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			arg.Users[i].ResetTokenHash = util.HashSecret(secrets[i])
+		}
+	}
+
+	users, err := server.store.ImportUsersTx(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	for i, user := range users {
+		result := &rsp.Rows[i]
+		result.Status = importStatusCreated
+		result.UserID = user.ID
+
+		if req.Credentials == importCredentialsPassword {
+			result.Password = secrets[i]
+			continue
+		}
+
+		// the accounts exist either way, a lost mail is fixed with
+		// forgot_password
+		err := server.sendImportInvite(ctx, user, secrets[i], arg.ResetExpiresAt)
+		if err != nil {
+			log.Printf("cannot send import invite to user %d: %v", user.ID, err)
+			result.Errors = append(result.Errors, "cannot send invite mail")
+			continue
+		}
+		result.Invited = true
+	}
+	rsp.Created = len(users)
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// readImportFile parses the rows of an import file, the line of a row is the
+// line it starts at
+func readImportFile(fileHeader *multipart.FileHeader) ([]importUserRow, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("file is empty!")
+		}
+		return nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, column := range importColumns {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("header has no %s column", column)
+		}
+	}
+
+	var rows []importUserRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("file has more than %d rows", maxImportRows)
+		}
+
+		field := func(column string) string {
+			if i := index[column]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, importUserRow{
+			Line:        line,
+			Username:    field("username"),
+			Fullname:    field("fullname"),
+			Email:       field("email"),
+			PhoneNumber: field("phone"),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("file has no rows!")
+	}
+
+	return rows, nil
+}
+
+// checkImportRows fills in a result for every row and reports whether all of
+// them are valid. A row is invalid when it breaks the rules of createUser, or
+// when its username, email or phone number is taken by a user or an earlier
+// row.
+func (server *Server) checkImportRows(ctx context.Context, rows []importUserRow, results []importUserResult) (bool, error) {
+	usernames := make(map[string]int, len(rows))
+	emails := make(map[string]int, len(rows))
+	phoneNumbers := make(map[string]int, len(rows))
+	valid := true
+
+	for i, row := range rows {
+		result := &results[i]
+		result.Line = row.Line
+		result.Username = row.Username
+
+		if err := binding.Validator.ValidateStruct(row); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+
+		if row.Username != "" {
+			username := strings.ToLower(row.Username)
+			if line, ok := usernames[username]; ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("username is already in line %d", line))
+			} else {
+				usernames[username] = row.Line
+
+				_, err := server.store.GetByUsername(ctx, sql.NullString{String: row.Username, Valid: true})
+				if err == nil {
+					result.Errors = append(result.Errors, "username is taken")
+				} else if err != sql.ErrNoRows {
+					return false, err
+				}
+			}
+		}
+
+		if row.Email != "" {
+			email := strings.ToLower(row.Email)
+			if line, ok := emails[email]; ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("email is already in line %d", line))
+			} else {
+				emails[email] = row.Line
+
+				_, err := server.store.GetUserByEmail(ctx, sql.NullString{String: row.Email, Valid: true})
+				if err == nil {
+					result.Errors = append(result.Errors, "email is taken")
+				} else if err != sql.ErrNoRows {
+					return false, err
+				}
+			}
+		}
+
+		if row.PhoneNumber != "" {
+			if line, ok := phoneNumbers[row.PhoneNumber]; ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("phone number is already in line %d", line))
+			} else {
+				phoneNumbers[row.PhoneNumber] = row.Line
+
+				_, err := server.store.GetUserByPhoneNumber(ctx, sql.NullString{String: row.PhoneNumber, Valid: true})
+				if err == nil {
+					result.Errors = append(result.Errors, "phone number is taken")
+				} else if err != sql.ErrNoRows {
+					return false, err
+				}
+			}
+		}
+
+		if len(result.Errors) > 0 {
+			result.Status = importStatusInvalid
+			valid = false
+		} else {
+			result.Status = importStatusValid
+		}
+	}
+
+	return valid, nil
+}
+
+// sendImportInvite mails an imported user the token to set their password
+// with
+func (server *Server) sendImportInvite(ctx context.Context, user db.User, resetToken string, expiresAt time.Time) error {
+	body := fmt.Sprintf("Hello %s,\n\nan account with the username %s was created for you. Use this token to set your password, it expires at %s:\n\n%s\n",
+		user.Fullname.String, user.Username.String, expiresAt.Format(time.RFC1123), resetToken)
+
+	return server.mailer.Send(ctx, user.Email.String, "Set your password", body)
+}
@@ -0,0 +1,501 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	mockmail "github.com/dongocanh96/class_manager_go/mail/mock"
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportUsersAPI(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+	student, _ := randomStudentUser(t)
+	student.ID = teacher.ID + 1
+
+	class := db.Class{
+		ID:        util.RandomInt(1, 100),
+		TeacherID: teacher.ID,
+		Name:      util.RandomString(10),
+	}
+
+	rows := [][]string{
+		{"phone", "email", "username", "fullname"},
+		{"0123456789", "alice@example.com", "alice", "Alice Nguyen"},
+		{"0987654321", "bob@example.com", "bob", "Bob Tran"},
+	}
+
+	teacherAuth := func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+		addAuthorization(t, request, tokenMaker,
+			authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+			time.Minute*15)
+	}
+
+	// stubFreeRows answers that no user has the username, email or phone
+	// number of a row
+	stubFreeRows := func(store *mockdb.MockStore, n int) {
+		store.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).
+			Times(n).
+			Return(db.User{}, sql.ErrNoRows)
+		store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).
+			Times(n).
+			Return(db.User{}, sql.ErrNoRows)
+		store.EXPECT().GetUserByPhoneNumber(gomock.Any(), gomock.Any()).
+			Times(n).
+			Return(db.User{}, sql.ErrNoRows)
+	}
+
+	importedUsers := func(arg db.ImportUsersTxParams) []db.User {
+		users := make([]db.User, len(arg.Users))
+		for i, imported := range arg.Users {
+			users[i] = db.User{
+				ID:             int64(200 + i),
+				Username:       imported.User.Username,
+				HashedPassword: imported.User.HashedPassword,
+				Fullname:       imported.User.Fullname,
+				Email:          imported.User.Email,
+				PhoneNumber:    imported.User.PhoneNumber,
+				Role:           imported.User.Role,
+			}
+		}
+		return users
+	}
+
+	testCases := []struct {
+		name          string
+		fields        map[string]string
+		rows          [][]string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore, mailer *mockmail.MockMailer)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Passwords",
+			fields:    map[string]string{"credentials": "password"},
+			rows:      rows,
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				stubFreeRows(store, 2)
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ImportUsersTxParams) ([]db.User, error) {
+						require.Len(t, arg.Users, 2)
+						require.False(t, arg.ClassID.Valid)
+						require.Equal(t, "alice", arg.Users[0].User.Username.String)
+						require.Equal(t, "Alice Nguyen", arg.Users[0].User.Fullname.String)
+						require.Equal(t, "alice@example.com", arg.Users[0].User.Email.String)
+						require.Equal(t, "0123456789", arg.Users[0].User.PhoneNumber.String)
+						for _, imported := range arg.Users {
+							require.Equal(t, util.RoleStudent, imported.User.Role)
+							require.Empty(t, imported.ResetTokenHash)
+						}
+
+						return importedUsers(arg), nil
+					})
+				mailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := requireBodyMatchImport(t, recorder.Body)
+				require.False(t, rsp.DryRun)
+				require.Equal(t, 2, rsp.Created)
+				require.Len(t, rsp.Rows, 2)
+				require.Equal(t, 2, rsp.Rows[0].Line)
+				require.Equal(t, 3, rsp.Rows[1].Line)
+
+				for i, row := range rsp.Rows {
+					require.Equal(t, importStatusCreated, row.Status)
+					require.Equal(t, int64(200+i), row.UserID)
+					require.NotEmpty(t, row.Password)
+					require.False(t, row.Invited)
+					require.Empty(t, row.Errors)
+				}
+				require.NotEqual(t, rsp.Rows[0].Password, rsp.Rows[1].Password)
+			},
+		},
+		{
+			name:      "InvitesToClass",
+			fields:    map[string]string{"credentials": "invite", "class_id": fmt.Sprint(class.ID)},
+			rows:      rows,
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				tokenHashes := map[string]string{}
+
+				store.EXPECT().GetClass(gomock.Any(), gomock.Eq(class.ID)).
+					Times(1).
+					Return(class, nil)
+				stubFreeRows(store, 2)
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ImportUsersTxParams) ([]db.User, error) {
+						require.Equal(t, sql.NullInt64{Int64: class.ID, Valid: true}, arg.ClassID)
+						require.WithinDuration(t, time.Now().Add(time.Hour*24*7), arg.ResetExpiresAt, time.Minute)
+						for _, imported := range arg.Users {
+							require.NotEmpty(t, imported.ResetTokenHash)
+							tokenHashes[imported.User.Email.String] = imported.ResetTokenHash
+						}
+
+						return importedUsers(arg), nil
+					})
+				mailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(ctx context.Context, to string, subject string, body string) error {
+						fields := strings.Fields(body)
+						require.Equal(t, tokenHashes[to], util.HashSecret(fields[len(fields)-1]))
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := requireBodyMatchImport(t, recorder.Body)
+				require.Equal(t, 2, rsp.Created)
+				for _, row := range rsp.Rows {
+					require.Equal(t, importStatusCreated, row.Status)
+					require.True(t, row.Invited)
+					require.Empty(t, row.Password)
+				}
+			},
+		},
+		{
+			name:      "MailFailed",
+			fields:    map[string]string{"credentials": "invite"},
+			rows:      rows[:2],
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				stubFreeRows(store, 1)
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ImportUsersTxParams) ([]db.User, error) {
+						return importedUsers(arg), nil
+					})
+				mailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("smtp is down"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := requireBodyMatchImport(t, recorder.Body)
+				require.Equal(t, 1, rsp.Created)
+				require.Equal(t, importStatusCreated, rsp.Rows[0].Status)
+				require.False(t, rsp.Rows[0].Invited)
+				require.NotEmpty(t, rsp.Rows[0].Errors)
+			},
+		},
+		{
+			name:      "DryRun",
+			fields:    map[string]string{"credentials": "password", "dry_run": "true"},
+			rows:      rows,
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				stubFreeRows(store, 2)
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := requireBodyMatchImport(t, recorder.Body)
+				require.True(t, rsp.DryRun)
+				require.Zero(t, rsp.Created)
+				for _, row := range rsp.Rows {
+					require.Equal(t, importStatusValid, row.Status)
+					require.Zero(t, row.UserID)
+					require.Empty(t, row.Password)
+				}
+			},
+		},
+		{
+			name:   "InvalidRows",
+			fields: map[string]string{"credentials": "password"},
+			rows: [][]string{
+				{"username", "fullname", "email", "phone"},
+				{"alice", "Alice Nguyen", "alice@example.com", "0123456789"},
+				{"ALICE", "Alice Le", "not-an-email", "0123456788"},
+				{"bob", "Bob Tran", "bob@example.com", "0987654321"},
+				{"carol", "", "Alice@example.com", "0987654320"},
+			},
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetByUsername(gomock.Any(), gomock.Eq(sql.NullString{String: "alice", Valid: true})).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetByUsername(gomock.Any(), gomock.Eq(sql.NullString{String: "bob", Valid: true})).
+					Times(1).
+					Return(student, nil)
+				store.EXPECT().GetByUsername(gomock.Any(), gomock.Eq(sql.NullString{String: "carol", Valid: true})).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(3).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByPhoneNumber(gomock.Any(), gomock.Any()).
+					Times(4).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				rsp := requireBodyMatchImport(t, recorder.Body)
+				require.Zero(t, rsp.Created)
+				require.Len(t, rsp.Rows, 4)
+
+				require.Equal(t, importStatusValid, rsp.Rows[0].Status)
+				require.Empty(t, rsp.Rows[0].Errors)
+
+				// invalid email and a username already in line 2
+				require.Equal(t, importStatusInvalid, rsp.Rows[1].Status)
+				require.Len(t, rsp.Rows[1].Errors, 2)
+				require.Contains(t, rsp.Rows[1].Errors[1], "line 2")
+
+				require.Equal(t, importStatusInvalid, rsp.Rows[2].Status)
+				require.Equal(t, []string{"username is taken"}, rsp.Rows[2].Errors)
+
+				// no fullname and an email already in line 2
+				require.Equal(t, importStatusInvalid, rsp.Rows[3].Status)
+				require.Len(t, rsp.Rows[3].Errors, 2)
+			},
+		},
+		{
+			name:   "PhoneNumberTaken",
+			fields: map[string]string{"credentials": "password"},
+			rows: [][]string{
+				{"username", "fullname", "email", "phone"},
+				{"alice", "Alice Nguyen", "alice@example.com", "0123456789"},
+				{"bob", "Bob Tran", "bob@example.com", "0123456789"},
+				{"carol", "Carol Pham", "carol@example.com", "0987654321"},
+			},
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).
+					Times(3).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(3).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByPhoneNumber(gomock.Any(), gomock.Eq(sql.NullString{String: "0123456789", Valid: true})).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().GetUserByPhoneNumber(gomock.Any(), gomock.Eq(sql.NullString{String: "0987654321", Valid: true})).
+					Times(1).
+					Return(student, nil)
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				rsp := requireBodyMatchImport(t, recorder.Body)
+				require.Len(t, rsp.Rows, 3)
+
+				require.Equal(t, importStatusValid, rsp.Rows[0].Status)
+				require.Equal(t, []string{"phone number is already in line 2"}, rsp.Rows[1].Errors)
+				require.Equal(t, []string{"phone number is taken"}, rsp.Rows[2].Errors)
+			},
+		},
+		{
+			name:   "MissingColumn",
+			fields: map[string]string{"credentials": "password"},
+			rows: [][]string{
+				{"username", "fullname", "email"},
+				{"alice", "Alice Nguyen", "alice@example.com"},
+			},
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NoRows",
+			fields:    map[string]string{"credentials": "password"},
+			rows:      rows[:1],
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidCredentials",
+			fields:    map[string]string{"credentials": "none"},
+			rows:      rows,
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotOwnClass",
+			fields: map[string]string{"credentials": "password", "class_id": fmt.Sprint(class.ID)},
+			rows:   rows,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, teacher.ID+1, teacher.Username.String, teacher.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().GetClass(gomock.Any(), gomock.Eq(class.ID)).
+					Times(1).
+					Return(class, nil)
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "Student",
+			fields: map[string]string{"credentials": "password"},
+			rows:   rows,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker,
+					authorizationTypeBearer, student.ID, student.Username.String, student.Role,
+					time.Minute*15)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "UniqueViolation",
+			fields:    map[string]string{"credentials": "password"},
+			rows:      rows,
+			setupAuth: teacherAuth,
+			buildStubs: func(store *mockdb.MockStore, mailer *mockmail.MockMailer) {
+				stubFreeRows(store, 2)
+				store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockMailer(ctrl)
+			tc.buildStubs(store, mailer)
+
+			server := newTestServer(t, store)
+			server.mailer = mailer
+			recorder := httptest.NewRecorder()
+
+			var lines []string
+			for _, row := range tc.rows {
+				lines = append(lines, strings.Join(row, ","))
+			}
+			body, contentType := newMultipartBody(t, tc.fields, "students.csv", []byte(strings.Join(lines, "\n")+"\n"))
+
+			request, err := http.NewRequest(http.MethodPost, "/users/import", body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", contentType)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestImportUsersDefaultInviteDuration(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+	store.EXPECT().GetUserByEmail(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+	store.EXPECT().GetUserByPhoneNumber(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+	store.EXPECT().ImportUsersTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.ImportUsersTxParams) ([]db.User, error) {
+			require.WithinDuration(t, time.Now().Add(defaultImportInviteDuration), arg.ResetExpiresAt, time.Second)
+			return []db.User{{ID: 200, Username: arg.Users[0].User.Username, Email: arg.Users[0].User.Email}}, nil
+		})
+
+	mailer := mockmail.NewMockMailer(ctrl)
+	mailer.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	server := newTestServer(t, store)
+	server.mailer = mailer
+	server.config.ImportInviteDuration = 0
+	recorder := httptest.NewRecorder()
+
+	content := "username,fullname,email,phone\nalice,Alice Nguyen,alice@example.com,0123456789\n"
+	body, contentType := newMultipartBody(t, map[string]string{"credentials": "invite"}, "students.csv", []byte(content))
+
+	request, err := http.NewRequest(http.MethodPost, "/users/import", body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", contentType)
+
+	addAuthorization(t, request, server.tokenMaker,
+		authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
+		time.Minute*15)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func requireBodyMatchImport(t *testing.T, body *bytes.Buffer) importUsersResponse {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var rsp importUsersResponse
+	err = json.Unmarshal(data, &rsp)
+	require.NoError(t, err)
+	return rsp
+}
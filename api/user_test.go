@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 
 	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
 	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/storage"
 	"github.com/dongocanh96/class_manager_go/token"
 	"github.com/dongocanh96/class_manager_go/util"
 	"github.com/gin-gonic/gin"
@@ -55,6 +57,7 @@ func EqCreateUserParams(arg db.CreateUserParams, password string) gomock.Matcher
 func TestCreateUserApi(t *testing.T) {
 	student, studentPassword := randomStudentUser(t)
 	teacher, teacherPassword := randomTeacherUser(t)
+	inviteCode := util.RandomString(32)
 
 	testCases := []struct {
 		name          string
@@ -70,7 +73,6 @@ func TestCreateUserApi(t *testing.T) {
 				"fullname":     student.Fullname.String,
 				"email":        student.Email.String,
 				"phone_number": student.PhoneNumber.String,
-				"teacher_key":  "",
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.CreateUserParams{
@@ -78,12 +80,16 @@ func TestCreateUserApi(t *testing.T) {
 					Fullname:    student.Fullname,
 					Email:       student.Email,
 					PhoneNumber: student.PhoneNumber,
-					IsTeacher:   student.IsTeacher,
+					Role:        student.Role,
 				}
 				store.EXPECT().
 					CreateUser(gomock.Any(), EqCreateUserParams(arg, studentPassword)).
 					Times(1).
 					Return(student, nil)
+				store.EXPECT().
+					CreateEmailVerification(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.EmailVerification{UserID: student.ID, Email: student.Email.String}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -98,7 +104,7 @@ func TestCreateUserApi(t *testing.T) {
 				"fullname":     teacher.Fullname.String,
 				"email":        teacher.Email.String,
 				"phone_number": teacher.PhoneNumber.String,
-				"teacher_key":  "5WC7CnJ99KBhyPF",
+				"invite_code":  inviteCode,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.CreateUserParams{
@@ -106,12 +112,24 @@ func TestCreateUserApi(t *testing.T) {
 					Fullname:    teacher.Fullname,
 					Email:       teacher.Email,
 					PhoneNumber: teacher.PhoneNumber,
-					IsTeacher:   true,
+					Role:        util.RoleStudent,
 				}
 				store.EXPECT().
-					CreateUser(gomock.Any(), EqCreateUserParams(arg, teacherPassword)).
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					SignUpWithInviteTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(teacher, nil)
+					DoAndReturn(func(ctx context.Context, txArg db.SignUpWithInviteTxParams) (db.User, error) {
+						require.True(t, EqCreateUserParams(arg, teacherPassword).Matches(txArg.User))
+						require.Equal(t, util.HashSecret(inviteCode), txArg.CodeHash)
+						require.WithinDuration(t, time.Now(), txArg.RedeemedAt, time.Second)
+						return teacher, nil
+					})
+				store.EXPECT().
+					CreateEmailVerification(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.EmailVerification{UserID: teacher.ID, Email: teacher.Email.String}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -126,7 +144,7 @@ func TestCreateUserApi(t *testing.T) {
 				"fullname":     teacher.Fullname.String,
 				"email":        teacher.Email.String,
 				"phone_number": teacher.PhoneNumber.String,
-				"teacher_key":  "5WC7CnJ99KBhyPF",
+				"invite_code":  inviteCode,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -145,11 +163,11 @@ func TestCreateUserApi(t *testing.T) {
 				"fullname":     teacher.Fullname.String,
 				"email":        teacher.Email.String,
 				"phone_number": teacher.PhoneNumber.String,
-				"teacher_key":  "5WC7CnJ99KBhyPF",
+				"invite_code":  inviteCode,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					SignUpWithInviteTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, &pq.Error{Code: "23505"})
 			},
@@ -157,6 +175,66 @@ func TestCreateUserApi(t *testing.T) {
 				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
+		{
+			name: "InvalidInvite",
+			body: gin.H{
+				"username":     teacher.Username.String,
+				"password":     teacherPassword,
+				"fullname":     teacher.Fullname.String,
+				"email":        teacher.Email.String,
+				"phone_number": teacher.PhoneNumber.String,
+				"invite_code":  inviteCode,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SignUpWithInviteTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrInviteInvalid)
+				store.EXPECT().
+					CreateEmailVerification(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "PasswordIsUsername",
+			body: gin.H{
+				"username":     "Janedoe1",
+				"password":     "janedoe1",
+				"fullname":     student.Fullname.String,
+				"email":        student.Email.String,
+				"phone_number": student.PhoneNumber.String,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BreachedPassword",
+			body: gin.H{
+				"username":     student.Username.String,
+				"password":     "Password1",
+				"fullname":     student.Fullname.String,
+				"email":        student.Email.String,
+				"phone_number": student.PhoneNumber.String,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "breach")
+			},
+		},
 		{
 			name: "InvalidName",
 			body: gin.H{
@@ -165,7 +243,7 @@ func TestCreateUserApi(t *testing.T) {
 				"fullname":     teacher.Fullname.String,
 				"email":        teacher.Email.String,
 				"phone_number": teacher.PhoneNumber.String,
-				"teacher_key":  "5WC7CnJ99KBhyPF",
+				"invite_code":  inviteCode,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -188,7 +266,11 @@ func TestCreateUserApi(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
+			breached, err := util.NewBreachedPasswords("../breached_passwords")
+			require.NoError(t, err)
+
 			server := newTestServer(t, store)
+			server.passwordPolicy.Breached = breached
 			recorder := httptest.NewRecorder()
 
 			// Marshal body data to JSON
@@ -205,8 +287,22 @@ func TestCreateUserApi(t *testing.T) {
 	}
 }
 
+// stubLoginNotLocked lets a login through the lockout check
+func stubLoginNotLocked(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetLoginFailure(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(db.LoginFailure{}, sql.ErrNoRows)
+}
+
 func TestLoginUserAPI(t *testing.T) {
 	user, password := randomStudentUser(t)
+	user.EmailVerifiedAt = time.Now()
+
+	unverified, unverifiedPassword := randomStudentUser(t)
+
+	deactivated := user
+	deactivated.DeletedAt = time.Now()
 
 	testCases := []struct {
 		name          string
@@ -221,10 +317,15 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginNotLocked(store)
 				store.EXPECT().
 					GetByUsername(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Eq(loginUsernameKey(user.Username.String))).
+					Times(1).
+					Return(nil)
 				store.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
 					Times(1)
@@ -233,6 +334,59 @@ func TestLoginUserAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "EmailNotVerified",
+			body: gin.H{
+				"username": unverified.Username.String,
+				"password": unverifiedPassword,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginNotLocked(store)
+				store.EXPECT().
+					GetByUsername(gomock.Any(), gomock.Eq(unverified.Username)).
+					Times(1).
+					Return(unverified, nil)
+				store.EXPECT().
+					CreateEmailVerification(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.EmailVerification{Email: unverified.Email.String}, nil)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 1}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				// the same answer as a wrong password
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Deactivated",
+			body: gin.H{
+				"username": user.Username.String,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginNotLocked(store)
+				store.EXPECT().
+					GetByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(deactivated, nil)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 1}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "UserNotFound",
 			body: gin.H{
@@ -240,13 +394,19 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginNotLocked(store)
 				store.EXPECT().
 					GetByUsername(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 1}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errInvalidCredentials.Error())
 			},
 		},
 		{
@@ -256,15 +416,87 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": "incorrect",
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginNotLocked(store)
+				store.EXPECT().
+					GetByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginFailure{FailedCount: 1}, nil)
+				store.EXPECT().
+					LockLogin(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errInvalidCredentials.Error())
+			},
+		},
+		{
+			name: "LockedAfterTooManyFailures",
+			body: gin.H{
+				"username": user.Username.String,
+				"password": "incorrect",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				usernameKey := loginUsernameKey(user.Username.String)
+
+				stubLoginNotLocked(store)
 				store.EXPECT().
 					GetByUsername(gomock.Any(), gomock.Eq(user.Username)).
 					Times(1).
 					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(ctx context.Context, arg db.RecordLoginFailureParams) (db.LoginFailure, error) {
+						if arg.Key == usernameKey {
+							return db.LoginFailure{Key: arg.Key, FailedCount: defaultLoginMaxAttempts}, nil
+						}
+						return db.LoginFailure{Key: arg.Key, FailedCount: 1}, nil
+					})
+				store.EXPECT().
+					LockLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.LockLoginParams) error {
+						require.Equal(t, usernameKey, arg.Key)
+						require.WithinDuration(t, time.Now().Add(defaultLoginLockout), arg.LockedUntil, time.Second)
+						return nil
+					})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "Locked",
+			body: gin.H{
+				"username": user.Username.String,
+				"password": password,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLoginFailure(gomock.Any(), gomock.Eq(loginUsernameKey(user.Username.String))).
+					Times(1).
+					Return(db.LoginFailure{FailedCount: 6, LockedUntil: time.Now().Add(time.Minute)}, nil)
+				store.EXPECT().
+					GetLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginFailure{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetByUsername(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			},
+		},
 		{
 			name: "InternalError",
 			body: gin.H{
@@ -272,6 +504,7 @@ func TestLoginUserAPI(t *testing.T) {
 				"password": password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				stubLoginNotLocked(store)
 				store.EXPECT().
 					GetByUsername(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -311,6 +544,7 @@ func TestLoginUserAPI(t *testing.T) {
 			tc.buildStubs(store)
 
 			server := newTestServer(t, store)
+			server.config.RequireVerifiedEmail = true
 			recorder := httptest.NewRecorder()
 
 			// Marshal body data to JSON
@@ -327,6 +561,70 @@ func TestLoginUserAPI(t *testing.T) {
 	}
 }
 
+func TestGetUserAPI(t *testing.T) {
+	user, _ := randomTeacherUser(t)
+	user.TotpEnabledAt = time.Now()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// the account state is private, anyone can call this route
+				var rsp map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, user.Username.String, rsp["username"])
+				require.NotContains(t, rsp, "totp_enabled")
+				require.NotContains(t, rsp, "is_active")
+				require.NotContains(t, rsp, "deleted_at")
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%d", user.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestUpdateUserInfo(t *testing.T) {
 	// student, password := randomStudentUser(t)
 	teacher, _ := randomTeacherUser(t)
@@ -340,7 +638,7 @@ func TestUpdateUserInfo(t *testing.T) {
 		name          string
 		userId        int64
 		body          gin.H
-		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker)
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -353,9 +651,9 @@ func TestUpdateUserInfo(t *testing.T) {
 				"email":        updateEmail,
 				"phone_number": updatePhone,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.JWTMaker) {
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker,
-					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.IsTeacher,
+					authorizationTypeBearer, teacher.ID, teacher.Username.String, teacher.Role,
 					time.Minute*15)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
@@ -387,6 +685,15 @@ func TestUpdateUserInfo(t *testing.T) {
 				store.EXPECT().UpdateUserInfoTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(updateUser, nil)
+
+				// the new address gets a verification link
+				store.EXPECT().CreateEmailVerification(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateEmailVerificationParams) (db.EmailVerification, error) {
+						require.Equal(t, teacher.ID, arg.UserID)
+						require.Equal(t, updateEmail, arg.Email)
+						return db.EmailVerification{UserID: arg.UserID, Email: arg.Email}, nil
+					})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -422,62 +729,341 @@ func TestUpdateUserInfo(t *testing.T) {
 
 }
 
-func randomStudentUser(t *testing.T) (user db.User, password string) {
-	password = util.RandomString(6)
-	hashedPassword, err := util.HashPassword(password)
-	require.NoError(t, err)
-
-	user = db.User{
-		ID:             util.RandomInt(1, 100),
-		Username:       sql.NullString{String: util.RandomString(6), Valid: true},
-		HashedPassword: hashedPassword,
-		Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
-		Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
-		PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
-	}
-
-	return
-}
-
-func requireBodyMatchStudent(t *testing.T, body *bytes.Buffer, student db.User) {
-	data, err := ioutil.ReadAll(body)
-	require.NoError(t, err)
-
-	var gotUser struct {
-		ID                int64     `json:"id"`
-		Username          string    `json:"username"`
-		Fullname          string    `json:"fullname"`
-		Email             string    `json:"email"`
-		PhoneNumber       string    `json:"phone_number"`
-		IsTeacher         bool      `json:"is_teacher"`
-		PasswordChangedAt time.Time `json:"password_changed_at"`
-		CreatedAt         time.Time `json:"created_at"`
-	}
-	err = json.Unmarshal(data, &gotUser)
-
-	require.NoError(t, err)
-	require.Equal(t, student.Username.String, gotUser.Username)
-	require.Equal(t, student.Fullname.String, gotUser.Fullname)
-	require.Equal(t, student.Email.String, gotUser.Email)
-	require.Equal(t, student.PhoneNumber.String, gotUser.PhoneNumber)
-	require.Equal(t, false, gotUser.IsTeacher)
-
-}
+func TestUpdateUserRoleAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	student, _ := randomStudentUser(t)
+	student.ID = admin.ID + 1
 
-func randomTeacherUser(t *testing.T) (user db.User, password string) {
-	password = util.RandomString(6)
-	hashedPassword, err := util.HashPassword(password)
-	require.NoError(t, err)
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		userID        int64
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			authUser: admin,
+			userID:   student.ID,
+			body:     gin.H{"role": util.RoleTeacher},
+			buildStubs: func(store *mockdb.MockStore) {
+				teacher := student
+				teacher.Role = util.RoleTeacher
 
-	user = db.User{
-		ID:             util.RandomInt(1, 100),
-		Username:       sql.NullString{String: util.RandomString(6), Valid: true},
-		HashedPassword: hashedPassword,
-		Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
-		Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
-		PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
-		IsTeacher:      true,
-	}
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Eq(db.UpdateUserRoleParams{
+					ID:   student.ID,
+					Role: util.RoleTeacher,
+				})).
+					Times(1).
+					Return(teacher, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTeacher(t, recorder.Body, student)
+			},
+		},
+		{
+			name:     "NotAdmin",
+			authUser: student,
+			userID:   student.ID,
+			body:     gin.H{"role": util.RoleAdmin},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "OwnRole",
+			authUser: admin,
+			userID:   admin.ID,
+			body:     gin.H{"role": util.RoleStudent},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidRole",
+			authUser: admin,
+			userID:   student.ID,
+			body:     gin.H{"role": "principal"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			authUser: admin,
+			userID:   student.ID,
+			body:     gin.H{"role": util.RoleGuardian},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserRole(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/users/%d/role", tc.userID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.authUser.ID, tc.authUser.Username.String, tc.authUser.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateUserPasswordAPI(t *testing.T) {
+	user, password := randomStudentUser(t)
+	other, _ := randomStudentUser(t)
+	other.ID = user.ID + 1
+	newPassword := util.RandomString(8)
+
+	oldPassword := util.RandomString(8)
+	oldHash, err := util.HashPassword(oldPassword)
+	require.NoError(t, err)
+
+	history := []db.PasswordHistory{{UserID: user.ID, HashedPassword: oldHash}}
+
+	testCases := []struct {
+		name          string
+		userID        int64
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			userID: user.ID,
+			body:   gin.H{"old_password": password, "new_password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().ListPasswordHistory(gomock.Any(), gomock.Eq(db.ListPasswordHistoryParams{
+					UserID: user.ID,
+					Limit:  2,
+				})).
+					Times(1).
+					Return(history, nil)
+				store.EXPECT().UpdateUserPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserPasswordTxParams) (db.User, error) {
+						require.Equal(t, user.ID, arg.ID)
+						require.NoError(t, util.CheckPassword(newPassword, arg.HashedPassword))
+						require.Equal(t, int32(2), arg.KeepHistory)
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "WrongOldPassword",
+			userID: user.ID,
+			body:   gin.H{"old_password": "wrong", "new_password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().UpdateUserPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "TooShort",
+			userID: user.ID,
+			body:   gin.H{"old_password": password, "new_password": "abc"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().UpdateUserPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "SamePassword",
+			userID: user.ID,
+			body:   gin.H{"old_password": password, "new_password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().ListPasswordHistory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(history, nil)
+				store.EXPECT().UpdateUserPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "RecentPassword",
+			userID: user.ID,
+			body:   gin.H{"old_password": password, "new_password": oldPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().ListPasswordHistory(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(history, nil)
+				store.EXPECT().UpdateUserPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "used recently")
+			},
+		},
+		{
+			name:   "OtherUser",
+			userID: other.ID,
+			body:   gin.H{"old_password": password, "new_password": newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().UpdateUserPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.passwordPolicy.History = 3
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/users/%d/update_password", tc.userID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, user.ID, user.Username.String, user.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomStudentUser(t *testing.T) (user db.User, password string) {
+	password = util.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		ID:             util.RandomInt(1, 100),
+		Username:       sql.NullString{String: util.RandomString(6), Valid: true},
+		HashedPassword: hashedPassword,
+		Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
+		Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
+		PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
+		Role:           util.RoleStudent,
+	}
+
+	return
+}
+
+func requireBodyMatchStudent(t *testing.T, body *bytes.Buffer, student db.User) {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotUser struct {
+		ID                int64     `json:"id"`
+		Username          string    `json:"username"`
+		Fullname          string    `json:"fullname"`
+		Email             string    `json:"email"`
+		PhoneNumber       string    `json:"phone_number"`
+		Role              string    `json:"role"`
+		PasswordChangedAt time.Time `json:"password_changed_at"`
+		CreatedAt         time.Time `json:"created_at"`
+	}
+	err = json.Unmarshal(data, &gotUser)
+
+	require.NoError(t, err)
+	require.Equal(t, student.Username.String, gotUser.Username)
+	require.Equal(t, student.Fullname.String, gotUser.Fullname)
+	require.Equal(t, student.Email.String, gotUser.Email)
+	require.Equal(t, student.PhoneNumber.String, gotUser.PhoneNumber)
+	require.Equal(t, util.RoleStudent, gotUser.Role)
+
+}
+
+func randomTeacherUser(t *testing.T) (user db.User, password string) {
+	password = util.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		ID:             util.RandomInt(1, 100),
+		Username:       sql.NullString{String: util.RandomString(6), Valid: true},
+		HashedPassword: hashedPassword,
+		Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
+		Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
+		PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
+		Role:           util.RoleTeacher,
+	}
 
 	return
 }
@@ -492,7 +1078,7 @@ func requireBodyMatchTeacher(t *testing.T, body *bytes.Buffer, teacher db.User)
 		Fullname          string    `json:"fullname"`
 		Email             string    `json:"email"`
 		PhoneNumber       string    `json:"phone_number"`
-		IsTeacher         bool      `json:"is_teacher"`
+		Role              string    `json:"role"`
 		PasswordChangedAt time.Time `json:"password_changed_at"`
 		CreatedAt         time.Time `json:"created_at"`
 	}
@@ -503,6 +1089,407 @@ func requireBodyMatchTeacher(t *testing.T, body *bytes.Buffer, teacher db.User)
 	require.Equal(t, teacher.Fullname.String, gotUser.Fullname)
 	require.Equal(t, teacher.Email.String, gotUser.Email)
 	require.Equal(t, teacher.PhoneNumber.String, gotUser.PhoneNumber)
-	require.Equal(t, true, gotUser.IsTeacher)
+	require.Equal(t, util.RoleTeacher, gotUser.Role)
+
+}
+
+func TestDeleteUserAPI(t *testing.T) {
+	teacher, _ := randomTeacherUser(t)
+	student, _ := randomStudentUser(t)
+	student.ID = teacher.ID + 1
+	other, _ := randomTeacherUser(t)
+	other.ID = teacher.ID + 2
+
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		userID        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			authUser: teacher,
+			userID:   student.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(student, nil)
+				store.EXPECT().DeactivateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.DeactivateUserParams) (db.User, error) {
+						require.Equal(t, student.ID, arg.ID)
+						require.WithinDuration(t, time.Now(), arg.DeletedAt, time.Second)
+
+						deactivated := student
+						deactivated.DeletedAt = arg.DeletedAt
+						return deactivated, nil
+					})
+				store.EXPECT().DeleteUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyDeactivated",
+			authUser: teacher,
+			userID:   student.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(student, nil)
+				store.EXPECT().DeactivateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherTeacher",
+			authUser: teacher,
+			userID:   other.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(other.ID)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().DeactivateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			authUser: teacher,
+			userID:   student.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().DeactivateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
 
+			url := fmt.Sprintf("/users/%d", tc.userID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.authUser.ID, tc.authUser.Username.String, tc.authUser.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReactivateUserAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	teacher, _ := randomTeacherUser(t)
+	teacher.ID = admin.ID + 1
+	student, _ := randomStudentUser(t)
+	student.ID = admin.ID + 2
+
+	deactivated := student
+	deactivated.DeletedAt = time.Now()
+
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			authUser: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(deactivated, nil)
+				store.EXPECT().ReactivateUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(student, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp privateUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, student.ID, rsp.ID)
+				require.True(t, rsp.IsActive)
+			},
+		},
+		{
+			name:     "NotDeactivated",
+			authUser: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(student, nil)
+				store.EXPECT().ReactivateUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotAdmin",
+			authUser: teacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReactivateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			authUser: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(student.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().ReactivateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/%d/reactivate", student.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.authUser.ID, tc.authUser.Username.String, tc.authUser.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPurgeUserAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	teacher, _ := randomTeacherUser(t)
+	teacher.ID = admin.ID + 1
+
+	deactivated := teacher
+	deactivated.DeletedAt = time.Now()
+
+	savedPath := util.RandomString(10)
+
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server)
+	}{
+		{
+			name:     "OK",
+			authUser: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(teacher.ID)).
+					Times(1).
+					Return(deactivated, nil)
+				store.EXPECT().PurgeUserTx(gomock.Any(), gomock.Eq(teacher.ID)).
+					Times(1).
+					Return([]string{savedPath}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				_, err := server.fileStore.Open(context.Background(), savedPath)
+				require.ErrorIs(t, err, storage.ErrFileNotFound)
+			},
+		},
+		{
+			name:     "StillActive",
+			authUser: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(teacher.ID)).
+					Times(1).
+					Return(teacher, nil)
+				store.EXPECT().PurgeUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+
+				file, err := server.fileStore.Open(context.Background(), savedPath)
+				require.NoError(t, err)
+				file.Close()
+			},
+		},
+		{
+			name:     "NotAdmin",
+			authUser: teacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PurgeUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			authUser: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(teacher.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().PurgeUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, server *Server) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			content := []byte(util.RandomString(32))
+			err := server.fileStore.Save(context.Background(), savedPath, bytes.NewReader(content), int64(len(content)), "text/plain")
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/users/%d/purge", teacher.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.authUser.ID, tc.authUser.Username.String, tc.authUser.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server)
+		})
+	}
+}
+
+func TestListAllUsersAPI(t *testing.T) {
+	admin, _ := randomStudentUser(t)
+	admin.Role = util.RoleAdmin
+	teacher, _ := randomTeacherUser(t)
+	teacher.ID = admin.ID + 1
+
+	deactivated, _ := randomStudentUser(t)
+	deactivated.DeletedAt = time.Now()
+
+	testCases := []struct {
+		name          string
+		authUser      db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			authUser: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAllUsers(gomock.Any(), gomock.Eq(db.ListAllUsersParams{Limit: 5, Offset: 0})).
+					Times(1).
+					Return([]db.User{teacher, deactivated}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []privateUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Len(t, rsp, 2)
+				require.True(t, rsp[0].IsActive)
+				require.False(t, rsp[1].IsActive)
+			},
+		},
+		{
+			name:     "NotAdmin",
+			authUser: teacher,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAllUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/all?page_id=1&page_size=5", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker,
+				authorizationTypeBearer, tc.authUser.ID, tc.authUser.Username.String, tc.authUser.Role,
+				time.Minute*15)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
 }
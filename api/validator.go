@@ -1,13 +1,34 @@
 package api
 
 import (
+	"context"
+	"sync/atomic"
+
 	"github.com/dongocanh96/class_manager_go/util"
 	"github.com/go-playground/validator/v10"
 )
 
+// subjectValidatorCache holds the *subjectCache of the latest server. The
+// binding validator is global and keeps the validation func per parsed struct,
+// so the cache is swapped instead of registering a new func.
+var subjectValidatorCache atomic.Value
+
 var validSubject validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	subjects, ok := subjectValidatorCache.Load().(*subjectCache)
+	if !ok {
+		return false
+	}
+
 	if subject, ok := fieldLevel.Field().Interface().(string); ok {
-		return util.IsSupportedSubject(subject)
+		exists, err := subjects.has(context.Background(), subject)
+		return err == nil && exists
+	}
+	return false
+}
+
+var validRole validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if role, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedRole(role)
 	}
 	return false
 }
@@ -0,0 +1,7 @@
+ALTER TABLE "solutions" DROP CONSTRAINT IF EXISTS "solutions_saved_path_key";
+
+ALTER TABLE "homeworks" DROP CONSTRAINT IF EXISTS "homeworks_saved_path_key";
+
+ALTER TABLE "solutions" ADD CONSTRAINT "solutions_file_name_key" UNIQUE ("file_name");
+
+ALTER TABLE "homeworks" ADD CONSTRAINT "homeworks_file_name_key" UNIQUE ("file_name");
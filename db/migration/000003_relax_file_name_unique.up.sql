@@ -0,0 +1,7 @@
+ALTER TABLE "homeworks" DROP CONSTRAINT IF EXISTS "homeworks_file_name_key";
+
+ALTER TABLE "solutions" DROP CONSTRAINT IF EXISTS "solutions_file_name_key";
+
+ALTER TABLE "homeworks" ADD CONSTRAINT "homeworks_saved_path_key" UNIQUE ("saved_path");
+
+ALTER TABLE "solutions" ADD CONSTRAINT "solutions_saved_path_key" UNIQUE ("saved_path");
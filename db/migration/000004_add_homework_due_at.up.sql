@@ -0,0 +1,3 @@
+ALTER TABLE "homeworks" ADD COLUMN "due_at" timestamptz;
+
+CREATE INDEX ON "homeworks" ("due_at");
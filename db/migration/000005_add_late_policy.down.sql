@@ -0,0 +1,8 @@
+ALTER TABLE "solutions" DROP COLUMN IF EXISTS "late_penalty";
+ALTER TABLE "solutions" DROP COLUMN IF EXISTS "late_seconds";
+ALTER TABLE "solutions" DROP COLUMN IF EXISTS "is_late";
+
+ALTER TABLE "homeworks" DROP COLUMN IF EXISTS "late_cutoff_at";
+ALTER TABLE "homeworks" DROP COLUMN IF EXISTS "late_penalty_per_day";
+ALTER TABLE "homeworks" DROP COLUMN IF EXISTS "late_grace_minutes";
+ALTER TABLE "homeworks" DROP COLUMN IF EXISTS "allow_late";
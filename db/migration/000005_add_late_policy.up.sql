@@ -0,0 +1,11 @@
+ALTER TABLE "homeworks" ADD COLUMN "allow_late" boolean NOT NULL DEFAULT false;
+ALTER TABLE "homeworks" ADD COLUMN "late_grace_minutes" integer NOT NULL DEFAULT 0;
+ALTER TABLE "homeworks" ADD COLUMN "late_penalty_per_day" integer NOT NULL DEFAULT 0;
+ALTER TABLE "homeworks" ADD COLUMN "late_cutoff_at" timestamptz;
+
+ALTER TABLE "homeworks" ADD CONSTRAINT "homeworks_late_grace_minutes_check" CHECK ("late_grace_minutes" >= 0);
+ALTER TABLE "homeworks" ADD CONSTRAINT "homeworks_late_penalty_per_day_check" CHECK ("late_penalty_per_day" BETWEEN 0 AND 100);
+
+ALTER TABLE "solutions" ADD COLUMN "is_late" boolean NOT NULL DEFAULT false;
+ALTER TABLE "solutions" ADD COLUMN "late_seconds" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "solutions" ADD COLUMN "late_penalty" integer NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "grades";
+
+ALTER TABLE "homeworks" DROP COLUMN IF EXISTS "max_score";
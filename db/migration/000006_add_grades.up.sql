@@ -0,0 +1,24 @@
+ALTER TABLE "homeworks" ADD COLUMN "max_score" double precision NOT NULL DEFAULT 100;
+ALTER TABLE "homeworks" ADD CONSTRAINT "homeworks_max_score_check" CHECK ("max_score" > 0);
+
+CREATE TABLE "grades" (
+  "id" bigserial PRIMARY KEY,
+  "solution_id" bigint UNIQUE NOT NULL,
+  "grader_id" bigint NOT NULL,
+  "score" double precision NOT NULL,
+  "feedback" varchar(5000) NOT NULL DEFAULT '',
+  "feedback_file_name" varchar NOT NULL DEFAULT '',
+  "feedback_saved_path" varchar NOT NULL DEFAULT '',
+  "is_released" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "released_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z'
+);
+
+CREATE INDEX ON "grades" ("grader_id");
+
+ALTER TABLE "grades" ADD CONSTRAINT "grades_score_check" CHECK ("score" >= 0);
+
+ALTER TABLE "grades" ADD FOREIGN KEY ("solution_id") REFERENCES "solutions" ("id");
+
+ALTER TABLE "grades" ADD FOREIGN KEY ("grader_id") REFERENCES "users" ("id");
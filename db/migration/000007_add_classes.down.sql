@@ -0,0 +1,4 @@
+ALTER TABLE "homeworks" DROP COLUMN IF EXISTS "class_id";
+
+DROP TABLE IF EXISTS "class_members";
+DROP TABLE IF EXISTS "classes";
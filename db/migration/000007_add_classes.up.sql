@@ -0,0 +1,30 @@
+CREATE TABLE "classes" (
+  "id" bigserial PRIMARY KEY,
+  "teacher_id" bigint NOT NULL,
+  "name" varchar(256) NOT NULL,
+  "join_code" varchar UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "class_members" (
+  "class_id" bigint NOT NULL,
+  "user_id" bigint NOT NULL,
+  "joined_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("class_id", "user_id")
+);
+
+ALTER TABLE "homeworks" ADD COLUMN "class_id" bigint;
+
+CREATE INDEX ON "classes" ("teacher_id");
+
+CREATE INDEX ON "class_members" ("user_id");
+
+CREATE INDEX ON "homeworks" ("class_id");
+
+ALTER TABLE "classes" ADD FOREIGN KEY ("teacher_id") REFERENCES "users" ("id");
+
+ALTER TABLE "class_members" ADD FOREIGN KEY ("class_id") REFERENCES "classes" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "class_members" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "homeworks" ADD FOREIGN KEY ("class_id") REFERENCES "classes" ("id");
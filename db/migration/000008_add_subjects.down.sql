@@ -0,0 +1,3 @@
+ALTER TABLE "homeworks" DROP CONSTRAINT IF EXISTS "homeworks_subject_fkey";
+
+DROP TABLE IF EXISTS "subjects";
@@ -0,0 +1,20 @@
+CREATE TABLE "subjects" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar(256) UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+INSERT INTO "subjects" ("name") VALUES
+  ('Math'),
+  ('Biology'),
+  ('Literature'),
+  ('Science'),
+  ('Physics'),
+  ('Chemical');
+
+-- any subject already used by a homework must exist before adding the foreign key
+INSERT INTO "subjects" ("name")
+SELECT DISTINCT "subject" FROM "homeworks"
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE "homeworks" ADD FOREIGN KEY ("subject") REFERENCES "subjects" ("name") ON UPDATE CASCADE;
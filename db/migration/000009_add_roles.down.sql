@@ -0,0 +1,6 @@
+ALTER TABLE "users" ADD COLUMN "is_teacher" boolean NOT NULL DEFAULT false;
+
+-- there is no admin without roles, admins keep the rights of a teacher
+UPDATE "users" SET "is_teacher" = true WHERE "role" IN ('teacher', 'admin');
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
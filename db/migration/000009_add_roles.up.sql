@@ -0,0 +1,8 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'student'
+  CHECK ("role" IN ('admin', 'teacher', 'student', 'guardian'));
+
+UPDATE "users" SET "role" = 'teacher' WHERE "is_teacher";
+
+ALTER TABLE "users" DROP COLUMN "is_teacher";
+
+CREATE INDEX ON "users" ("role");
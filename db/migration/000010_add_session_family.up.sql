@@ -0,0 +1,11 @@
+-- every refresh token issued by rotation gets its own session row, rows of
+-- the same login share the family id
+ALTER TABLE "sessions" ADD COLUMN "family_id" uuid;
+
+UPDATE "sessions" SET "family_id" = "id";
+
+ALTER TABLE "sessions" ALTER COLUMN "family_id" SET NOT NULL;
+
+ALTER TABLE "sessions" ADD COLUMN "rotated_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z';
+
+CREATE INDEX ON "sessions" ("family_id");
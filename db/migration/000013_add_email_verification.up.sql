@@ -0,0 +1,16 @@
+ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z';
+
+-- accounts created before verification existed are trusted as they are
+UPDATE "users" SET "email_verified_at" = now() WHERE "email" IS NOT NULL;
+
+CREATE TABLE "email_verifications" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "email" varchar NOT NULL,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "email_verifications" ("user_id");
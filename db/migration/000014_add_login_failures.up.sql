@@ -0,0 +1,8 @@
+-- failed logins are counted per username and per client ip, the key is
+-- prefixed with what it counts, e.g. "username:alice" or "ip:10.0.0.1"
+CREATE TABLE "login_failures" (
+  "key" varchar PRIMARY KEY,
+  "failed_count" integer NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz NOT NULL,
+  "locked_until" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z'
+);
@@ -0,0 +1,29 @@
+-- a secret with a zero totp_enabled_at is an enrollment that is not
+-- confirmed yet. totp_last_step is the last time step a code was accepted
+-- for, so a code can not be used twice.
+ALTER TABLE "users" ADD COLUMN "totp_secret" varchar NOT NULL DEFAULT '';
+ALTER TABLE "users" ADD COLUMN "totp_enabled_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z';
+ALTER TABLE "users" ADD COLUMN "totp_last_step" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "recovery_codes" ("user_id");
+
+-- a challenge is handed out after the password was checked and exchanged for
+-- tokens together with a code
+CREATE TABLE "mfa_challenges" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "mfa_challenges" ("user_id");
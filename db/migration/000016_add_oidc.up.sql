@@ -0,0 +1,24 @@
+-- accounts of an OpenID Connect provider linked to users, a provider
+-- identifies an account by its issuer and subject
+CREATE TABLE "user_identities" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "issuer" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "email" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "user_identities" ("issuer", "subject");
+CREATE INDEX ON "user_identities" ("user_id");
+
+-- a login started at the provider, only the sha256 of the state is stored
+CREATE TABLE "oidc_logins" (
+  "id" bigserial PRIMARY KEY,
+  "state_hash" varchar UNIQUE NOT NULL,
+  "nonce" varchar NOT NULL,
+  "code_verifier" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
@@ -0,0 +1,27 @@
+-- codes admins and teachers hand out instead of a shared sign up key, only
+-- the sha256 of a code is stored. Teacher invites make the new account a
+-- teacher, student invites enroll the user in the class.
+CREATE TABLE "invites" (
+  "id" bigserial PRIMARY KEY,
+  "code_hash" varchar UNIQUE NOT NULL,
+  "role" varchar NOT NULL CHECK ("role" IN ('teacher', 'student')),
+  "class_id" bigint REFERENCES "classes" ("id") ON DELETE CASCADE,
+  "created_by" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "max_uses" int NOT NULL CHECK ("max_uses" > 0),
+  "uses" int NOT NULL DEFAULT 0,
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK (("role" = 'student') = ("class_id" IS NOT NULL))
+);
+
+CREATE INDEX ON "invites" ("created_by");
+
+CREATE TABLE "invite_redemptions" (
+  "invite_id" bigint NOT NULL REFERENCES "invites" ("id") ON DELETE CASCADE,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "redeemed_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("invite_id", "user_id")
+);
+
+CREATE INDEX ON "invite_redemptions" ("user_id");
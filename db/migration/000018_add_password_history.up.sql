@@ -0,0 +1,10 @@
+-- earlier password hashes of users, so a new password can not be one of the
+-- last ones. Only as many as the password policy looks at are kept.
+CREATE TABLE "password_histories" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "hashed_password" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "password_histories" ("user_id");
@@ -0,0 +1,28 @@
+ALTER TABLE "class_members" DROP CONSTRAINT IF EXISTS "class_members_user_id_fkey";
+ALTER TABLE "class_members" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "classes" DROP CONSTRAINT IF EXISTS "classes_teacher_id_fkey";
+ALTER TABLE "classes" ADD FOREIGN KEY ("teacher_id") REFERENCES "users" ("id");
+
+ALTER TABLE "grades" DROP CONSTRAINT IF EXISTS "grades_grader_id_fkey";
+ALTER TABLE "grades" ADD FOREIGN KEY ("grader_id") REFERENCES "users" ("id");
+
+ALTER TABLE "grades" DROP CONSTRAINT IF EXISTS "grades_solution_id_fkey";
+ALTER TABLE "grades" ADD FOREIGN KEY ("solution_id") REFERENCES "solutions" ("id");
+
+ALTER TABLE "messages" DROP CONSTRAINT IF EXISTS "messages_to_user_id_fkey";
+ALTER TABLE "messages" ADD FOREIGN KEY ("to_user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "messages" DROP CONSTRAINT IF EXISTS "messages_from_user_id_fkey";
+ALTER TABLE "messages" ADD FOREIGN KEY ("from_user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "solutions" DROP CONSTRAINT IF EXISTS "solutions_user_id_fkey";
+ALTER TABLE "solutions" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "solutions" DROP CONSTRAINT IF EXISTS "solutions_problem_id_fkey";
+ALTER TABLE "solutions" ADD FOREIGN KEY ("problem_id") REFERENCES "homeworks" ("id");
+
+ALTER TABLE "homeworks" DROP CONSTRAINT IF EXISTS "homeworks_teacher_id_fkey";
+ALTER TABLE "homeworks" ADD FOREIGN KEY ("teacher_id") REFERENCES "users" ("id");
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "deleted_at";
@@ -0,0 +1,32 @@
+-- deactivated users keep their records but can not log in, a purge deletes
+-- them together with everything they own
+ALTER TABLE "users" ADD COLUMN "deleted_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z';
+
+CREATE INDEX ON "users" ("deleted_at");
+
+ALTER TABLE "homeworks" DROP CONSTRAINT IF EXISTS "homeworks_teacher_id_fkey";
+ALTER TABLE "homeworks" ADD FOREIGN KEY ("teacher_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "solutions" DROP CONSTRAINT IF EXISTS "solutions_problem_id_fkey";
+ALTER TABLE "solutions" ADD FOREIGN KEY ("problem_id") REFERENCES "homeworks" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "solutions" DROP CONSTRAINT IF EXISTS "solutions_user_id_fkey";
+ALTER TABLE "solutions" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "messages" DROP CONSTRAINT IF EXISTS "messages_from_user_id_fkey";
+ALTER TABLE "messages" ADD FOREIGN KEY ("from_user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "messages" DROP CONSTRAINT IF EXISTS "messages_to_user_id_fkey";
+ALTER TABLE "messages" ADD FOREIGN KEY ("to_user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "grades" DROP CONSTRAINT IF EXISTS "grades_solution_id_fkey";
+ALTER TABLE "grades" ADD FOREIGN KEY ("solution_id") REFERENCES "solutions" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "grades" DROP CONSTRAINT IF EXISTS "grades_grader_id_fkey";
+ALTER TABLE "grades" ADD FOREIGN KEY ("grader_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "classes" DROP CONSTRAINT IF EXISTS "classes_teacher_id_fkey";
+ALTER TABLE "classes" ADD FOREIGN KEY ("teacher_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "class_members" DROP CONSTRAINT IF EXISTS "class_members_user_id_fkey";
+ALTER TABLE "class_members" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,6 @@
+-- the asset directory is not known here, this puts back the default of app.env
+UPDATE "homeworks" SET "saved_path" = './asset/' || "saved_path"
+WHERE "saved_path" !~ '[/\\]';
+
+UPDATE "solutions" SET "saved_path" = './asset/' || "saved_path"
+WHERE "saved_path" !~ '[/\\]';
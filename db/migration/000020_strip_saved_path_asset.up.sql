@@ -0,0 +1,8 @@
+-- saved_path used to be the file path "<ASSET>/<file name>", files are now
+-- addressed by their key in the file store, which for those files is the
+-- file name inside the asset directory
+UPDATE "homeworks" SET "saved_path" = regexp_replace("saved_path", '^.*[/\\]', '')
+WHERE "saved_path" ~ '[/\\]';
+
+UPDATE "solutions" SET "saved_path" = regexp_replace("saved_path", '^.*[/\\]', '')
+WHERE "saved_path" ~ '[/\\]';
@@ -8,6 +8,7 @@ import (
 	context "context"
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	db "github.com/dongocanh96/class_manager_go/db/sqlc"
 	gomock "github.com/golang/mock/gomock"
@@ -37,6 +38,49 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AddClassMember mocks base method.
+func (m *MockStore) AddClassMember(arg0 context.Context, arg1 db.AddClassMemberParams) (db.ClassMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddClassMember", arg0, arg1)
+	ret0, _ := ret[0].(db.ClassMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddClassMember indicates an expected call of AddClassMember.
+func (mr *MockStoreMockRecorder) AddClassMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddClassMember", reflect.TypeOf((*MockStore)(nil).AddClassMember), arg0, arg1)
+}
+
+// AddPasswordHistory mocks base method.
+func (m *MockStore) AddPasswordHistory(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPasswordHistory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPasswordHistory indicates an expected call of AddPasswordHistory.
+func (mr *MockStoreMockRecorder) AddPasswordHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPasswordHistory", reflect.TypeOf((*MockStore)(nil).AddPasswordHistory), arg0, arg1)
+}
+
+// BlockSessionFamily mocks base method.
+func (m *MockStore) BlockSessionFamily(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSessionFamily", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSessionFamily indicates an expected call of BlockSessionFamily.
+func (mr *MockStoreMockRecorder) BlockSessionFamily(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSessionFamily", reflect.TypeOf((*MockStore)(nil).BlockSessionFamily), arg0, arg1)
+}
+
 // CloseHomework mocks base method.
 func (m *MockStore) CloseHomework(arg0 context.Context, arg1 db.CloseHomeworkParams) (db.Homework, error) {
 	m.ctrl.T.Helper()
@@ -52,6 +96,66 @@ func (mr *MockStoreMockRecorder) CloseHomework(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseHomework", reflect.TypeOf((*MockStore)(nil).CloseHomework), arg0, arg1)
 }
 
+// CloseOverdueHomeworks mocks base method.
+func (m *MockStore) CloseOverdueHomeworks(arg0 context.Context, arg1 time.Time) ([]db.Homework, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseOverdueHomeworks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Homework)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseOverdueHomeworks indicates an expected call of CloseOverdueHomeworks.
+func (mr *MockStoreMockRecorder) CloseOverdueHomeworks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseOverdueHomeworks", reflect.TypeOf((*MockStore)(nil).CloseOverdueHomeworks), arg0, arg1)
+}
+
+// CreateClass mocks base method.
+func (m *MockStore) CreateClass(arg0 context.Context, arg1 db.CreateClassParams) (db.Class, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClass", arg0, arg1)
+	ret0, _ := ret[0].(db.Class)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateClass indicates an expected call of CreateClass.
+func (mr *MockStoreMockRecorder) CreateClass(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClass", reflect.TypeOf((*MockStore)(nil).CreateClass), arg0, arg1)
+}
+
+// CreateEmailVerification mocks base method.
+func (m *MockStore) CreateEmailVerification(arg0 context.Context, arg1 db.CreateEmailVerificationParams) (db.EmailVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailVerification", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailVerification indicates an expected call of CreateEmailVerification.
+func (mr *MockStoreMockRecorder) CreateEmailVerification(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailVerification", reflect.TypeOf((*MockStore)(nil).CreateEmailVerification), arg0, arg1)
+}
+
+// CreateGrade mocks base method.
+func (m *MockStore) CreateGrade(arg0 context.Context, arg1 db.CreateGradeParams) (db.Grade, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGrade", arg0, arg1)
+	ret0, _ := ret[0].(db.Grade)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGrade indicates an expected call of CreateGrade.
+func (mr *MockStoreMockRecorder) CreateGrade(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGrade", reflect.TypeOf((*MockStore)(nil).CreateGrade), arg0, arg1)
+}
+
 // CreateHomework mocks base method.
 func (m *MockStore) CreateHomework(arg0 context.Context, arg1 db.CreateHomeworkParams) (db.Homework, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +171,51 @@ func (mr *MockStoreMockRecorder) CreateHomework(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHomework", reflect.TypeOf((*MockStore)(nil).CreateHomework), arg0, arg1)
 }
 
+// CreateInvite mocks base method.
+func (m *MockStore) CreateInvite(arg0 context.Context, arg1 db.CreateInviteParams) (db.Invite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvite", arg0, arg1)
+	ret0, _ := ret[0].(db.Invite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvite indicates an expected call of CreateInvite.
+func (mr *MockStoreMockRecorder) CreateInvite(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvite", reflect.TypeOf((*MockStore)(nil).CreateInvite), arg0, arg1)
+}
+
+// CreateInviteRedemption mocks base method.
+func (m *MockStore) CreateInviteRedemption(arg0 context.Context, arg1 db.CreateInviteRedemptionParams) (db.InviteRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInviteRedemption", arg0, arg1)
+	ret0, _ := ret[0].(db.InviteRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInviteRedemption indicates an expected call of CreateInviteRedemption.
+func (mr *MockStoreMockRecorder) CreateInviteRedemption(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInviteRedemption", reflect.TypeOf((*MockStore)(nil).CreateInviteRedemption), arg0, arg1)
+}
+
+// CreateMFAChallenge mocks base method.
+func (m *MockStore) CreateMFAChallenge(arg0 context.Context, arg1 db.CreateMFAChallengeParams) (db.MfaChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMFAChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.MfaChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMFAChallenge indicates an expected call of CreateMFAChallenge.
+func (mr *MockStoreMockRecorder) CreateMFAChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMFAChallenge", reflect.TypeOf((*MockStore)(nil).CreateMFAChallenge), arg0, arg1)
+}
+
 // CreateMessage mocks base method.
 func (m *MockStore) CreateMessage(arg0 context.Context, arg1 db.CreateMessageParams) (db.Message, error) {
 	m.ctrl.T.Helper()
@@ -82,6 +231,51 @@ func (mr *MockStoreMockRecorder) CreateMessage(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessage", reflect.TypeOf((*MockStore)(nil).CreateMessage), arg0, arg1)
 }
 
+// CreateOIDCLogin mocks base method.
+func (m *MockStore) CreateOIDCLogin(arg0 context.Context, arg1 db.CreateOIDCLoginParams) (db.OidcLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOIDCLogin", arg0, arg1)
+	ret0, _ := ret[0].(db.OidcLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOIDCLogin indicates an expected call of CreateOIDCLogin.
+func (mr *MockStoreMockRecorder) CreateOIDCLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOIDCLogin", reflect.TypeOf((*MockStore)(nil).CreateOIDCLogin), arg0, arg1)
+}
+
+// CreatePasswordReset mocks base method.
+func (m *MockStore) CreatePasswordReset(arg0 context.Context, arg1 db.CreatePasswordResetParams) (db.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordReset indicates an expected call of CreatePasswordReset.
+func (mr *MockStoreMockRecorder) CreatePasswordReset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordReset", reflect.TypeOf((*MockStore)(nil).CreatePasswordReset), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
 // CreateSession mocks base method.
 func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
@@ -112,6 +306,21 @@ func (mr *MockStoreMockRecorder) CreateSolution(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSolution", reflect.TypeOf((*MockStore)(nil).CreateSolution), arg0, arg1)
 }
 
+// CreateSubject mocks base method.
+func (m *MockStore) CreateSubject(arg0 context.Context, arg1 string) (db.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubject", arg0, arg1)
+	ret0, _ := ret[0].(db.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubject indicates an expected call of CreateSubject.
+func (mr *MockStoreMockRecorder) CreateSubject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubject", reflect.TypeOf((*MockStore)(nil).CreateSubject), arg0, arg1)
+}
+
 // CreateUser mocks base method.
 func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -127,6 +336,93 @@ func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
 }
 
+// CreateUserIdentity mocks base method.
+func (m *MockStore) CreateUserIdentity(arg0 context.Context, arg1 db.CreateUserIdentityParams) (db.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(db.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserIdentity indicates an expected call of CreateUserIdentity.
+func (mr *MockStoreMockRecorder) CreateUserIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserIdentity", reflect.TypeOf((*MockStore)(nil).CreateUserIdentity), arg0, arg1)
+}
+
+// DeactivateUser mocks base method.
+func (m *MockStore) DeactivateUser(arg0 context.Context, arg1 db.DeactivateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockStoreMockRecorder) DeactivateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*MockStore)(nil).DeactivateUser), arg0, arg1)
+}
+
+// DeactivateUserTx mocks base method.
+func (m *MockStore) DeactivateUserTx(arg0 context.Context, arg1 db.DeactivateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateUserTx indicates an expected call of DeactivateUserTx.
+func (mr *MockStoreMockRecorder) DeactivateUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUserTx", reflect.TypeOf((*MockStore)(nil).DeactivateUserTx), arg0, arg1)
+}
+
+// DeleteClass mocks base method.
+func (m *MockStore) DeleteClass(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteClass", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteClass indicates an expected call of DeleteClass.
+func (mr *MockStoreMockRecorder) DeleteClass(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteClass", reflect.TypeOf((*MockStore)(nil).DeleteClass), arg0, arg1)
+}
+
+// DeleteExpiredLoginFailures mocks base method.
+func (m *MockStore) DeleteExpiredLoginFailures(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredLoginFailures", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredLoginFailures indicates an expected call of DeleteExpiredLoginFailures.
+func (mr *MockStoreMockRecorder) DeleteExpiredLoginFailures(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredLoginFailures", reflect.TypeOf((*MockStore)(nil).DeleteExpiredLoginFailures), arg0, arg1)
+}
+
+// DeleteGrade mocks base method.
+func (m *MockStore) DeleteGrade(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGrade", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGrade indicates an expected call of DeleteGrade.
+func (mr *MockStoreMockRecorder) DeleteGrade(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGrade", reflect.TypeOf((*MockStore)(nil).DeleteGrade), arg0, arg1)
+}
+
 // DeleteHomework mocks base method.
 func (m *MockStore) DeleteHomework(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -141,6 +437,20 @@ func (mr *MockStoreMockRecorder) DeleteHomework(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHomework", reflect.TypeOf((*MockStore)(nil).DeleteHomework), arg0, arg1)
 }
 
+// DeleteLoginFailure mocks base method.
+func (m *MockStore) DeleteLoginFailure(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginFailure indicates an expected call of DeleteLoginFailure.
+func (mr *MockStoreMockRecorder) DeleteLoginFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginFailure", reflect.TypeOf((*MockStore)(nil).DeleteLoginFailure), arg0, arg1)
+}
+
 // DeleteMessage mocks base method.
 func (m *MockStore) DeleteMessage(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -155,6 +465,34 @@ func (mr *MockStoreMockRecorder) DeleteMessage(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockStore)(nil).DeleteMessage), arg0, arg1)
 }
 
+// DeletePasswordResetsByUser mocks base method.
+func (m *MockStore) DeletePasswordResetsByUser(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasswordResetsByUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasswordResetsByUser indicates an expected call of DeletePasswordResetsByUser.
+func (mr *MockStoreMockRecorder) DeletePasswordResetsByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResetsByUser", reflect.TypeOf((*MockStore)(nil).DeletePasswordResetsByUser), arg0, arg1)
+}
+
+// DeleteRecoveryCodesByUser mocks base method.
+func (m *MockStore) DeleteRecoveryCodesByUser(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodesByUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodesByUser indicates an expected call of DeleteRecoveryCodesByUser.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodesByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodesByUser", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodesByUser), arg0, arg1)
+}
+
 // DeleteSession mocks base method.
 func (m *MockStore) DeleteSession(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -169,6 +507,34 @@ func (mr *MockStoreMockRecorder) DeleteSession(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockStore)(nil).DeleteSession), arg0, arg1)
 }
 
+// DeleteSessionFamily mocks base method.
+func (m *MockStore) DeleteSessionFamily(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessionFamily", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSessionFamily indicates an expected call of DeleteSessionFamily.
+func (mr *MockStoreMockRecorder) DeleteSessionFamily(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessionFamily", reflect.TypeOf((*MockStore)(nil).DeleteSessionFamily), arg0, arg1)
+}
+
+// DeleteSessionsByUsername mocks base method.
+func (m *MockStore) DeleteSessionsByUsername(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessionsByUsername", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSessionsByUsername indicates an expected call of DeleteSessionsByUsername.
+func (mr *MockStoreMockRecorder) DeleteSessionsByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessionsByUsername", reflect.TypeOf((*MockStore)(nil).DeleteSessionsByUsername), arg0, arg1)
+}
+
 // DeleteSolution mocks base method.
 func (m *MockStore) DeleteSolution(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -183,288 +549,1138 @@ func (mr *MockStoreMockRecorder) DeleteSolution(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSolution", reflect.TypeOf((*MockStore)(nil).DeleteSolution), arg0, arg1)
 }
 
+// DeleteSubject mocks base method.
+func (m *MockStore) DeleteSubject(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubject", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubject indicates an expected call of DeleteSubject.
+func (mr *MockStoreMockRecorder) DeleteSubject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubject", reflect.TypeOf((*MockStore)(nil).DeleteSubject), arg0, arg1)
+}
+
 // DeleteUser mocks base method.
 func (m *MockStore) DeleteUser(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+}
+
+// DisableTOTPTx mocks base method.
+func (m *MockStore) DisableTOTPTx(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableTOTPTx indicates an expected call of DisableTOTPTx.
+func (mr *MockStoreMockRecorder) DisableTOTPTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTPTx", reflect.TypeOf((*MockStore)(nil).DisableTOTPTx), arg0, arg1)
+}
+
+// DisableUserTOTP mocks base method.
+func (m *MockStore) DisableUserTOTP(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableUserTOTP indicates an expected call of DisableUserTOTP.
+func (mr *MockStoreMockRecorder) DisableUserTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUserTOTP", reflect.TypeOf((*MockStore)(nil).DisableUserTOTP), arg0, arg1)
+}
+
+// EnableTOTPTx mocks base method.
+func (m *MockStore) EnableTOTPTx(arg0 context.Context, arg1 db.EnableTOTPTxParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTPTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableTOTPTx indicates an expected call of EnableTOTPTx.
+func (mr *MockStoreMockRecorder) EnableTOTPTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTPTx", reflect.TypeOf((*MockStore)(nil).EnableTOTPTx), arg0, arg1)
+}
+
+// EnableUserTOTP mocks base method.
+func (m *MockStore) EnableUserTOTP(arg0 context.Context, arg1 db.EnableUserTOTPParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableUserTOTP indicates an expected call of EnableUserTOTP.
+func (mr *MockStoreMockRecorder) EnableUserTOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTOTP", reflect.TypeOf((*MockStore)(nil).EnableUserTOTP), arg0, arg1)
+}
+
+// GetByUsername mocks base method.
+func (m *MockStore) GetByUsername(arg0 context.Context, arg1 sql.NullString) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUsername", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUsername indicates an expected call of GetByUsername.
+func (mr *MockStoreMockRecorder) GetByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUsername", reflect.TypeOf((*MockStore)(nil).GetByUsername), arg0, arg1)
+}
+
+// GetClass mocks base method.
+func (m *MockStore) GetClass(arg0 context.Context, arg1 int64) (db.Class, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClass", arg0, arg1)
+	ret0, _ := ret[0].(db.Class)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClass indicates an expected call of GetClass.
+func (mr *MockStoreMockRecorder) GetClass(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClass", reflect.TypeOf((*MockStore)(nil).GetClass), arg0, arg1)
+}
+
+// GetClassByJoinCode mocks base method.
+func (m *MockStore) GetClassByJoinCode(arg0 context.Context, arg1 string) (db.Class, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClassByJoinCode", arg0, arg1)
+	ret0, _ := ret[0].(db.Class)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClassByJoinCode indicates an expected call of GetClassByJoinCode.
+func (mr *MockStoreMockRecorder) GetClassByJoinCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClassByJoinCode", reflect.TypeOf((*MockStore)(nil).GetClassByJoinCode), arg0, arg1)
+}
+
+// GetClassMember mocks base method.
+func (m *MockStore) GetClassMember(arg0 context.Context, arg1 db.GetClassMemberParams) (db.ClassMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClassMember", arg0, arg1)
+	ret0, _ := ret[0].(db.ClassMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClassMember indicates an expected call of GetClassMember.
+func (mr *MockStoreMockRecorder) GetClassMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClassMember", reflect.TypeOf((*MockStore)(nil).GetClassMember), arg0, arg1)
+}
+
+// GetGradeBySolution mocks base method.
+func (m *MockStore) GetGradeBySolution(arg0 context.Context, arg1 int64) (db.Grade, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGradeBySolution", arg0, arg1)
+	ret0, _ := ret[0].(db.Grade)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGradeBySolution indicates an expected call of GetGradeBySolution.
+func (mr *MockStoreMockRecorder) GetGradeBySolution(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGradeBySolution", reflect.TypeOf((*MockStore)(nil).GetGradeBySolution), arg0, arg1)
+}
+
+// GetHomework mocks base method.
+func (m *MockStore) GetHomework(arg0 context.Context, arg1 int64) (db.Homework, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomework", arg0, arg1)
+	ret0, _ := ret[0].(db.Homework)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomework indicates an expected call of GetHomework.
+func (mr *MockStoreMockRecorder) GetHomework(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomework", reflect.TypeOf((*MockStore)(nil).GetHomework), arg0, arg1)
+}
+
+// GetInvite mocks base method.
+func (m *MockStore) GetInvite(arg0 context.Context, arg1 int64) (db.Invite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInvite", arg0, arg1)
+	ret0, _ := ret[0].(db.Invite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInvite indicates an expected call of GetInvite.
+func (mr *MockStoreMockRecorder) GetInvite(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInvite", reflect.TypeOf((*MockStore)(nil).GetInvite), arg0, arg1)
+}
+
+// GetLoginFailure mocks base method.
+func (m *MockStore) GetLoginFailure(arg0 context.Context, arg1 string) (db.LoginFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginFailure indicates an expected call of GetLoginFailure.
+func (mr *MockStoreMockRecorder) GetLoginFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginFailure", reflect.TypeOf((*MockStore)(nil).GetLoginFailure), arg0, arg1)
+}
+
+// GetMFAChallenge mocks base method.
+func (m *MockStore) GetMFAChallenge(arg0 context.Context, arg1 db.GetMFAChallengeParams) (db.MfaChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMFAChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.MfaChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMFAChallenge indicates an expected call of GetMFAChallenge.
+func (mr *MockStoreMockRecorder) GetMFAChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMFAChallenge", reflect.TypeOf((*MockStore)(nil).GetMFAChallenge), arg0, arg1)
+}
+
+// GetMessage mocks base method.
+func (m *MockStore) GetMessage(arg0 context.Context, arg1 int64) (db.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessage", arg0, arg1)
+	ret0, _ := ret[0].(db.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessage indicates an expected call of GetMessage.
+func (mr *MockStoreMockRecorder) GetMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessage", reflect.TypeOf((*MockStore)(nil).GetMessage), arg0, arg1)
+}
+
+// GetPasswordReset mocks base method.
+func (m *MockStore) GetPasswordReset(arg0 context.Context, arg1 db.GetPasswordResetParams) (db.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordReset indicates an expected call of GetPasswordReset.
+func (mr *MockStoreMockRecorder) GetPasswordReset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordReset", reflect.TypeOf((*MockStore)(nil).GetPasswordReset), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetSessionAuth mocks base method.
+func (m *MockStore) GetSessionAuth(arg0 context.Context, arg1 uuid.UUID) (db.GetSessionAuthRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionAuth", arg0, arg1)
+	ret0, _ := ret[0].(db.GetSessionAuthRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionAuth indicates an expected call of GetSessionAuth.
+func (mr *MockStoreMockRecorder) GetSessionAuth(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionAuth", reflect.TypeOf((*MockStore)(nil).GetSessionAuth), arg0, arg1)
+}
+
+// GetSolutionByID mocks base method.
+func (m *MockStore) GetSolutionByID(arg0 context.Context, arg1 int64) (db.Solution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolutionByID", arg0, arg1)
+	ret0, _ := ret[0].(db.Solution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSolutionByID indicates an expected call of GetSolutionByID.
+func (mr *MockStoreMockRecorder) GetSolutionByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolutionByID", reflect.TypeOf((*MockStore)(nil).GetSolutionByID), arg0, arg1)
+}
+
+// GetSolutionByProblemAndUser mocks base method.
+func (m *MockStore) GetSolutionByProblemAndUser(arg0 context.Context, arg1 db.GetSolutionByProblemAndUserParams) (db.Solution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSolutionByProblemAndUser", arg0, arg1)
+	ret0, _ := ret[0].(db.Solution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSolutionByProblemAndUser indicates an expected call of GetSolutionByProblemAndUser.
+func (mr *MockStoreMockRecorder) GetSolutionByProblemAndUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolutionByProblemAndUser", reflect.TypeOf((*MockStore)(nil).GetSolutionByProblemAndUser), arg0, arg1)
+}
+
+// GetSubject mocks base method.
+func (m *MockStore) GetSubject(arg0 context.Context, arg1 int64) (db.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubject", arg0, arg1)
+	ret0, _ := ret[0].(db.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubject indicates an expected call of GetSubject.
+func (mr *MockStoreMockRecorder) GetSubject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubject", reflect.TypeOf((*MockStore)(nil).GetSubject), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStore) GetUserByEmail(arg0 context.Context, arg1 sql.NullString) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStoreMockRecorder) GetUserByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), arg0, arg1)
+}
+
+// GetUserByPhoneNumber mocks base method.
+func (m *MockStore) GetUserByPhoneNumber(arg0 context.Context, arg1 sql.NullString) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByPhoneNumber", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByPhoneNumber indicates an expected call of GetUserByPhoneNumber.
+func (mr *MockStoreMockRecorder) GetUserByPhoneNumber(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByPhoneNumber", reflect.TypeOf((*MockStore)(nil).GetUserByPhoneNumber), arg0, arg1)
+}
+
+// GetUserForUpdate mocks base method.
+func (m *MockStore) GetUserForUpdate(arg0 context.Context, arg1 int64) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserForUpdate indicates an expected call of GetUserForUpdate.
+func (mr *MockStoreMockRecorder) GetUserForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserForUpdate", reflect.TypeOf((*MockStore)(nil).GetUserForUpdate), arg0, arg1)
+}
+
+// GetUserIdentity mocks base method.
+func (m *MockStore) GetUserIdentity(arg0 context.Context, arg1 db.GetUserIdentityParams) (db.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(db.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentity indicates an expected call of GetUserIdentity.
+func (mr *MockStoreMockRecorder) GetUserIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentity", reflect.TypeOf((*MockStore)(nil).GetUserIdentity), arg0, arg1)
+}
+
+// ImportUsersTx mocks base method.
+func (m *MockStore) ImportUsersTx(arg0 context.Context, arg1 db.ImportUsersTxParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportUsersTx", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportUsersTx indicates an expected call of ImportUsersTx.
+func (mr *MockStoreMockRecorder) ImportUsersTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportUsersTx", reflect.TypeOf((*MockStore)(nil).ImportUsersTx), arg0, arg1)
+}
+
+// JoinClassWithInviteTx mocks base method.
+func (m *MockStore) JoinClassWithInviteTx(arg0 context.Context, arg1 db.JoinClassWithInviteTxParams) (db.ClassMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinClassWithInviteTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ClassMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JoinClassWithInviteTx indicates an expected call of JoinClassWithInviteTx.
+func (mr *MockStoreMockRecorder) JoinClassWithInviteTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinClassWithInviteTx", reflect.TypeOf((*MockStore)(nil).JoinClassWithInviteTx), arg0, arg1)
+}
+
+// ListAllUsers mocks base method.
+func (m *MockStore) ListAllUsers(arg0 context.Context, arg1 db.ListAllUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllUsers indicates an expected call of ListAllUsers.
+func (mr *MockStoreMockRecorder) ListAllUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllUsers", reflect.TypeOf((*MockStore)(nil).ListAllUsers), arg0, arg1)
+}
+
+// ListClassMembers mocks base method.
+func (m *MockStore) ListClassMembers(arg0 context.Context, arg1 db.ListClassMembersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClassMembers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClassMembers indicates an expected call of ListClassMembers.
+func (mr *MockStoreMockRecorder) ListClassMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClassMembers", reflect.TypeOf((*MockStore)(nil).ListClassMembers), arg0, arg1)
+}
+
+// ListClassMembersByTeacher mocks base method.
+func (m *MockStore) ListClassMembersByTeacher(arg0 context.Context, arg1 int64) ([]db.ClassMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClassMembersByTeacher", arg0, arg1)
+	ret0, _ := ret[0].([]db.ClassMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClassMembersByTeacher indicates an expected call of ListClassMembersByTeacher.
+func (mr *MockStoreMockRecorder) ListClassMembersByTeacher(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClassMembersByTeacher", reflect.TypeOf((*MockStore)(nil).ListClassMembersByTeacher), arg0, arg1)
+}
+
+// ListClassesForUser mocks base method.
+func (m *MockStore) ListClassesForUser(arg0 context.Context, arg1 db.ListClassesForUserParams) ([]db.Class, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClassesForUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Class)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClassesForUser indicates an expected call of ListClassesForUser.
+func (mr *MockStoreMockRecorder) ListClassesForUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClassesForUser", reflect.TypeOf((*MockStore)(nil).ListClassesForUser), arg0, arg1)
+}
+
+// ListGradebookEntries mocks base method.
+func (m *MockStore) ListGradebookEntries(arg0 context.Context, arg1 db.ListGradebookEntriesParams) ([]db.ListGradebookEntriesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGradebookEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListGradebookEntriesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGradebookEntries indicates an expected call of ListGradebookEntries.
+func (mr *MockStoreMockRecorder) ListGradebookEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGradebookEntries", reflect.TypeOf((*MockStore)(nil).ListGradebookEntries), arg0, arg1)
+}
+
+// ListGradebookHomeworks mocks base method.
+func (m *MockStore) ListGradebookHomeworks(arg0 context.Context, arg1 db.ListGradebookHomeworksParams) ([]db.Homework, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGradebookHomeworks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Homework)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGradebookHomeworks indicates an expected call of ListGradebookHomeworks.
+func (mr *MockStoreMockRecorder) ListGradebookHomeworks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGradebookHomeworks", reflect.TypeOf((*MockStore)(nil).ListGradebookHomeworks), arg0, arg1)
+}
+
+// ListGradebookStudents mocks base method.
+func (m *MockStore) ListGradebookStudents(arg0 context.Context, arg1 int64) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGradebookStudents", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGradebookStudents indicates an expected call of ListGradebookStudents.
+func (mr *MockStoreMockRecorder) ListGradebookStudents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGradebookStudents", reflect.TypeOf((*MockStore)(nil).ListGradebookStudents), arg0, arg1)
+}
+
+// ListHomeworks mocks base method.
+func (m *MockStore) ListHomeworks(arg0 context.Context, arg1 db.ListHomeworksParams) ([]db.Homework, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHomeworks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Homework)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHomeworks indicates an expected call of ListHomeworks.
+func (mr *MockStoreMockRecorder) ListHomeworks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHomeworks", reflect.TypeOf((*MockStore)(nil).ListHomeworks), arg0, arg1)
+}
+
+// ListHomeworksBySubject mocks base method.
+func (m *MockStore) ListHomeworksBySubject(arg0 context.Context, arg1 db.ListHomeworksBySubjectParams) ([]db.Homework, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHomeworksBySubject", arg0, arg1)
+	ret0, _ := ret[0].([]db.Homework)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHomeworksBySubject indicates an expected call of ListHomeworksBySubject.
+func (mr *MockStoreMockRecorder) ListHomeworksBySubject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHomeworksBySubject", reflect.TypeOf((*MockStore)(nil).ListHomeworksBySubject), arg0, arg1)
+}
+
+// ListHomeworksBySubjectForUser mocks base method.
+func (m *MockStore) ListHomeworksBySubjectForUser(arg0 context.Context, arg1 db.ListHomeworksBySubjectForUserParams) ([]db.Homework, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHomeworksBySubjectForUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Homework)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHomeworksBySubjectForUser indicates an expected call of ListHomeworksBySubjectForUser.
+func (mr *MockStoreMockRecorder) ListHomeworksBySubjectForUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHomeworksBySubjectForUser", reflect.TypeOf((*MockStore)(nil).ListHomeworksBySubjectForUser), arg0, arg1)
+}
+
+// ListHomeworksByTeacher mocks base method.
+func (m *MockStore) ListHomeworksByTeacher(arg0 context.Context, arg1 db.ListHomeworksByTeacherParams) ([]db.Homework, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHomeworksByTeacher", arg0, arg1)
+	ret0, _ := ret[0].([]db.Homework)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHomeworksByTeacher indicates an expected call of ListHomeworksByTeacher.
+func (mr *MockStoreMockRecorder) ListHomeworksByTeacher(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHomeworksByTeacher", reflect.TypeOf((*MockStore)(nil).ListHomeworksByTeacher), arg0, arg1)
+}
+
+// ListHomeworksForUser mocks base method.
+func (m *MockStore) ListHomeworksForUser(arg0 context.Context, arg1 db.ListHomeworksForUserParams) ([]db.Homework, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHomeworksForUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Homework)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHomeworksForUser indicates an expected call of ListHomeworksForUser.
+func (mr *MockStoreMockRecorder) ListHomeworksForUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHomeworksForUser", reflect.TypeOf((*MockStore)(nil).ListHomeworksForUser), arg0, arg1)
+}
+
+// ListInviteRedemptions mocks base method.
+func (m *MockStore) ListInviteRedemptions(arg0 context.Context, arg1 db.ListInviteRedemptionsParams) ([]db.InviteRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInviteRedemptions", arg0, arg1)
+	ret0, _ := ret[0].([]db.InviteRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInviteRedemptions indicates an expected call of ListInviteRedemptions.
+func (mr *MockStoreMockRecorder) ListInviteRedemptions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInviteRedemptions", reflect.TypeOf((*MockStore)(nil).ListInviteRedemptions), arg0, arg1)
+}
+
+// ListInvites mocks base method.
+func (m *MockStore) ListInvites(arg0 context.Context, arg1 db.ListInvitesParams) ([]db.Invite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvites", arg0, arg1)
+	ret0, _ := ret[0].([]db.Invite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvites indicates an expected call of ListInvites.
+func (mr *MockStoreMockRecorder) ListInvites(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvites", reflect.TypeOf((*MockStore)(nil).ListInvites), arg0, arg1)
+}
+
+// ListInvitesByCreator mocks base method.
+func (m *MockStore) ListInvitesByCreator(arg0 context.Context, arg1 db.ListInvitesByCreatorParams) ([]db.Invite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvitesByCreator", arg0, arg1)
+	ret0, _ := ret[0].([]db.Invite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvitesByCreator indicates an expected call of ListInvitesByCreator.
+func (mr *MockStoreMockRecorder) ListInvitesByCreator(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvitesByCreator", reflect.TypeOf((*MockStore)(nil).ListInvitesByCreator), arg0, arg1)
+}
+
+// ListMessages mocks base method.
+func (m *MockStore) ListMessages(arg0 context.Context, arg1 db.ListMessagesParams) ([]db.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMessages", arg0, arg1)
+	ret0, _ := ret[0].([]db.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMessages indicates an expected call of ListMessages.
+func (mr *MockStoreMockRecorder) ListMessages(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessages", reflect.TypeOf((*MockStore)(nil).ListMessages), arg0, arg1)
+}
+
+// ListMessagesFromUser mocks base method.
+func (m *MockStore) ListMessagesFromUser(arg0 context.Context, arg1 db.ListMessagesFromUserParams) ([]db.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMessagesFromUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMessagesFromUser indicates an expected call of ListMessagesFromUser.
+func (mr *MockStoreMockRecorder) ListMessagesFromUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessagesFromUser", reflect.TypeOf((*MockStore)(nil).ListMessagesFromUser), arg0, arg1)
+}
+
+// ListMessagesToUser mocks base method.
+func (m *MockStore) ListMessagesToUser(arg0 context.Context, arg1 db.ListMessagesToUserParams) ([]db.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMessagesToUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMessagesToUser indicates an expected call of ListMessagesToUser.
+func (mr *MockStoreMockRecorder) ListMessagesToUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessagesToUser", reflect.TypeOf((*MockStore)(nil).ListMessagesToUser), arg0, arg1)
+}
+
+// ListPasswordHistory mocks base method.
+func (m *MockStore) ListPasswordHistory(arg0 context.Context, arg1 db.ListPasswordHistoryParams) ([]db.PasswordHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPasswordHistory", arg0, arg1)
+	ret0, _ := ret[0].([]db.PasswordHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPasswordHistory indicates an expected call of ListPasswordHistory.
+func (mr *MockStoreMockRecorder) ListPasswordHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPasswordHistory", reflect.TypeOf((*MockStore)(nil).ListPasswordHistory), arg0, arg1)
+}
+
+// ListSessionsByUsername mocks base method.
+func (m *MockStore) ListSessionsByUsername(arg0 context.Context, arg1 string) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessionsByUsername", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessionsByUsername indicates an expected call of ListSessionsByUsername.
+func (mr *MockStoreMockRecorder) ListSessionsByUsername(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessionsByUsername", reflect.TypeOf((*MockStore)(nil).ListSessionsByUsername), arg0, arg1)
+}
+
+// ListSolutionsByProblem mocks base method.
+func (m *MockStore) ListSolutionsByProblem(arg0 context.Context, arg1 db.ListSolutionsByProblemParams) ([]db.Solution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSolutionsByProblem", arg0, arg1)
+	ret0, _ := ret[0].([]db.Solution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSolutionsByProblem indicates an expected call of ListSolutionsByProblem.
+func (mr *MockStoreMockRecorder) ListSolutionsByProblem(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSolutionsByProblem", reflect.TypeOf((*MockStore)(nil).ListSolutionsByProblem), arg0, arg1)
+}
+
+// ListSolutionsByUser mocks base method.
+func (m *MockStore) ListSolutionsByUser(arg0 context.Context, arg1 db.ListSolutionsByUserParams) ([]db.Solution, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSolutionsByUser", arg0, arg1)
+	ret0, _ := ret[0].([]db.Solution)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSolutionsByUser indicates an expected call of ListSolutionsByUser.
+func (mr *MockStoreMockRecorder) ListSolutionsByUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSolutionsByUser", reflect.TypeOf((*MockStore)(nil).ListSolutionsByUser), arg0, arg1)
+}
+
+// ListSubjects mocks base method.
+func (m *MockStore) ListSubjects(arg0 context.Context) ([]db.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubjects", arg0)
+	ret0, _ := ret[0].([]db.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubjects indicates an expected call of ListSubjects.
+func (mr *MockStoreMockRecorder) ListSubjects(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubjects", reflect.TypeOf((*MockStore)(nil).ListSubjects), arg0)
+}
+
+// ListUserFiles mocks base method.
+func (m *MockStore) ListUserFiles(arg0 context.Context, arg1 int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserFiles", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserFiles indicates an expected call of ListUserFiles.
+func (mr *MockStoreMockRecorder) ListUserFiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserFiles", reflect.TypeOf((*MockStore)(nil).ListUserFiles), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
+}
+
+// LockLogin mocks base method.
+func (m *MockStore) LockLogin(arg0 context.Context, arg1 db.LockLoginParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLogin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLogin indicates an expected call of LockLogin.
+func (mr *MockStoreMockRecorder) LockLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogin", reflect.TypeOf((*MockStore)(nil).LockLogin), arg0, arg1)
+}
+
+// ProvisionOIDCUserTx mocks base method.
+func (m *MockStore) ProvisionOIDCUserTx(arg0 context.Context, arg1 db.ProvisionOIDCUserTxParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvisionOIDCUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProvisionOIDCUserTx indicates an expected call of ProvisionOIDCUserTx.
+func (mr *MockStoreMockRecorder) ProvisionOIDCUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvisionOIDCUserTx", reflect.TypeOf((*MockStore)(nil).ProvisionOIDCUserTx), arg0, arg1)
+}
+
+// PrunePasswordHistory mocks base method.
+func (m *MockStore) PrunePasswordHistory(arg0 context.Context, arg1 db.PrunePasswordHistoryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrunePasswordHistory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrunePasswordHistory indicates an expected call of PrunePasswordHistory.
+func (mr *MockStoreMockRecorder) PrunePasswordHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrunePasswordHistory", reflect.TypeOf((*MockStore)(nil).PrunePasswordHistory), arg0, arg1)
+}
+
+// PurgeUserTx mocks base method.
+func (m *MockStore) PurgeUserTx(arg0 context.Context, arg1 int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUserTx", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+// PurgeUserTx indicates an expected call of PurgeUserTx.
+func (mr *MockStoreMockRecorder) PurgeUserTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUserTx", reflect.TypeOf((*MockStore)(nil).PurgeUserTx), arg0, arg1)
 }
 
-// GetByUsername mocks base method.
-func (m *MockStore) GetByUsername(arg0 context.Context, arg1 sql.NullString) (db.User, error) {
+// ReactivateUser mocks base method.
+func (m *MockStore) ReactivateUser(arg0 context.Context, arg1 int64) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByUsername", arg0, arg1)
+	ret := m.ctrl.Call(m, "ReactivateUser", arg0, arg1)
 	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByUsername indicates an expected call of GetByUsername.
-func (mr *MockStoreMockRecorder) GetByUsername(arg0, arg1 interface{}) *gomock.Call {
+// ReactivateUser indicates an expected call of ReactivateUser.
+func (mr *MockStoreMockRecorder) ReactivateUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUsername", reflect.TypeOf((*MockStore)(nil).GetByUsername), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateUser", reflect.TypeOf((*MockStore)(nil).ReactivateUser), arg0, arg1)
 }
 
-// GetHomework mocks base method.
-func (m *MockStore) GetHomework(arg0 context.Context, arg1 int64) (db.Homework, error) {
+// RecordLoginFailure mocks base method.
+func (m *MockStore) RecordLoginFailure(arg0 context.Context, arg1 db.RecordLoginFailureParams) (db.LoginFailure, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetHomework", arg0, arg1)
-	ret0, _ := ret[0].(db.Homework)
+	ret := m.ctrl.Call(m, "RecordLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginFailure)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetHomework indicates an expected call of GetHomework.
-func (mr *MockStoreMockRecorder) GetHomework(arg0, arg1 interface{}) *gomock.Call {
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockStoreMockRecorder) RecordLoginFailure(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomework", reflect.TypeOf((*MockStore)(nil).GetHomework), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockStore)(nil).RecordLoginFailure), arg0, arg1)
 }
 
-// GetMessage mocks base method.
-func (m *MockStore) GetMessage(arg0 context.Context, arg1 int64) (db.Message, error) {
+// ReleaseGrade mocks base method.
+func (m *MockStore) ReleaseGrade(arg0 context.Context, arg1 db.ReleaseGradeParams) (db.Grade, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMessage", arg0, arg1)
-	ret0, _ := ret[0].(db.Message)
+	ret := m.ctrl.Call(m, "ReleaseGrade", arg0, arg1)
+	ret0, _ := ret[0].(db.Grade)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetMessage indicates an expected call of GetMessage.
-func (mr *MockStoreMockRecorder) GetMessage(arg0, arg1 interface{}) *gomock.Call {
+// ReleaseGrade indicates an expected call of ReleaseGrade.
+func (mr *MockStoreMockRecorder) ReleaseGrade(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessage", reflect.TypeOf((*MockStore)(nil).GetMessage), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseGrade", reflect.TypeOf((*MockStore)(nil).ReleaseGrade), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+// RemoveClassMember mocks base method.
+func (m *MockStore) RemoveClassMember(arg0 context.Context, arg1 db.RemoveClassMemberParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "RemoveClassMember", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+// RemoveClassMember indicates an expected call of RemoveClassMember.
+func (mr *MockStoreMockRecorder) RemoveClassMember(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveClassMember", reflect.TypeOf((*MockStore)(nil).RemoveClassMember), arg0, arg1)
 }
 
-// GetSolutionByID mocks base method.
-func (m *MockStore) GetSolutionByID(arg0 context.Context, arg1 int64) (db.Solution, error) {
+// ReplaceRecoveryCodesTx mocks base method.
+func (m *MockStore) ReplaceRecoveryCodesTx(arg0 context.Context, arg1 db.ReplaceRecoveryCodesTxParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSolutionByID", arg0, arg1)
-	ret0, _ := ret[0].(db.Solution)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodesTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetSolutionByID indicates an expected call of GetSolutionByID.
-func (mr *MockStoreMockRecorder) GetSolutionByID(arg0, arg1 interface{}) *gomock.Call {
+// ReplaceRecoveryCodesTx indicates an expected call of ReplaceRecoveryCodesTx.
+func (mr *MockStoreMockRecorder) ReplaceRecoveryCodesTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolutionByID", reflect.TypeOf((*MockStore)(nil).GetSolutionByID), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodesTx", reflect.TypeOf((*MockStore)(nil).ReplaceRecoveryCodesTx), arg0, arg1)
 }
 
-// GetSolutionByProblemAndUser mocks base method.
-func (m *MockStore) GetSolutionByProblemAndUser(arg0 context.Context, arg1 db.GetSolutionByProblemAndUserParams) (db.Solution, error) {
+// ResetPasswordTx mocks base method.
+func (m *MockStore) ResetPasswordTx(arg0 context.Context, arg1 db.ResetPasswordTxParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSolutionByProblemAndUser", arg0, arg1)
-	ret0, _ := ret[0].(db.Solution)
+	ret := m.ctrl.Call(m, "ResetPasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSolutionByProblemAndUser indicates an expected call of GetSolutionByProblemAndUser.
-func (mr *MockStoreMockRecorder) GetSolutionByProblemAndUser(arg0, arg1 interface{}) *gomock.Call {
+// ResetPasswordTx indicates an expected call of ResetPasswordTx.
+func (mr *MockStoreMockRecorder) ResetPasswordTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSolutionByProblemAndUser", reflect.TypeOf((*MockStore)(nil).GetSolutionByProblemAndUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPasswordTx", reflect.TypeOf((*MockStore)(nil).ResetPasswordTx), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 int64) (db.User, error) {
+// RevokeInvite mocks base method.
+func (m *MockStore) RevokeInvite(arg0 context.Context, arg1 db.RevokeInviteParams) (db.Invite, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "RevokeInvite", arg0, arg1)
+	ret0, _ := ret[0].(db.Invite)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// RevokeInvite indicates an expected call of RevokeInvite.
+func (mr *MockStoreMockRecorder) RevokeInvite(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeInvite", reflect.TypeOf((*MockStore)(nil).RevokeInvite), arg0, arg1)
 }
 
-// GetUserForUpdate mocks base method.
-func (m *MockStore) GetUserForUpdate(arg0 context.Context, arg1 int64) (db.User, error) {
+// RotateSession mocks base method.
+func (m *MockStore) RotateSession(arg0 context.Context, arg1 db.RotateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserForUpdate", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "RotateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserForUpdate indicates an expected call of GetUserForUpdate.
-func (mr *MockStoreMockRecorder) GetUserForUpdate(arg0, arg1 interface{}) *gomock.Call {
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockStoreMockRecorder) RotateSession(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserForUpdate", reflect.TypeOf((*MockStore)(nil).GetUserForUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockStore)(nil).RotateSession), arg0, arg1)
 }
 
-// ListHomeworks mocks base method.
-func (m *MockStore) ListHomeworks(arg0 context.Context, arg1 db.ListHomeworksParams) ([]db.Homework, error) {
+// RotateSessionTx mocks base method.
+func (m *MockStore) RotateSessionTx(arg0 context.Context, arg1 db.RotateSessionTxParams) (db.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListHomeworks", arg0, arg1)
-	ret0, _ := ret[0].([]db.Homework)
+	ret := m.ctrl.Call(m, "RotateSessionTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListHomeworks indicates an expected call of ListHomeworks.
-func (mr *MockStoreMockRecorder) ListHomeworks(arg0, arg1 interface{}) *gomock.Call {
+// RotateSessionTx indicates an expected call of RotateSessionTx.
+func (mr *MockStoreMockRecorder) RotateSessionTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHomeworks", reflect.TypeOf((*MockStore)(nil).ListHomeworks), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSessionTx", reflect.TypeOf((*MockStore)(nil).RotateSessionTx), arg0, arg1)
 }
 
-// ListHomeworksBySubject mocks base method.
-func (m *MockStore) ListHomeworksBySubject(arg0 context.Context, arg1 db.ListHomeworksBySubjectParams) ([]db.Homework, error) {
+// SetUserTOTPSecret mocks base method.
+func (m *MockStore) SetUserTOTPSecret(arg0 context.Context, arg1 db.SetUserTOTPSecretParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListHomeworksBySubject", arg0, arg1)
-	ret0, _ := ret[0].([]db.Homework)
+	ret := m.ctrl.Call(m, "SetUserTOTPSecret", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListHomeworksBySubject indicates an expected call of ListHomeworksBySubject.
-func (mr *MockStoreMockRecorder) ListHomeworksBySubject(arg0, arg1 interface{}) *gomock.Call {
+// SetUserTOTPSecret indicates an expected call of SetUserTOTPSecret.
+func (mr *MockStoreMockRecorder) SetUserTOTPSecret(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHomeworksBySubject", reflect.TypeOf((*MockStore)(nil).ListHomeworksBySubject), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserTOTPSecret", reflect.TypeOf((*MockStore)(nil).SetUserTOTPSecret), arg0, arg1)
 }
 
-// ListHomeworksByTeacher mocks base method.
-func (m *MockStore) ListHomeworksByTeacher(arg0 context.Context, arg1 db.ListHomeworksByTeacherParams) ([]db.Homework, error) {
+// SignUpWithInviteTx mocks base method.
+func (m *MockStore) SignUpWithInviteTx(arg0 context.Context, arg1 db.SignUpWithInviteTxParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListHomeworksByTeacher", arg0, arg1)
-	ret0, _ := ret[0].([]db.Homework)
+	ret := m.ctrl.Call(m, "SignUpWithInviteTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListHomeworksByTeacher indicates an expected call of ListHomeworksByTeacher.
-func (mr *MockStoreMockRecorder) ListHomeworksByTeacher(arg0, arg1 interface{}) *gomock.Call {
+// SignUpWithInviteTx indicates an expected call of SignUpWithInviteTx.
+func (mr *MockStoreMockRecorder) SignUpWithInviteTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHomeworksByTeacher", reflect.TypeOf((*MockStore)(nil).ListHomeworksByTeacher), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUpWithInviteTx", reflect.TypeOf((*MockStore)(nil).SignUpWithInviteTx), arg0, arg1)
 }
 
-// ListMessages mocks base method.
-func (m *MockStore) ListMessages(arg0 context.Context, arg1 db.ListMessagesParams) ([]db.Message, error) {
+// UpdateClassJoinCode mocks base method.
+func (m *MockStore) UpdateClassJoinCode(arg0 context.Context, arg1 db.UpdateClassJoinCodeParams) (db.Class, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListMessages", arg0, arg1)
-	ret0, _ := ret[0].([]db.Message)
+	ret := m.ctrl.Call(m, "UpdateClassJoinCode", arg0, arg1)
+	ret0, _ := ret[0].(db.Class)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListMessages indicates an expected call of ListMessages.
-func (mr *MockStoreMockRecorder) ListMessages(arg0, arg1 interface{}) *gomock.Call {
+// UpdateClassJoinCode indicates an expected call of UpdateClassJoinCode.
+func (mr *MockStoreMockRecorder) UpdateClassJoinCode(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessages", reflect.TypeOf((*MockStore)(nil).ListMessages), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClassJoinCode", reflect.TypeOf((*MockStore)(nil).UpdateClassJoinCode), arg0, arg1)
 }
 
-// ListMessagesFromUser mocks base method.
-func (m *MockStore) ListMessagesFromUser(arg0 context.Context, arg1 db.ListMessagesFromUserParams) ([]db.Message, error) {
+// UpdateGrade mocks base method.
+func (m *MockStore) UpdateGrade(arg0 context.Context, arg1 db.UpdateGradeParams) (db.Grade, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListMessagesFromUser", arg0, arg1)
-	ret0, _ := ret[0].([]db.Message)
+	ret := m.ctrl.Call(m, "UpdateGrade", arg0, arg1)
+	ret0, _ := ret[0].(db.Grade)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListMessagesFromUser indicates an expected call of ListMessagesFromUser.
-func (mr *MockStoreMockRecorder) ListMessagesFromUser(arg0, arg1 interface{}) *gomock.Call {
+// UpdateGrade indicates an expected call of UpdateGrade.
+func (mr *MockStoreMockRecorder) UpdateGrade(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessagesFromUser", reflect.TypeOf((*MockStore)(nil).ListMessagesFromUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGrade", reflect.TypeOf((*MockStore)(nil).UpdateGrade), arg0, arg1)
 }
 
-// ListMessagesToUser mocks base method.
-func (m *MockStore) ListMessagesToUser(arg0 context.Context, arg1 db.ListMessagesToUserParams) ([]db.Message, error) {
+// UpdateHomework mocks base method.
+func (m *MockStore) UpdateHomework(arg0 context.Context, arg1 db.UpdateHomeworkParams) (db.Homework, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListMessagesToUser", arg0, arg1)
-	ret0, _ := ret[0].([]db.Message)
+	ret := m.ctrl.Call(m, "UpdateHomework", arg0, arg1)
+	ret0, _ := ret[0].(db.Homework)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListMessagesToUser indicates an expected call of ListMessagesToUser.
-func (mr *MockStoreMockRecorder) ListMessagesToUser(arg0, arg1 interface{}) *gomock.Call {
+// UpdateHomework indicates an expected call of UpdateHomework.
+func (mr *MockStoreMockRecorder) UpdateHomework(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessagesToUser", reflect.TypeOf((*MockStore)(nil).ListMessagesToUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHomework", reflect.TypeOf((*MockStore)(nil).UpdateHomework), arg0, arg1)
 }
 
-// ListSolutionsByProblem mocks base method.
-func (m *MockStore) ListSolutionsByProblem(arg0 context.Context, arg1 db.ListSolutionsByProblemParams) ([]db.Solution, error) {
+// UpdateHomeworkClass mocks base method.
+func (m *MockStore) UpdateHomeworkClass(arg0 context.Context, arg1 db.UpdateHomeworkClassParams) (db.Homework, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListSolutionsByProblem", arg0, arg1)
-	ret0, _ := ret[0].([]db.Solution)
+	ret := m.ctrl.Call(m, "UpdateHomeworkClass", arg0, arg1)
+	ret0, _ := ret[0].(db.Homework)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListSolutionsByProblem indicates an expected call of ListSolutionsByProblem.
-func (mr *MockStoreMockRecorder) ListSolutionsByProblem(arg0, arg1 interface{}) *gomock.Call {
+// UpdateHomeworkClass indicates an expected call of UpdateHomeworkClass.
+func (mr *MockStoreMockRecorder) UpdateHomeworkClass(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSolutionsByProblem", reflect.TypeOf((*MockStore)(nil).ListSolutionsByProblem), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHomeworkClass", reflect.TypeOf((*MockStore)(nil).UpdateHomeworkClass), arg0, arg1)
 }
 
-// ListSolutionsByUser mocks base method.
-func (m *MockStore) ListSolutionsByUser(arg0 context.Context, arg1 db.ListSolutionsByUserParams) ([]db.Solution, error) {
+// UpdateHomeworkDueAt mocks base method.
+func (m *MockStore) UpdateHomeworkDueAt(arg0 context.Context, arg1 db.UpdateHomeworkDueAtParams) (db.Homework, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListSolutionsByUser", arg0, arg1)
-	ret0, _ := ret[0].([]db.Solution)
+	ret := m.ctrl.Call(m, "UpdateHomeworkDueAt", arg0, arg1)
+	ret0, _ := ret[0].(db.Homework)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListSolutionsByUser indicates an expected call of ListSolutionsByUser.
-func (mr *MockStoreMockRecorder) ListSolutionsByUser(arg0, arg1 interface{}) *gomock.Call {
+// UpdateHomeworkDueAt indicates an expected call of UpdateHomeworkDueAt.
+func (mr *MockStoreMockRecorder) UpdateHomeworkDueAt(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSolutionsByUser", reflect.TypeOf((*MockStore)(nil).ListSolutionsByUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHomeworkDueAt", reflect.TypeOf((*MockStore)(nil).UpdateHomeworkDueAt), arg0, arg1)
 }
 
-// ListUsers mocks base method.
-func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+// UpdateHomeworkLatePolicy mocks base method.
+func (m *MockStore) UpdateHomeworkLatePolicy(arg0 context.Context, arg1 db.UpdateHomeworkLatePolicyParams) (db.Homework, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
-	ret0, _ := ret[0].([]db.User)
+	ret := m.ctrl.Call(m, "UpdateHomeworkLatePolicy", arg0, arg1)
+	ret0, _ := ret[0].(db.Homework)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListUsers indicates an expected call of ListUsers.
-func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+// UpdateHomeworkLatePolicy indicates an expected call of UpdateHomeworkLatePolicy.
+func (mr *MockStoreMockRecorder) UpdateHomeworkLatePolicy(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHomeworkLatePolicy", reflect.TypeOf((*MockStore)(nil).UpdateHomeworkLatePolicy), arg0, arg1)
 }
 
-// UpdateHomework mocks base method.
-func (m *MockStore) UpdateHomework(arg0 context.Context, arg1 db.UpdateHomeworkParams) (db.Homework, error) {
+// UpdateHomeworkTx mocks base method.
+func (m *MockStore) UpdateHomeworkTx(arg0 context.Context, arg1 db.UpdateHomeworkTxParams) (db.Homework, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateHomework", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateHomeworkTx", arg0, arg1)
 	ret0, _ := ret[0].(db.Homework)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateHomework indicates an expected call of UpdateHomework.
-func (mr *MockStoreMockRecorder) UpdateHomework(arg0, arg1 interface{}) *gomock.Call {
+// UpdateHomeworkTx indicates an expected call of UpdateHomeworkTx.
+func (mr *MockStoreMockRecorder) UpdateHomeworkTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHomework", reflect.TypeOf((*MockStore)(nil).UpdateHomework), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHomeworkTx", reflect.TypeOf((*MockStore)(nil).UpdateHomeworkTx), arg0, arg1)
 }
 
 // UpdateMessage mocks base method.
@@ -512,6 +1728,21 @@ func (mr *MockStoreMockRecorder) UpdateSolution(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSolution", reflect.TypeOf((*MockStore)(nil).UpdateSolution), arg0, arg1)
 }
 
+// UpdateSubject mocks base method.
+func (m *MockStore) UpdateSubject(arg0 context.Context, arg1 db.UpdateSubjectParams) (db.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubject", arg0, arg1)
+	ret0, _ := ret[0].(db.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSubject indicates an expected call of UpdateSubject.
+func (mr *MockStoreMockRecorder) UpdateSubject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubject", reflect.TypeOf((*MockStore)(nil).UpdateSubject), arg0, arg1)
+}
+
 // UpdateUserInfo mocks base method.
 func (m *MockStore) UpdateUserInfo(arg0 context.Context, arg1 db.UpdateUserInfoParams) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -556,3 +1787,168 @@ func (mr *MockStoreMockRecorder) UpdateUserPassword(arg0, arg1 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), arg0, arg1)
 }
+
+// UpdateUserPasswordTx mocks base method.
+func (m *MockStore) UpdateUserPasswordTx(arg0 context.Context, arg1 db.UpdateUserPasswordTxParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPasswordTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserPasswordTx indicates an expected call of UpdateUserPasswordTx.
+func (mr *MockStoreMockRecorder) UpdateUserPasswordTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPasswordTx", reflect.TypeOf((*MockStore)(nil).UpdateUserPasswordTx), arg0, arg1)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockStore) UpdateUserRole(arg0 context.Context, arg1 db.UpdateUserRoleParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockStoreMockRecorder) UpdateUserRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockStore)(nil).UpdateUserRole), arg0, arg1)
+}
+
+// UseEmailVerification mocks base method.
+func (m *MockStore) UseEmailVerification(arg0 context.Context, arg1 db.UseEmailVerificationParams) (db.EmailVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseEmailVerification", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseEmailVerification indicates an expected call of UseEmailVerification.
+func (mr *MockStoreMockRecorder) UseEmailVerification(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseEmailVerification", reflect.TypeOf((*MockStore)(nil).UseEmailVerification), arg0, arg1)
+}
+
+// UseInvite mocks base method.
+func (m *MockStore) UseInvite(arg0 context.Context, arg1 db.UseInviteParams) (db.Invite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseInvite", arg0, arg1)
+	ret0, _ := ret[0].(db.Invite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseInvite indicates an expected call of UseInvite.
+func (mr *MockStoreMockRecorder) UseInvite(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseInvite", reflect.TypeOf((*MockStore)(nil).UseInvite), arg0, arg1)
+}
+
+// UseMFAChallenge mocks base method.
+func (m *MockStore) UseMFAChallenge(arg0 context.Context, arg1 db.UseMFAChallengeParams) (db.MfaChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseMFAChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.MfaChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseMFAChallenge indicates an expected call of UseMFAChallenge.
+func (mr *MockStoreMockRecorder) UseMFAChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseMFAChallenge", reflect.TypeOf((*MockStore)(nil).UseMFAChallenge), arg0, arg1)
+}
+
+// UseOIDCLogin mocks base method.
+func (m *MockStore) UseOIDCLogin(arg0 context.Context, arg1 db.UseOIDCLoginParams) (db.OidcLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOIDCLogin", arg0, arg1)
+	ret0, _ := ret[0].(db.OidcLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseOIDCLogin indicates an expected call of UseOIDCLogin.
+func (mr *MockStoreMockRecorder) UseOIDCLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOIDCLogin", reflect.TypeOf((*MockStore)(nil).UseOIDCLogin), arg0, arg1)
+}
+
+// UsePasswordReset mocks base method.
+func (m *MockStore) UsePasswordReset(arg0 context.Context, arg1 db.UsePasswordResetParams) (db.PasswordReset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UsePasswordReset", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordReset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UsePasswordReset indicates an expected call of UsePasswordReset.
+func (mr *MockStoreMockRecorder) UsePasswordReset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordReset", reflect.TypeOf((*MockStore)(nil).UsePasswordReset), arg0, arg1)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
+}
+
+// UseUserTOTPStep mocks base method.
+func (m *MockStore) UseUserTOTPStep(arg0 context.Context, arg1 db.UseUserTOTPStepParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseUserTOTPStep", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseUserTOTPStep indicates an expected call of UseUserTOTPStep.
+func (mr *MockStoreMockRecorder) UseUserTOTPStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseUserTOTPStep", reflect.TypeOf((*MockStore)(nil).UseUserTOTPStep), arg0, arg1)
+}
+
+// VerifyEmailTx mocks base method.
+func (m *MockStore) VerifyEmailTx(arg0 context.Context, arg1 db.VerifyEmailTxParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmailTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmailTx indicates an expected call of VerifyEmailTx.
+func (mr *MockStoreMockRecorder) VerifyEmailTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmailTx", reflect.TypeOf((*MockStore)(nil).VerifyEmailTx), arg0, arg1)
+}
+
+// VerifyUserEmail mocks base method.
+func (m *MockStore) VerifyUserEmail(arg0 context.Context, arg1 db.VerifyUserEmailParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyUserEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyUserEmail indicates an expected call of VerifyUserEmail.
+func (mr *MockStoreMockRecorder) VerifyUserEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyUserEmail", reflect.TypeOf((*MockStore)(nil).VerifyUserEmail), arg0, arg1)
+}
@@ -0,0 +1,64 @@
+-- name: CreateClass :one
+INSERT INTO classes (
+    teacher_id,
+    name,
+    join_code
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetClass :one
+SELECT * FROM classes
+WHERE id = $1 LIMIT 1;
+
+-- name: GetClassByJoinCode :one
+SELECT * FROM classes
+WHERE join_code = $1 LIMIT 1;
+
+-- name: ListClassesForUser :many
+SELECT * FROM classes
+WHERE id IN (SELECT class_id FROM class_members WHERE user_id = $1)
+    OR teacher_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateClassJoinCode :one
+UPDATE classes
+SET join_code = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteClass :exec
+DELETE FROM classes
+WHERE id = $1;
+
+-- name: AddClassMember :one
+INSERT INTO class_members (
+    class_id,
+    user_id
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetClassMember :one
+SELECT * FROM class_members
+WHERE class_id = $1 AND user_id = $2
+LIMIT 1;
+
+-- name: ListClassMembers :many
+SELECT * FROM users
+WHERE id IN (SELECT user_id FROM class_members WHERE class_id = $1)
+    AND deleted_at = '0001-01-01 00:00:00Z'
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListClassMembersByTeacher :many
+SELECT cm.class_id, cm.user_id, cm.joined_at FROM class_members cm
+JOIN classes c ON c.id = cm.class_id
+WHERE c.teacher_id = $1;
+
+-- name: RemoveClassMember :exec
+DELETE FROM class_members
+WHERE class_id = $1 AND user_id = $2;
@@ -0,0 +1,17 @@
+-- name: CreateEmailVerification :one
+INSERT INTO email_verifications (
+    user_id,
+    email,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UseEmailVerification :one
+UPDATE email_verifications
+SET used_at = $2
+WHERE token_hash = $1
+    AND used_at = '0001-01-01 00:00:00Z'
+    AND expires_at > $2
+RETURNING *;
@@ -0,0 +1,38 @@
+-- name: CreateGrade :one
+INSERT INTO grades (
+    solution_id,
+    grader_id,
+    score,
+    feedback,
+    feedback_file_name,
+    feedback_saved_path
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetGradeBySolution :one
+SELECT * FROM grades
+WHERE solution_id = $1
+LIMIT 1;
+
+-- name: UpdateGrade :one
+UPDATE grades
+SET grader_id = $2,
+    score = $3,
+    feedback = $4,
+    feedback_file_name = $5,
+    feedback_saved_path = $6,
+    updated_at = $7
+WHERE id = $1
+RETURNING *;
+
+-- name: ReleaseGrade :one
+UPDATE grades
+SET is_released = true,
+    released_at = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteGrade :exec
+DELETE FROM grades
+WHERE id = $1;
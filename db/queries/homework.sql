@@ -4,9 +4,16 @@ INSERT INTO homeworks (
     subject,
     title,
     file_name,
-    saved_path
+    saved_path,
+    due_at,
+    allow_late,
+    late_grace_minutes,
+    late_penalty_per_day,
+    late_cutoff_at,
+    max_score,
+    class_id
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 ) RETURNING *;
 
 -- name: GetHomework :one
@@ -33,6 +40,38 @@ ORDER BY id
 LIMIT $1
 OFFSET $2;
 
+-- name: ListHomeworksForUser :many
+SELECT * FROM homeworks
+WHERE class_id IN (SELECT class_id FROM class_members WHERE user_id = $1)
+    OR teacher_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListHomeworksBySubjectForUser :many
+SELECT * FROM homeworks
+WHERE subject = $1
+    AND (class_id IN (SELECT class_id FROM class_members WHERE user_id = $2)
+        OR teacher_id = $2)
+ORDER BY id
+LIMIT $3
+OFFSET $4;
+
+-- name: UpdateHomeworkClass :one
+UPDATE homeworks
+SET class_id = $2,
+    updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: ListGradebookHomeworks :many
+SELECT * FROM homeworks
+WHERE teacher_id = sqlc.arg(teacher_id)
+    AND (sqlc.narg(subject)::varchar IS NULL OR subject = sqlc.narg(subject))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+ORDER BY created_at, id;
+
 -- name: UpdateHomework :one
 UPDATE homeworks
 SET file_name = $2,
@@ -41,6 +80,25 @@ SET file_name = $2,
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateHomeworkDueAt :one
+UPDATE homeworks
+SET due_at = $2,
+    updated_at = $3,
+    is_closed = false,
+    closed_at = '0001-01-01 00:00:00Z'
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateHomeworkLatePolicy :one
+UPDATE homeworks
+SET allow_late = $2,
+    late_grace_minutes = $3,
+    late_penalty_per_day = $4,
+    late_cutoff_at = $5,
+    updated_at = $6
+WHERE id = $1
+RETURNING *;
+
 -- name: CloseHomework :one
 UPDATE homeworks
 SET is_closed = $2,
@@ -48,6 +106,15 @@ SET is_closed = $2,
 WHERE id = $1
 RETURNING *;
 
+-- name: CloseOverdueHomeworks :many
+UPDATE homeworks
+SET is_closed = true,
+    closed_at = due_at
+WHERE is_closed = false
+    AND due_at IS NOT NULL
+    AND due_at <= sqlc.arg(now)::timestamptz
+RETURNING *;
+
 -- name: DeleteHomework :exec
 DELETE FROM homeworks
 WHERE id = $1;
@@ -0,0 +1,60 @@
+-- name: CreateInvite :one
+INSERT INTO invites (
+    code_hash,
+    role,
+    class_id,
+    created_by,
+    max_uses,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetInvite :one
+SELECT * FROM invites
+WHERE id = $1 LIMIT 1;
+
+-- name: ListInvites :many
+SELECT * FROM invites
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: ListInvitesByCreator :many
+SELECT * FROM invites
+WHERE created_by = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: RevokeInvite :one
+UPDATE invites
+SET revoked_at = $2
+WHERE id = $1
+    AND revoked_at = '0001-01-01 00:00:00Z'
+RETURNING *;
+
+-- name: UseInvite :one
+UPDATE invites
+SET uses = uses + 1
+WHERE code_hash = sqlc.arg(code_hash)
+    AND revoked_at = '0001-01-01 00:00:00Z'
+    AND expires_at > sqlc.arg(now)
+    AND uses < max_uses
+RETURNING *;
+
+-- name: CreateInviteRedemption :one
+INSERT INTO invite_redemptions (
+    invite_id,
+    user_id,
+    redeemed_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: ListInviteRedemptions :many
+SELECT * FROM invite_redemptions
+WHERE invite_id = $1
+ORDER BY redeemed_at
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,33 @@
+-- name: GetLoginFailure :one
+SELECT * FROM login_failures
+WHERE key = $1 LIMIT 1;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_failures (
+    key,
+    failed_count,
+    last_failed_at
+) VALUES (
+    sqlc.arg(key), 1, sqlc.arg(last_failed_at)
+)
+ON CONFLICT (key) DO UPDATE
+SET failed_count = CASE
+        WHEN login_failures.last_failed_at < sqlc.arg(window_start) THEN 1
+        ELSE login_failures.failed_count + 1
+    END,
+    last_failed_at = EXCLUDED.last_failed_at
+RETURNING *;
+
+-- name: LockLogin :exec
+UPDATE login_failures
+SET locked_until = $2
+WHERE key = $1;
+
+-- name: DeleteLoginFailure :exec
+DELETE FROM login_failures
+WHERE key = $1;
+
+-- name: DeleteExpiredLoginFailures :exec
+DELETE FROM login_failures
+WHERE last_failed_at < sqlc.arg(before)
+  AND locked_until < sqlc.arg(before);
@@ -0,0 +1,23 @@
+-- name: CreateMFAChallenge :one
+INSERT INTO mfa_challenges (
+    user_id,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetMFAChallenge :one
+SELECT * FROM mfa_challenges
+WHERE token_hash = sqlc.arg(token_hash)
+    AND used_at = '0001-01-01 00:00:00Z'
+    AND expires_at > sqlc.arg(now)
+LIMIT 1;
+
+-- name: UseMFAChallenge :one
+UPDATE mfa_challenges
+SET used_at = $2
+WHERE id = $1
+    AND used_at = '0001-01-01 00:00:00Z'
+    AND expires_at > $2
+RETURNING *;
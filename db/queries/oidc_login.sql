@@ -0,0 +1,17 @@
+-- name: CreateOIDCLogin :one
+INSERT INTO oidc_logins (
+    state_hash,
+    nonce,
+    code_verifier,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UseOIDCLogin :one
+UPDATE oidc_logins
+SET used_at = $2
+WHERE state_hash = $1
+    AND used_at = '0001-01-01 00:00:00Z'
+    AND expires_at > $2
+RETURNING *;
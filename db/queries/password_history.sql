@@ -0,0 +1,23 @@
+-- name: AddPasswordHistory :exec
+INSERT INTO password_histories (
+    user_id,
+    hashed_password
+)
+SELECT id, hashed_password FROM users
+WHERE id = $1;
+
+-- name: ListPasswordHistory :many
+SELECT * FROM password_histories
+WHERE user_id = $1
+ORDER BY id DESC
+LIMIT $2;
+
+-- name: PrunePasswordHistory :exec
+DELETE FROM password_histories
+WHERE user_id = $1
+    AND id NOT IN (
+        SELECT id FROM password_histories
+        WHERE user_id = $1
+        ORDER BY id DESC
+        LIMIT $2
+    );
@@ -0,0 +1,27 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+    user_id,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET used_at = $2
+WHERE token_hash = $1
+    AND used_at = '0001-01-01 00:00:00Z'
+    AND expires_at > $2
+RETURNING *;
+
+-- name: DeletePasswordResetsByUser :exec
+DELETE FROM password_resets
+WHERE user_id = $1;
+
+-- name: GetPasswordReset :one
+SELECT * FROM password_resets
+WHERE token_hash = sqlc.arg(token_hash)
+    AND used_at = '0001-01-01 00:00:00Z'
+    AND expires_at > sqlc.arg(now)
+LIMIT 1;
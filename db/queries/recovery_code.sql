@@ -0,0 +1,19 @@
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+    user_id,
+    code_hash
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = $3
+WHERE user_id = $1
+    AND code_hash = $2
+    AND used_at = '0001-01-01 00:00:00Z'
+RETURNING *;
+
+-- name: DeleteRecoveryCodesByUser :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
@@ -6,15 +6,48 @@ INSERT INTO sessions (
     user_agent,
     client_ip,
     is_blocked,
-    expires_at
+    expires_at,
+    family_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions
 WHERE id = $1 LIMIT 1;
 
+-- name: GetSessionAuth :one
+SELECT s.id, s.username, s.family_id, s.is_blocked, s.expires_at, u.id AS user_id, u.role, u.password_changed_at
+FROM sessions s
+JOIN users u ON u.username = s.username
+WHERE s.id = $1 LIMIT 1;
+
 -- name: DeleteSession :exec
 DELETE FROM sessions
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListSessionsByUsername :many
+SELECT * FROM sessions
+WHERE username = $1
+    AND expires_at > now()
+    AND rotated_at = '0001-01-01 00:00:00Z'
+ORDER BY create_at DESC;
+
+-- name: RotateSession :one
+UPDATE sessions
+SET rotated_at = $2
+WHERE id = $1 AND rotated_at = '0001-01-01 00:00:00Z'
+RETURNING *;
+
+-- name: BlockSessionFamily :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1;
+
+-- name: DeleteSessionFamily :exec
+DELETE FROM sessions
+WHERE family_id = $1;
+
+-- name: DeleteSessionsByUsername :exec
+DELETE FROM sessions
+WHERE username = $1;
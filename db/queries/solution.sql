@@ -3,9 +3,12 @@ INSERT INTO solutions (
     problem_id,
     user_id,
     file_name,
-    saved_path
+    saved_path,
+    is_late,
+    late_seconds,
+    late_penalty
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6, $7
 ) RETURNING *;
 
 -- name: GetSolutionByID :one
@@ -33,11 +36,26 @@ ORDER BY id
 LIMIT $2
 OFFSET $3;
 
+-- name: ListGradebookEntries :many
+SELECT s.id, s.problem_id, s.user_id, s.submited_at, s.is_late, s.late_seconds, s.late_penalty,
+    g.score, g.is_released
+FROM solutions s
+JOIN homeworks h ON h.id = s.problem_id
+LEFT JOIN grades g ON g.solution_id = s.id
+WHERE h.teacher_id = sqlc.arg(teacher_id)
+    AND (sqlc.narg(subject)::varchar IS NULL OR h.subject = sqlc.narg(subject))
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR h.created_at >= sqlc.narg(created_from))
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR h.created_at < sqlc.narg(created_to))
+ORDER BY s.user_id, s.problem_id;
+
 -- name: UpdateSolution :one
 UPDATE solutions
 SET file_name = $2,
     saved_path = $3,
-    updated_at = $4
+    updated_at = $4,
+    is_late = $5,
+    late_seconds = $6,
+    late_penalty = $7
 WHERE id = $1
 RETURNING *;
 
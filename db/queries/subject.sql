@@ -0,0 +1,24 @@
+-- name: CreateSubject :one
+INSERT INTO subjects (
+    name
+) VALUES (
+    $1
+) RETURNING *;
+
+-- name: GetSubject :one
+SELECT * FROM subjects
+WHERE id = $1 LIMIT 1;
+
+-- name: ListSubjects :many
+SELECT * FROM subjects
+ORDER BY name;
+
+-- name: UpdateSubject :one
+UPDATE subjects
+SET name = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteSubject :exec
+DELETE FROM subjects
+WHERE id = $1;
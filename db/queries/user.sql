@@ -5,7 +5,7 @@ INSERT INTO users (
     fullname,
     email,
     phone_number,
-    is_teacher
+    role
 ) VALUES (
     $1, $2, $3, $4, $5, $6
 ) RETURNING *;
@@ -18,6 +18,14 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
 
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
+-- name: GetUserByPhoneNumber :one
+SELECT * FROM users
+WHERE phone_number = $1 LIMIT 1;
+
 -- name: GetUserForUpdate :one
 SELECT * FROM users
 WHERE id = $1 LIMIT 1
@@ -25,16 +33,33 @@ FOR UPDATE;
 
 -- name: ListUsers :many
 SELECT * FROM users
+WHERE deleted_at = '0001-01-01 00:00:00Z'
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: ListAllUsers :many
+SELECT * FROM users
 ORDER BY id
 LIMIT $1
 OFFSET $2;
 
+-- name: ListGradebookStudents :many
+SELECT * FROM users
+WHERE id IN (
+    SELECT cm.user_id FROM class_members cm
+    JOIN classes c ON c.id = cm.class_id
+    WHERE c.teacher_id = $1
+)
+ORDER BY id;
+
 -- name: UpdateUserInfo :one
 UPDATE users
 SET username = COALESCE($2, username),
     fullname = COALESCE($3, fullname),
     email = COALESCE($4, email),
-    phone_number = COALESCE($5, phone_number)
+    phone_number = COALESCE($5, phone_number),
+    email_verified_at = CASE WHEN $4 IS NULL OR $4 = email THEN email_verified_at ELSE '0001-01-01 00:00:00Z' END
 WHERE id = $1
 RETURNING *;
 
@@ -45,6 +70,73 @@ SET hashed_password = $2,
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET email_verified_at = $3
+WHERE id = $1 AND email = $2
+RETURNING *;
+
+-- name: SetUserTOTPSecret :one
+UPDATE users
+SET totp_secret = $2,
+    totp_enabled_at = '0001-01-01 00:00:00Z',
+    totp_last_step = 0
+WHERE id = $1
+RETURNING *;
+
+-- name: EnableUserTOTP :one
+UPDATE users
+SET totp_enabled_at = $2,
+    totp_last_step = $3
+WHERE id = $1 AND totp_secret <> ''
+RETURNING *;
+
+-- name: UseUserTOTPStep :one
+UPDATE users
+SET totp_last_step = $2
+WHERE id = $1 AND totp_last_step < $2
+RETURNING *;
+
+-- name: DisableUserTOTP :one
+UPDATE users
+SET totp_secret = '',
+    totp_enabled_at = '0001-01-01 00:00:00Z',
+    totp_last_step = 0
+WHERE id = $1
+RETURNING *;
+
+-- name: DeactivateUser :one
+UPDATE users
+SET deleted_at = $2
+WHERE id = $1 AND deleted_at = '0001-01-01 00:00:00Z'
+RETURNING *;
+
+-- name: ReactivateUser :one
+UPDATE users
+SET deleted_at = '0001-01-01 00:00:00Z'
+WHERE id = $1 AND deleted_at <> '0001-01-01 00:00:00Z'
+RETURNING *;
+
+-- name: ListUserFiles :many
+SELECT saved_path FROM homeworks
+WHERE teacher_id = sqlc.arg(user_id)
+UNION
+SELECT s.saved_path FROM solutions s
+JOIN homeworks h ON h.id = s.problem_id
+WHERE s.user_id = sqlc.arg(user_id) OR h.teacher_id = sqlc.arg(user_id)
+UNION
+SELECT g.feedback_saved_path FROM grades g
+JOIN solutions s ON s.id = g.solution_id
+JOIN homeworks h ON h.id = s.problem_id
+WHERE g.feedback_saved_path <> ''
+    AND (s.user_id = sqlc.arg(user_id) OR h.teacher_id = sqlc.arg(user_id) OR g.grader_id = sqlc.arg(user_id));
+
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1;
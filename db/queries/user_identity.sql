@@ -0,0 +1,13 @@
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+    user_id,
+    issuer,
+    subject,
+    email
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE issuer = $1 AND subject = $2 LIMIT 1;
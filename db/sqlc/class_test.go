@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomClass(t *testing.T, teacherID int64) Class {
+	arg := CreateClassParams{
+		TeacherID: teacherID,
+		Name:      util.RandomString(10),
+		JoinCode:  util.RandomString(8),
+	}
+
+	class, err := testQueries.CreateClass(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, class)
+
+	require.NotZero(t, class.ID)
+	require.Equal(t, arg.TeacherID, class.TeacherID)
+	require.Equal(t, arg.Name, class.Name)
+	require.Equal(t, arg.JoinCode, class.JoinCode)
+	require.NotZero(t, class.CreatedAt)
+
+	return class
+}
+
+func TestClassMembers(t *testing.T) {
+	teacher := createRandomTeacher(t)
+	user := createRandomUser(t)
+	class := createRandomClass(t, teacher.ID)
+
+	class2, err := testQueries.GetClassByJoinCode(context.Background(), class.JoinCode)
+	require.NoError(t, err)
+	require.Equal(t, class.ID, class2.ID)
+
+	member, err := testQueries.AddClassMember(context.Background(), AddClassMemberParams{
+		ClassID: class.ID,
+		UserID:  user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, class.ID, member.ClassID)
+	require.Equal(t, user.ID, member.UserID)
+	require.NotZero(t, member.JoinedAt)
+
+	_, err = testQueries.AddClassMember(context.Background(), AddClassMemberParams{
+		ClassID: class.ID,
+		UserID:  user.ID,
+	})
+	require.Error(t, err)
+
+	users, err := testQueries.ListClassMembers(context.Background(), ListClassMembersParams{
+		ClassID: class.ID,
+		Limit:   5,
+		Offset:  0,
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, user.ID, users[0].ID)
+
+	for _, userID := range []int64{teacher.ID, user.ID} {
+		classes, err := testQueries.ListClassesForUser(context.Background(), ListClassesForUserParams{
+			UserID: userID,
+			Limit:  5,
+			Offset: 0,
+		})
+		require.NoError(t, err)
+		require.Len(t, classes, 1)
+		require.Equal(t, class.ID, classes[0].ID)
+	}
+
+	homework := createRandomHomework(t, teacher.ID, util.RandomSubject())
+	homeworks, err := testQueries.ListHomeworksForUser(context.Background(), ListHomeworksForUserParams{
+		UserID: user.ID,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, homeworks)
+
+	_, err = testQueries.UpdateHomeworkClass(context.Background(), UpdateHomeworkClassParams{
+		ID:      homework.ID,
+		ClassID: sql.NullInt64{Int64: class.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	homeworks, err = testQueries.ListHomeworksForUser(context.Background(), ListHomeworksForUserParams{
+		UserID: user.ID,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, homeworks, 1)
+	require.Equal(t, homework.ID, homeworks[0].ID)
+
+	err = testQueries.RemoveClassMember(context.Background(), RemoveClassMemberParams{
+		ClassID: class.ID,
+		UserID:  user.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetClassMember(context.Background(), GetClassMemberParams{
+		ClassID: class.ID,
+		UserID:  user.ID,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	testQueries.DeleteHomework(context.Background(), homework.ID)
+	testQueries.DeleteClass(context.Background(), class.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomGrade(t *testing.T, graderID, solutionID int64) Grade {
+	arg := CreateGradeParams{
+		SolutionID: solutionID,
+		GraderID:   graderID,
+		Score:      float64(util.RandomInt(0, 100)),
+		Feedback:   util.RandomString(20),
+	}
+
+	grade, err := testQueries.CreateGrade(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, grade)
+
+	require.NotZero(t, grade.ID)
+	require.Equal(t, arg.SolutionID, grade.SolutionID)
+	require.Equal(t, arg.GraderID, grade.GraderID)
+	require.Equal(t, arg.Score, grade.Score)
+	require.Equal(t, arg.Feedback, grade.Feedback)
+	require.Empty(t, grade.FeedbackSavedPath)
+	require.False(t, grade.IsReleased)
+	require.NotZero(t, grade.CreatedAt)
+	require.True(t, grade.ReleasedAt.IsZero())
+
+	return grade
+}
+
+func TestGrade(t *testing.T) {
+	teacher := createRandomTeacher(t)
+	user := createRandomUser(t)
+	homework := createRandomHomework(t, teacher.ID, util.RandomSubject())
+	solution := createRandomSolution(t, user.ID, homework.ID)
+
+	grade1 := createRandomGrade(t, teacher.ID, solution.ID)
+
+	grade2, err := testQueries.GetGradeBySolution(context.Background(), solution.ID)
+	require.NoError(t, err)
+	require.Equal(t, grade1.ID, grade2.ID)
+
+	_, err = testQueries.CreateGrade(context.Background(), CreateGradeParams{
+		SolutionID: solution.ID,
+		GraderID:   teacher.ID,
+	})
+	require.Error(t, err)
+
+	arg := UpdateGradeParams{
+		ID:                grade1.ID,
+		GraderID:          teacher.ID,
+		Score:             grade1.Score / 2,
+		Feedback:          util.RandomString(20),
+		FeedbackFileName:  util.RandomString(6),
+		FeedbackSavedPath: util.RandomString(6),
+		UpdatedAt:         time.Now(),
+	}
+
+	grade3, err := testQueries.UpdateGrade(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Score, grade3.Score)
+	require.Equal(t, arg.Feedback, grade3.Feedback)
+	require.Equal(t, arg.FeedbackSavedPath, grade3.FeedbackSavedPath)
+	require.WithinDuration(t, arg.UpdatedAt, grade3.UpdatedAt, time.Second)
+	require.False(t, grade3.IsReleased)
+
+	releasedAt := time.Now()
+	grade4, err := testQueries.ReleaseGrade(context.Background(), ReleaseGradeParams{
+		ID:         grade1.ID,
+		ReleasedAt: releasedAt,
+	})
+	require.NoError(t, err)
+	require.True(t, grade4.IsReleased)
+	require.WithinDuration(t, releasedAt, grade4.ReleasedAt, time.Second)
+
+	err = testQueries.DeleteGrade(context.Background(), grade1.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetGradeBySolution(context.Background(), solution.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	testQueries.DeleteSolution(context.Background(), solution.ID)
+	testQueries.DeleteHomework(context.Background(), homework.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
+
+func TestListGradebook(t *testing.T) {
+	teacher := createRandomTeacher(t)
+	user := createRandomUser(t)
+	subject := util.RandomSubject()
+	homework := createRandomHomework(t, teacher.ID, subject)
+	solution := createRandomSolution(t, user.ID, homework.ID)
+	grade := createRandomGrade(t, teacher.ID, solution.ID)
+
+	homeworks, err := testQueries.ListGradebookHomeworks(context.Background(), ListGradebookHomeworksParams{
+		TeacherID: teacher.ID,
+		Subject:   sql.NullString{String: subject, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, homeworks, 1)
+	require.Equal(t, homework.ID, homeworks[0].ID)
+
+	homeworks, err = testQueries.ListGradebookHomeworks(context.Background(), ListGradebookHomeworksParams{
+		TeacherID:   teacher.ID,
+		CreatedFrom: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Empty(t, homeworks)
+
+	entries, err := testQueries.ListGradebookEntries(context.Background(), ListGradebookEntriesParams{
+		TeacherID: teacher.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, solution.ID, entries[0].ID)
+	require.Equal(t, user.ID, entries[0].UserID)
+	require.True(t, entries[0].Score.Valid)
+	require.Equal(t, grade.Score, entries[0].Score.Float64)
+	require.False(t, entries[0].IsReleased.Bool)
+
+	class := createRandomClass(t, teacher.ID)
+	_, err = testQueries.AddClassMember(context.Background(), AddClassMemberParams{
+		ClassID: class.ID,
+		UserID:  user.ID,
+	})
+	require.NoError(t, err)
+
+	students, err := testQueries.ListGradebookStudents(context.Background(), teacher.ID)
+	require.NoError(t, err)
+	require.Len(t, students, 1)
+	require.Equal(t, user.ID, students[0].ID)
+
+	testQueries.DeleteGrade(context.Background(), grade.ID)
+	testQueries.DeleteSolution(context.Background(), solution.ID)
+	testQueries.DeleteHomework(context.Background(), homework.ID)
+	testQueries.DeleteClass(context.Background(), class.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -15,7 +16,7 @@ UPDATE homeworks
 SET is_closed = $2,
     closed_at = $3
 WHERE id = $1
-RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at
+RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id
 `
 
 type CloseHomeworkParams struct {
@@ -38,28 +39,100 @@ func (q *Queries) CloseHomework(ctx context.Context, arg CloseHomeworkParams) (H
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ClosedAt,
+		&i.DueAt,
+		&i.AllowLate,
+		&i.LateGraceMinutes,
+		&i.LatePenaltyPerDay,
+		&i.LateCutoffAt,
+		&i.MaxScore,
+		&i.ClassID,
 	)
 	return i, err
 }
 
+const closeOverdueHomeworks = `-- name: CloseOverdueHomeworks :many
+UPDATE homeworks
+SET is_closed = true,
+    closed_at = due_at
+WHERE is_closed = false
+    AND due_at IS NOT NULL
+    AND due_at <= $1::timestamptz
+RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id
+`
+
+func (q *Queries) CloseOverdueHomeworks(ctx context.Context, now time.Time) ([]Homework, error) {
+	rows, err := q.db.QueryContext(ctx, closeOverdueHomeworks, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Homework{}
+	for rows.Next() {
+		var i Homework
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeacherID,
+			&i.Subject,
+			&i.Title,
+			&i.FileName,
+			&i.SavedPath,
+			&i.IsClosed,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClosedAt,
+			&i.DueAt,
+			&i.AllowLate,
+			&i.LateGraceMinutes,
+			&i.LatePenaltyPerDay,
+			&i.LateCutoffAt,
+			&i.MaxScore,
+			&i.ClassID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createHomework = `-- name: CreateHomework :one
 INSERT INTO homeworks (
     teacher_id,
     subject,
     title,
     file_name,
-    saved_path
+    saved_path,
+    due_at,
+    allow_late,
+    late_grace_minutes,
+    late_penalty_per_day,
+    late_cutoff_at,
+    max_score,
+    class_id
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id
 `
 
 type CreateHomeworkParams struct {
-	TeacherID int64  `json:"teacher_id"`
-	Subject   string `json:"subject"`
-	Title     string `json:"title"`
-	FileName  string `json:"file_name"`
-	SavedPath string `json:"saved_path"`
+	TeacherID         int64         `json:"teacher_id"`
+	Subject           string        `json:"subject"`
+	Title             string        `json:"title"`
+	FileName          string        `json:"file_name"`
+	SavedPath         string        `json:"saved_path"`
+	DueAt             sql.NullTime  `json:"due_at"`
+	AllowLate         bool          `json:"allow_late"`
+	LateGraceMinutes  int32         `json:"late_grace_minutes"`
+	LatePenaltyPerDay int32         `json:"late_penalty_per_day"`
+	LateCutoffAt      sql.NullTime  `json:"late_cutoff_at"`
+	MaxScore          float64       `json:"max_score"`
+	ClassID           sql.NullInt64 `json:"class_id"`
 }
 
 func (q *Queries) CreateHomework(ctx context.Context, arg CreateHomeworkParams) (Homework, error) {
@@ -69,6 +142,13 @@ func (q *Queries) CreateHomework(ctx context.Context, arg CreateHomeworkParams)
 		arg.Title,
 		arg.FileName,
 		arg.SavedPath,
+		arg.DueAt,
+		arg.AllowLate,
+		arg.LateGraceMinutes,
+		arg.LatePenaltyPerDay,
+		arg.LateCutoffAt,
+		arg.MaxScore,
+		arg.ClassID,
 	)
 	var i Homework
 	err := row.Scan(
@@ -82,6 +162,13 @@ func (q *Queries) CreateHomework(ctx context.Context, arg CreateHomeworkParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ClosedAt,
+		&i.DueAt,
+		&i.AllowLate,
+		&i.LateGraceMinutes,
+		&i.LatePenaltyPerDay,
+		&i.LateCutoffAt,
+		&i.MaxScore,
+		&i.ClassID,
 	)
 	return i, err
 }
@@ -97,7 +184,7 @@ func (q *Queries) DeleteHomework(ctx context.Context, id int64) error {
 }
 
 const getHomework = `-- name: GetHomework :one
-SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at FROM homeworks
+SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id FROM homeworks
 WHERE id = $1 LIMIT 1
 `
 
@@ -115,12 +202,81 @@ func (q *Queries) GetHomework(ctx context.Context, id int64) (Homework, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ClosedAt,
+		&i.DueAt,
+		&i.AllowLate,
+		&i.LateGraceMinutes,
+		&i.LatePenaltyPerDay,
+		&i.LateCutoffAt,
+		&i.MaxScore,
+		&i.ClassID,
 	)
 	return i, err
 }
 
+const listGradebookHomeworks = `-- name: ListGradebookHomeworks :many
+SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id FROM homeworks
+WHERE teacher_id = $1
+    AND ($2::varchar IS NULL OR subject = $2)
+    AND ($3::timestamptz IS NULL OR created_at >= $3)
+    AND ($4::timestamptz IS NULL OR created_at < $4)
+ORDER BY created_at, id
+`
+
+type ListGradebookHomeworksParams struct {
+	TeacherID   int64          `json:"teacher_id"`
+	Subject     sql.NullString `json:"subject"`
+	CreatedFrom sql.NullTime   `json:"created_from"`
+	CreatedTo   sql.NullTime   `json:"created_to"`
+}
+
+func (q *Queries) ListGradebookHomeworks(ctx context.Context, arg ListGradebookHomeworksParams) ([]Homework, error) {
+	rows, err := q.db.QueryContext(ctx, listGradebookHomeworks,
+		arg.TeacherID,
+		arg.Subject,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Homework{}
+	for rows.Next() {
+		var i Homework
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeacherID,
+			&i.Subject,
+			&i.Title,
+			&i.FileName,
+			&i.SavedPath,
+			&i.IsClosed,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClosedAt,
+			&i.DueAt,
+			&i.AllowLate,
+			&i.LateGraceMinutes,
+			&i.LatePenaltyPerDay,
+			&i.LateCutoffAt,
+			&i.MaxScore,
+			&i.ClassID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listHomeworks = `-- name: ListHomeworks :many
-SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at FROM homeworks
+SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id FROM homeworks
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -151,6 +307,13 @@ func (q *Queries) ListHomeworks(ctx context.Context, arg ListHomeworksParams) ([
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.ClosedAt,
+			&i.DueAt,
+			&i.AllowLate,
+			&i.LateGraceMinutes,
+			&i.LatePenaltyPerDay,
+			&i.LateCutoffAt,
+			&i.MaxScore,
+			&i.ClassID,
 		); err != nil {
 			return nil, err
 		}
@@ -166,7 +329,7 @@ func (q *Queries) ListHomeworks(ctx context.Context, arg ListHomeworksParams) ([
 }
 
 const listHomeworksBySubject = `-- name: ListHomeworksBySubject :many
-SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at FROM homeworks
+SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id FROM homeworks
 WHERE subject = $1
 ORDER BY id
 LIMIT $2
@@ -199,6 +362,76 @@ func (q *Queries) ListHomeworksBySubject(ctx context.Context, arg ListHomeworksB
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.ClosedAt,
+			&i.DueAt,
+			&i.AllowLate,
+			&i.LateGraceMinutes,
+			&i.LatePenaltyPerDay,
+			&i.LateCutoffAt,
+			&i.MaxScore,
+			&i.ClassID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHomeworksBySubjectForUser = `-- name: ListHomeworksBySubjectForUser :many
+SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id FROM homeworks
+WHERE subject = $1
+    AND (class_id IN (SELECT class_id FROM class_members WHERE user_id = $2)
+        OR teacher_id = $2)
+ORDER BY id
+LIMIT $3
+OFFSET $4
+`
+
+type ListHomeworksBySubjectForUserParams struct {
+	Subject string `json:"subject"`
+	UserID  int64  `json:"user_id"`
+	Limit   int32  `json:"limit"`
+	Offset  int32  `json:"offset"`
+}
+
+func (q *Queries) ListHomeworksBySubjectForUser(ctx context.Context, arg ListHomeworksBySubjectForUserParams) ([]Homework, error) {
+	rows, err := q.db.QueryContext(ctx, listHomeworksBySubjectForUser,
+		arg.Subject,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Homework{}
+	for rows.Next() {
+		var i Homework
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeacherID,
+			&i.Subject,
+			&i.Title,
+			&i.FileName,
+			&i.SavedPath,
+			&i.IsClosed,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClosedAt,
+			&i.DueAt,
+			&i.AllowLate,
+			&i.LateGraceMinutes,
+			&i.LatePenaltyPerDay,
+			&i.LateCutoffAt,
+			&i.MaxScore,
+			&i.ClassID,
 		); err != nil {
 			return nil, err
 		}
@@ -214,7 +447,7 @@ func (q *Queries) ListHomeworksBySubject(ctx context.Context, arg ListHomeworksB
 }
 
 const listHomeworksByTeacher = `-- name: ListHomeworksByTeacher :many
-SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at FROM homeworks
+SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id FROM homeworks
 WHERE teacher_id = $1
 ORDER BY id
 LIMIT $2
@@ -247,6 +480,69 @@ func (q *Queries) ListHomeworksByTeacher(ctx context.Context, arg ListHomeworksB
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.ClosedAt,
+			&i.DueAt,
+			&i.AllowLate,
+			&i.LateGraceMinutes,
+			&i.LatePenaltyPerDay,
+			&i.LateCutoffAt,
+			&i.MaxScore,
+			&i.ClassID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHomeworksForUser = `-- name: ListHomeworksForUser :many
+SELECT id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id FROM homeworks
+WHERE class_id IN (SELECT class_id FROM class_members WHERE user_id = $1)
+    OR teacher_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListHomeworksForUserParams struct {
+	UserID int64 `json:"user_id"`
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListHomeworksForUser(ctx context.Context, arg ListHomeworksForUserParams) ([]Homework, error) {
+	rows, err := q.db.QueryContext(ctx, listHomeworksForUser, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Homework{}
+	for rows.Next() {
+		var i Homework
+		if err := rows.Scan(
+			&i.ID,
+			&i.TeacherID,
+			&i.Subject,
+			&i.Title,
+			&i.FileName,
+			&i.SavedPath,
+			&i.IsClosed,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClosedAt,
+			&i.DueAt,
+			&i.AllowLate,
+			&i.LateGraceMinutes,
+			&i.LatePenaltyPerDay,
+			&i.LateCutoffAt,
+			&i.MaxScore,
+			&i.ClassID,
 		); err != nil {
 			return nil, err
 		}
@@ -267,7 +563,7 @@ SET file_name = $2,
     saved_path = $3,
     updated_at = $4
 WHERE id = $1
-RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at
+RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id
 `
 
 type UpdateHomeworkParams struct {
@@ -296,6 +592,145 @@ func (q *Queries) UpdateHomework(ctx context.Context, arg UpdateHomeworkParams)
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.ClosedAt,
+		&i.DueAt,
+		&i.AllowLate,
+		&i.LateGraceMinutes,
+		&i.LatePenaltyPerDay,
+		&i.LateCutoffAt,
+		&i.MaxScore,
+		&i.ClassID,
+	)
+	return i, err
+}
+
+const updateHomeworkClass = `-- name: UpdateHomeworkClass :one
+UPDATE homeworks
+SET class_id = $2,
+    updated_at = $3
+WHERE id = $1
+RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id
+`
+
+type UpdateHomeworkClassParams struct {
+	ID        int64         `json:"id"`
+	ClassID   sql.NullInt64 `json:"class_id"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+func (q *Queries) UpdateHomeworkClass(ctx context.Context, arg UpdateHomeworkClassParams) (Homework, error) {
+	row := q.db.QueryRowContext(ctx, updateHomeworkClass, arg.ID, arg.ClassID, arg.UpdatedAt)
+	var i Homework
+	err := row.Scan(
+		&i.ID,
+		&i.TeacherID,
+		&i.Subject,
+		&i.Title,
+		&i.FileName,
+		&i.SavedPath,
+		&i.IsClosed,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.DueAt,
+		&i.AllowLate,
+		&i.LateGraceMinutes,
+		&i.LatePenaltyPerDay,
+		&i.LateCutoffAt,
+		&i.MaxScore,
+		&i.ClassID,
+	)
+	return i, err
+}
+
+const updateHomeworkDueAt = `-- name: UpdateHomeworkDueAt :one
+UPDATE homeworks
+SET due_at = $2,
+    updated_at = $3,
+    is_closed = false,
+    closed_at = '0001-01-01 00:00:00Z'
+WHERE id = $1
+RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id
+`
+
+type UpdateHomeworkDueAtParams struct {
+	ID        int64        `json:"id"`
+	DueAt     sql.NullTime `json:"due_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+func (q *Queries) UpdateHomeworkDueAt(ctx context.Context, arg UpdateHomeworkDueAtParams) (Homework, error) {
+	row := q.db.QueryRowContext(ctx, updateHomeworkDueAt, arg.ID, arg.DueAt, arg.UpdatedAt)
+	var i Homework
+	err := row.Scan(
+		&i.ID,
+		&i.TeacherID,
+		&i.Subject,
+		&i.Title,
+		&i.FileName,
+		&i.SavedPath,
+		&i.IsClosed,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.DueAt,
+		&i.AllowLate,
+		&i.LateGraceMinutes,
+		&i.LatePenaltyPerDay,
+		&i.LateCutoffAt,
+		&i.MaxScore,
+		&i.ClassID,
+	)
+	return i, err
+}
+
+const updateHomeworkLatePolicy = `-- name: UpdateHomeworkLatePolicy :one
+UPDATE homeworks
+SET allow_late = $2,
+    late_grace_minutes = $3,
+    late_penalty_per_day = $4,
+    late_cutoff_at = $5,
+    updated_at = $6
+WHERE id = $1
+RETURNING id, teacher_id, subject, title, file_name, saved_path, is_closed, created_at, updated_at, closed_at, due_at, allow_late, late_grace_minutes, late_penalty_per_day, late_cutoff_at, max_score, class_id
+`
+
+type UpdateHomeworkLatePolicyParams struct {
+	ID                int64        `json:"id"`
+	AllowLate         bool         `json:"allow_late"`
+	LateGraceMinutes  int32        `json:"late_grace_minutes"`
+	LatePenaltyPerDay int32        `json:"late_penalty_per_day"`
+	LateCutoffAt      sql.NullTime `json:"late_cutoff_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+}
+
+func (q *Queries) UpdateHomeworkLatePolicy(ctx context.Context, arg UpdateHomeworkLatePolicyParams) (Homework, error) {
+	row := q.db.QueryRowContext(ctx, updateHomeworkLatePolicy,
+		arg.ID,
+		arg.AllowLate,
+		arg.LateGraceMinutes,
+		arg.LatePenaltyPerDay,
+		arg.LateCutoffAt,
+		arg.UpdatedAt,
+	)
+	var i Homework
+	err := row.Scan(
+		&i.ID,
+		&i.TeacherID,
+		&i.Subject,
+		&i.Title,
+		&i.FileName,
+		&i.SavedPath,
+		&i.IsClosed,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+		&i.DueAt,
+		&i.AllowLate,
+		&i.LateGraceMinutes,
+		&i.LatePenaltyPerDay,
+		&i.LateCutoffAt,
+		&i.MaxScore,
+		&i.ClassID,
 	)
 	return i, err
 }
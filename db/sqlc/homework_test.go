@@ -17,6 +17,7 @@ func createRandomHomework(t *testing.T, teacherID int64, subject string) Homewor
 		Title:     util.RandomString(10),
 		FileName:  util.RandomString(10),
 		SavedPath: util.RandomString(10),
+		MaxScore:  100,
 	}
 
 	homework, err := testQueries.CreateHomework(context.Background(), arg)
@@ -30,6 +31,7 @@ func createRandomHomework(t *testing.T, teacherID int64, subject string) Homewor
 	require.Equal(t, arg.FileName, homework.FileName)
 	require.Equal(t, arg.SavedPath, homework.SavedPath)
 	require.False(t, homework.IsClosed)
+	require.Equal(t, arg.MaxScore, homework.MaxScore)
 
 	require.NotZero(t, homework.CreatedAt)
 	require.True(t, homework.UpdatedAt.IsZero())
@@ -229,3 +231,110 @@ func TestCloseHomework(t *testing.T) {
 	testQueries.DeleteHomework(context.Background(), homework2.ID)
 	testQueries.DeleteUser(context.Background(), teacher.ID)
 }
+
+func TestUpdateHomeworkDueAt(t *testing.T) {
+	teacher := createRandomTeacher(t)
+	subject := util.RandomSubject()
+	homework1 := createRandomHomework(t, teacher.ID, subject)
+	require.False(t, homework1.DueAt.Valid)
+
+	homework1, err := testQueries.CloseHomework(context.Background(), CloseHomeworkParams{
+		ID:       homework1.ID,
+		IsClosed: true,
+		ClosedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	arg := UpdateHomeworkDueAtParams{
+		ID:        homework1.ID,
+		DueAt:     sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		UpdatedAt: time.Now(),
+	}
+
+	homework2, err := testQueries.UpdateHomeworkDueAt(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, homework1.ID, homework2.ID)
+	require.Equal(t, homework1.FileName, homework2.FileName)
+	require.True(t, homework2.DueAt.Valid)
+	require.WithinDuration(t, arg.DueAt.Time, homework2.DueAt.Time, time.Second)
+	require.WithinDuration(t, arg.UpdatedAt, homework2.UpdatedAt, time.Second)
+	// the new due date reopens the homework
+	require.False(t, homework2.IsClosed)
+	require.True(t, homework2.ClosedAt.Equal(time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	testQueries.DeleteHomework(context.Background(), homework2.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+}
+
+func TestUpdateHomeworkLatePolicy(t *testing.T) {
+	teacher := createRandomTeacher(t)
+	subject := util.RandomSubject()
+	homework1 := createRandomHomework(t, teacher.ID, subject)
+	require.False(t, homework1.AllowLate)
+
+	arg := UpdateHomeworkLatePolicyParams{
+		ID:                homework1.ID,
+		AllowLate:         true,
+		LateGraceMinutes:  30,
+		LatePenaltyPerDay: 10,
+		LateCutoffAt:      sql.NullTime{Time: time.Now().Add(72 * time.Hour), Valid: true},
+		UpdatedAt:         time.Now(),
+	}
+
+	homework2, err := testQueries.UpdateHomeworkLatePolicy(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, homework1.ID, homework2.ID)
+	require.True(t, homework2.AllowLate)
+	require.Equal(t, arg.LateGraceMinutes, homework2.LateGraceMinutes)
+	require.Equal(t, arg.LatePenaltyPerDay, homework2.LatePenaltyPerDay)
+	require.WithinDuration(t, arg.LateCutoffAt.Time, homework2.LateCutoffAt.Time, time.Second)
+
+	arg.LatePenaltyPerDay = 101
+	_, err = testQueries.UpdateHomeworkLatePolicy(context.Background(), arg)
+	require.Error(t, err)
+
+	testQueries.DeleteHomework(context.Background(), homework2.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+}
+
+func TestCloseOverdueHomeworks(t *testing.T) {
+	teacher := createRandomTeacher(t)
+	subject := util.RandomSubject()
+	overdue := createRandomHomework(t, teacher.ID, subject)
+	upcoming := createRandomHomework(t, teacher.ID, subject)
+
+	dueAt := time.Now().Add(-time.Hour)
+	_, err := testQueries.UpdateHomeworkDueAt(context.Background(), UpdateHomeworkDueAtParams{
+		ID:        overdue.ID,
+		DueAt:     sql.NullTime{Time: dueAt, Valid: true},
+		UpdatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.UpdateHomeworkDueAt(context.Background(), UpdateHomeworkDueAtParams{
+		ID:        upcoming.ID,
+		DueAt:     sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		UpdatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	homeworks, err := testQueries.CloseOverdueHomeworks(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	closedIDs := make(map[int64]Homework)
+	for _, homework := range homeworks {
+		require.True(t, homework.IsClosed)
+		closedIDs[homework.ID] = homework
+	}
+	require.Contains(t, closedIDs, overdue.ID)
+	require.NotContains(t, closedIDs, upcoming.ID)
+	require.WithinDuration(t, dueAt, closedIDs[overdue.ID].ClosedAt, time.Second)
+
+	homework, err := testQueries.GetHomework(context.Background(), upcoming.ID)
+	require.NoError(t, err)
+	require.False(t, homework.IsClosed)
+
+	testQueries.DeleteHomework(context.Background(), overdue.ID)
+	testQueries.DeleteHomework(context.Background(), upcoming.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomInvite(t *testing.T, createdBy int64, classID sql.NullInt64, maxUses int32) (Invite, string) {
+	code := util.RandomString(32)
+
+	role := util.RoleTeacher
+	if classID.Valid {
+		role = util.RoleStudent
+	}
+
+	arg := CreateInviteParams{
+		CodeHash:  util.HashSecret(code),
+		Role:      role,
+		ClassID:   classID,
+		CreatedBy: createdBy,
+		MaxUses:   maxUses,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	invite, err := testQueries.CreateInvite(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Role, invite.Role)
+	require.Equal(t, arg.ClassID, invite.ClassID)
+	require.Zero(t, invite.Uses)
+	require.True(t, invite.RevokedAt.IsZero())
+
+	return invite, code
+}
+
+func randomSignUp(code string) SignUpWithInviteTxParams {
+	return SignUpWithInviteTxParams{
+		User: CreateUserParams{
+			Username:       sql.NullString{String: util.RandomString(6), Valid: true},
+			HashedPassword: util.RandomString(20),
+			Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
+			Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
+			PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
+			Role:           util.RoleStudent,
+		},
+		CodeHash:   util.HashSecret(code),
+		RedeemedAt: time.Now(),
+	}
+}
+
+func TestSignUpWithInviteTx(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+
+	invite, code := createRandomInvite(t, admin.ID, sql.NullInt64{}, 1)
+
+	teacher, err := store.SignUpWithInviteTx(context.Background(), randomSignUp(code))
+	require.NoError(t, err)
+	require.Equal(t, util.RoleTeacher, teacher.Role)
+
+	redemptions, err := testQueries.ListInviteRedemptions(context.Background(), ListInviteRedemptionsParams{
+		InviteID: invite.ID,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, redemptions, 1)
+	require.Equal(t, teacher.ID, redemptions[0].UserID)
+
+	// the invite is used up, and the failed sign up creates no user
+	arg := randomSignUp(code)
+	_, err = store.SignUpWithInviteTx(context.Background(), arg)
+	require.EqualError(t, err, ErrInviteInvalid.Error())
+
+	_, err = testQueries.GetByUsername(context.Background(), arg.User.Username)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	class := createRandomClass(t, teacher.ID)
+	classInvite, classCode := createRandomInvite(t, teacher.ID, sql.NullInt64{Int64: class.ID, Valid: true}, 10)
+
+	student, err := store.SignUpWithInviteTx(context.Background(), randomSignUp(classCode))
+	require.NoError(t, err)
+	require.Equal(t, util.RoleStudent, student.Role)
+
+	_, err = testQueries.GetClassMember(context.Background(), GetClassMemberParams{
+		ClassID: class.ID,
+		UserID:  student.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.RevokeInvite(context.Background(), RevokeInviteParams{
+		ID:        classInvite.ID,
+		RevokedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	_, err = store.SignUpWithInviteTx(context.Background(), randomSignUp(classCode))
+	require.EqualError(t, err, ErrInviteInvalid.Error())
+
+	testQueries.DeleteClass(context.Background(), class.ID)
+	testQueries.DeleteUser(context.Background(), student.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+	testQueries.DeleteUser(context.Background(), admin.ID)
+}
+
+func TestJoinClassWithInviteTx(t *testing.T) {
+	store := NewStore(testDB)
+	teacher := createRandomUser(t)
+	student := createRandomUser(t)
+	class := createRandomClass(t, teacher.ID)
+
+	_, teacherCode := createRandomInvite(t, teacher.ID, sql.NullInt64{}, 1)
+	invite, code := createRandomInvite(t, teacher.ID, sql.NullInt64{Int64: class.ID, Valid: true}, 2)
+
+	// teacher invites are only redeemed at sign up
+	_, err := store.JoinClassWithInviteTx(context.Background(), JoinClassWithInviteTxParams{
+		CodeHash:   util.HashSecret(teacherCode),
+		UserID:     student.ID,
+		RedeemedAt: time.Now(),
+	})
+	require.EqualError(t, err, ErrInviteInvalid.Error())
+
+	arg := JoinClassWithInviteTxParams{
+		CodeHash:   util.HashSecret(code),
+		UserID:     student.ID,
+		RedeemedAt: time.Now(),
+	}
+
+	member, err := store.JoinClassWithInviteTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, class.ID, member.ClassID)
+	require.Equal(t, student.ID, member.UserID)
+
+	// joining twice fails and does not count as a use
+	_, err = store.JoinClassWithInviteTx(context.Background(), arg)
+	require.Error(t, err)
+
+	invite, err = testQueries.GetInvite(context.Background(), invite.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), invite.Uses)
+
+	testQueries.DeleteClass(context.Background(), class.ID)
+	testQueries.DeleteUser(context.Background(), student.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLoginFailure(t *testing.T) {
+	key := "username:" + util.RandomString(8)
+	now := time.Now()
+
+	for i := 1; i <= 3; i++ {
+		failure, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+			Key:          key,
+			LastFailedAt: now,
+			WindowStart:  now.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, key, failure.Key)
+		require.Equal(t, int32(i), failure.FailedCount)
+		require.True(t, failure.LockedUntil.IsZero())
+	}
+
+	// a failure after the window starts counting again
+	later := now.Add(2 * time.Hour)
+	failure, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+		Key:          key,
+		LastFailedAt: later,
+		WindowStart:  later.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failure.FailedCount)
+
+	err = testQueries.LockLogin(context.Background(), LockLoginParams{
+		Key:         key,
+		LockedUntil: later.Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	failure, err = testQueries.GetLoginFailure(context.Background(), key)
+	require.NoError(t, err)
+	require.WithinDuration(t, later.Add(time.Minute), failure.LockedUntil, time.Second)
+
+	err = testQueries.DeleteLoginFailure(context.Background(), key)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetLoginFailure(context.Background(), key)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestDeleteExpiredLoginFailures(t *testing.T) {
+	now := time.Now()
+	expiredKey := "username:" + util.RandomString(8)
+	lockedKey := "username:" + util.RandomString(8)
+
+	for _, key := range []string{expiredKey, lockedKey} {
+		_, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+			Key:          key,
+			LastFailedAt: now.Add(-2 * time.Hour),
+			WindowStart:  now.Add(-3 * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	// a lockout that is still running keeps its failures
+	err := testQueries.LockLogin(context.Background(), LockLoginParams{
+		Key:         lockedKey,
+		LockedUntil: now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	err = testQueries.DeleteExpiredLoginFailures(context.Background(), now.Add(-time.Hour))
+	require.NoError(t, err)
+
+	_, err = testQueries.GetLoginFailure(context.Background(), expiredKey)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = testQueries.GetLoginFailure(context.Background(), lockedKey)
+	require.NoError(t, err)
+
+	testQueries.DeleteLoginFailure(context.Background(), lockedKey)
+}
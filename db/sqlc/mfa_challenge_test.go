@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAChallenge(t *testing.T) {
+	user := createRandomUser(t)
+
+	mfaToken, err := util.NewSecret()
+	require.NoError(t, err)
+
+	arg := CreateMFAChallengeParams{
+		UserID:    user.ID,
+		TokenHash: util.HashSecret(mfaToken),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	challenge1, err := testQueries.CreateMFAChallenge(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserID, challenge1.UserID)
+	require.True(t, challenge1.UsedAt.IsZero())
+
+	challenge2, err := testQueries.GetMFAChallenge(context.Background(), GetMFAChallengeParams{
+		TokenHash: arg.TokenHash,
+		Now:       time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, challenge1.ID, challenge2.ID)
+
+	_, err = testQueries.GetMFAChallenge(context.Background(), GetMFAChallengeParams{
+		TokenHash: arg.TokenHash,
+		Now:       time.Now().Add(2 * time.Minute),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = testQueries.UseMFAChallenge(context.Background(), UseMFAChallengeParams{
+		ID:     challenge1.ID,
+		UsedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetMFAChallenge(context.Background(), GetMFAChallengeParams{
+		TokenHash: arg.TokenHash,
+		Now:       time.Now(),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+// runMigration applies a migration file again, the migrations that rewrite
+// rows are written so that this is a no-op for rows they already rewrote
+func runMigration(t *testing.T, name string) {
+	query, err := os.ReadFile("../migration/" + name)
+	require.NoError(t, err)
+
+	_, err = testDB.Exec(string(query))
+	require.NoError(t, err)
+}
+
+func TestStripSavedPathAsset(t *testing.T) {
+	teacher := createRandomTeacher(t)
+	student := createRandomStudent(t)
+
+	// rows saved before files were stored under keys have the asset path
+	homeworkName := util.RandomString(10) + ".pdf"
+	homework, err := testQueries.CreateHomework(context.Background(), CreateHomeworkParams{
+		TeacherID: teacher.ID,
+		Subject:   util.RandomSubject(),
+		Title:     util.RandomString(10),
+		FileName:  homeworkName,
+		SavedPath: "./asset/" + homeworkName,
+		MaxScore:  100,
+	})
+	require.NoError(t, err)
+
+	solutionName := util.RandomString(10) + ".txt"
+	solution, err := testQueries.CreateSolution(context.Background(), CreateSolutionParams{
+		ProblemID: homework.ID,
+		UserID:    student.ID,
+		FileName:  solutionName,
+		SavedPath: "./asset/" + solutionName,
+	})
+	require.NoError(t, err)
+
+	runMigration(t, "000020_strip_saved_path_asset.up.sql")
+
+	homework, err = testQueries.GetHomework(context.Background(), homework.ID)
+	require.NoError(t, err)
+	require.Equal(t, homeworkName, homework.SavedPath)
+
+	solution, err = testQueries.GetSolutionByID(context.Background(), solution.ID)
+	require.NoError(t, err)
+	require.Equal(t, solutionName, solution.SavedPath)
+
+	testQueries.DeleteSolution(context.Background(), solution.ID)
+	testQueries.DeleteHomework(context.Background(), homework.ID)
+	testQueries.DeleteUser(context.Background(), student.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+}
@@ -11,17 +11,88 @@ import (
 	"github.com/google/uuid"
 )
 
-type Homework struct {
+type Class struct {
 	ID        int64     `json:"id"`
 	TeacherID int64     `json:"teacher_id"`
-	Subject   string    `json:"subject"`
-	Title     string    `json:"title"`
-	FileName  string    `json:"file_name"`
-	SavedPath string    `json:"saved_path"`
-	IsClosed  bool      `json:"is_closed"`
+	Name      string    `json:"name"`
+	JoinCode  string    `json:"join_code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ClassMember struct {
+	ClassID  int64     `json:"class_id"`
+	UserID   int64     `json:"user_id"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type EmailVerification struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Email     string    `json:"email"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UsedAt    time.Time `json:"used_at"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	ClosedAt  time.Time `json:"closed_at"`
+}
+
+type Grade struct {
+	ID                int64     `json:"id"`
+	SolutionID        int64     `json:"solution_id"`
+	GraderID          int64     `json:"grader_id"`
+	Score             float64   `json:"score"`
+	Feedback          string    `json:"feedback"`
+	FeedbackFileName  string    `json:"feedback_file_name"`
+	FeedbackSavedPath string    `json:"feedback_saved_path"`
+	IsReleased        bool      `json:"is_released"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	ReleasedAt        time.Time `json:"released_at"`
+}
+
+type Homework struct {
+	ID                int64         `json:"id"`
+	TeacherID         int64         `json:"teacher_id"`
+	Subject           string        `json:"subject"`
+	Title             string        `json:"title"`
+	FileName          string        `json:"file_name"`
+	SavedPath         string        `json:"saved_path"`
+	IsClosed          bool          `json:"is_closed"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	ClosedAt          time.Time     `json:"closed_at"`
+	DueAt             sql.NullTime  `json:"due_at"`
+	AllowLate         bool          `json:"allow_late"`
+	LateGraceMinutes  int32         `json:"late_grace_minutes"`
+	LatePenaltyPerDay int32         `json:"late_penalty_per_day"`
+	LateCutoffAt      sql.NullTime  `json:"late_cutoff_at"`
+	MaxScore          float64       `json:"max_score"`
+	ClassID           sql.NullInt64 `json:"class_id"`
+}
+
+type Invite struct {
+	ID        int64         `json:"id"`
+	CodeHash  string        `json:"code_hash"`
+	Role      string        `json:"role"`
+	ClassID   sql.NullInt64 `json:"class_id"`
+	CreatedBy int64         `json:"created_by"`
+	MaxUses   int32         `json:"max_uses"`
+	Uses      int32         `json:"uses"`
+	ExpiresAt time.Time     `json:"expires_at"`
+	RevokedAt time.Time     `json:"revoked_at"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type InviteRedemption struct {
+	InviteID   int64     `json:"invite_id"`
+	UserID     int64     `json:"user_id"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
+
+type LoginFailure struct {
+	Key          string    `json:"key"`
+	FailedCount  int32     `json:"failed_count"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	LockedUntil  time.Time `json:"locked_until"`
 }
 
 type Message struct {
@@ -34,6 +105,49 @@ type Message struct {
 	ReadAt     time.Time `json:"read_at"`
 }
 
+type MfaChallenge struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UsedAt    time.Time `json:"used_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OidcLogin struct {
+	ID           int64     `json:"id"`
+	StateHash    string    `json:"state_hash"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	UsedAt       time.Time `json:"used_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type PasswordHistory struct {
+	ID             int64     `json:"id"`
+	UserID         int64     `json:"user_id"`
+	HashedPassword string    `json:"hashed_password"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type PasswordReset struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UsedAt    time.Time `json:"used_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	CodeHash  string    `json:"code_hash"`
+	UsedAt    time.Time `json:"used_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Session struct {
 	ID           uuid.UUID `json:"id"`
 	Username     string    `json:"username"`
@@ -43,16 +157,27 @@ type Session struct {
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreateAt     time.Time `json:"create_at"`
+	FamilyID     uuid.UUID `json:"family_id"`
+	RotatedAt    time.Time `json:"rotated_at"`
 }
 
 type Solution struct {
-	ID         int64     `json:"id"`
-	ProblemID  int64     `json:"problem_id"`
-	UserID     int64     `json:"user_id"`
-	FileName   string    `json:"file_name"`
-	SavedPath  string    `json:"saved_path"`
-	SubmitedAt time.Time `json:"submited_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID          int64     `json:"id"`
+	ProblemID   int64     `json:"problem_id"`
+	UserID      int64     `json:"user_id"`
+	FileName    string    `json:"file_name"`
+	SavedPath   string    `json:"saved_path"`
+	SubmitedAt  time.Time `json:"submited_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	IsLate      bool      `json:"is_late"`
+	LateSeconds int64     `json:"late_seconds"`
+	LatePenalty int32     `json:"late_penalty"`
+}
+
+type Subject struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type User struct {
@@ -64,5 +189,19 @@ type User struct {
 	PhoneNumber       sql.NullString `json:"phone_number"`
 	PasswordChangedAt time.Time      `json:"password_changed_at"`
 	CreatedAt         time.Time      `json:"created_at"`
-	IsTeacher         bool           `json:"is_teacher"`
+	Role              string         `json:"role"`
+	EmailVerifiedAt   time.Time      `json:"email_verified_at"`
+	TotpSecret        string         `json:"totp_secret"`
+	TotpEnabledAt     time.Time      `json:"totp_enabled_at"`
+	TotpLastStep      int64          `json:"totp_last_step"`
+	DeletedAt         time.Time      `json:"deleted_at"`
+}
+
+type UserIdentity struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
 }
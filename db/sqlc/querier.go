@@ -7,45 +7,126 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddClassMember(ctx context.Context, arg AddClassMemberParams) (ClassMember, error)
+	AddPasswordHistory(ctx context.Context, id int64) error
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error
 	CloseHomework(ctx context.Context, arg CloseHomeworkParams) (Homework, error)
+	CloseOverdueHomeworks(ctx context.Context, now time.Time) ([]Homework, error)
+	CreateClass(ctx context.Context, arg CreateClassParams) (Class, error)
+	CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) (EmailVerification, error)
+	CreateGrade(ctx context.Context, arg CreateGradeParams) (Grade, error)
 	CreateHomework(ctx context.Context, arg CreateHomeworkParams) (Homework, error)
+	CreateInvite(ctx context.Context, arg CreateInviteParams) (Invite, error)
+	CreateInviteRedemption(ctx context.Context, arg CreateInviteRedemptionParams) (InviteRedemption, error)
+	CreateMFAChallenge(ctx context.Context, arg CreateMFAChallengeParams) (MfaChallenge, error)
 	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
+	CreateOIDCLogin(ctx context.Context, arg CreateOIDCLoginParams) (OidcLogin, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateSolution(ctx context.Context, arg CreateSolutionParams) (Solution, error)
+	CreateSubject(ctx context.Context, name string) (Subject, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+	DeactivateUser(ctx context.Context, arg DeactivateUserParams) (User, error)
+	DeleteClass(ctx context.Context, id int64) error
+	DeleteExpiredLoginFailures(ctx context.Context, before time.Time) error
+	DeleteGrade(ctx context.Context, id int64) error
 	DeleteHomework(ctx context.Context, id int64) error
+	DeleteLoginFailure(ctx context.Context, key string) error
 	DeleteMessage(ctx context.Context, id int64) error
+	DeletePasswordResetsByUser(ctx context.Context, userID int64) error
+	DeleteRecoveryCodesByUser(ctx context.Context, userID int64) error
 	DeleteSession(ctx context.Context, id uuid.UUID) error
+	DeleteSessionFamily(ctx context.Context, familyID uuid.UUID) error
+	DeleteSessionsByUsername(ctx context.Context, username string) error
 	DeleteSolution(ctx context.Context, id int64) error
+	DeleteSubject(ctx context.Context, id int64) error
 	DeleteUser(ctx context.Context, id int64) error
+	DisableUserTOTP(ctx context.Context, id int64) (User, error)
+	EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error)
 	GetByUsername(ctx context.Context, username sql.NullString) (User, error)
+	GetClass(ctx context.Context, id int64) (Class, error)
+	GetClassByJoinCode(ctx context.Context, joinCode string) (Class, error)
+	GetClassMember(ctx context.Context, arg GetClassMemberParams) (ClassMember, error)
+	GetGradeBySolution(ctx context.Context, solutionID int64) (Grade, error)
 	GetHomework(ctx context.Context, id int64) (Homework, error)
+	GetInvite(ctx context.Context, id int64) (Invite, error)
+	GetLoginFailure(ctx context.Context, key string) (LoginFailure, error)
+	GetMFAChallenge(ctx context.Context, arg GetMFAChallengeParams) (MfaChallenge, error)
 	GetMessage(ctx context.Context, id int64) (Message, error)
+	GetPasswordReset(ctx context.Context, arg GetPasswordResetParams) (PasswordReset, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSessionAuth(ctx context.Context, id uuid.UUID) (GetSessionAuthRow, error)
 	GetSolutionByID(ctx context.Context, id int64) (Solution, error)
 	GetSolutionByProblemAndUser(ctx context.Context, arg GetSolutionByProblemAndUserParams) (Solution, error)
+	GetSubject(ctx context.Context, id int64) (Subject, error)
 	GetUser(ctx context.Context, id int64) (User, error)
+	GetUserByEmail(ctx context.Context, email sql.NullString) (User, error)
+	GetUserByPhoneNumber(ctx context.Context, phoneNumber sql.NullString) (User, error)
 	GetUserForUpdate(ctx context.Context, id int64) (User, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	ListAllUsers(ctx context.Context, arg ListAllUsersParams) ([]User, error)
+	ListClassMembers(ctx context.Context, arg ListClassMembersParams) ([]User, error)
+	ListClassMembersByTeacher(ctx context.Context, teacherID int64) ([]ClassMember, error)
+	ListClassesForUser(ctx context.Context, arg ListClassesForUserParams) ([]Class, error)
+	ListGradebookEntries(ctx context.Context, arg ListGradebookEntriesParams) ([]ListGradebookEntriesRow, error)
+	ListGradebookHomeworks(ctx context.Context, arg ListGradebookHomeworksParams) ([]Homework, error)
+	ListGradebookStudents(ctx context.Context, teacherID int64) ([]User, error)
 	ListHomeworks(ctx context.Context, arg ListHomeworksParams) ([]Homework, error)
 	ListHomeworksBySubject(ctx context.Context, arg ListHomeworksBySubjectParams) ([]Homework, error)
+	ListHomeworksBySubjectForUser(ctx context.Context, arg ListHomeworksBySubjectForUserParams) ([]Homework, error)
 	ListHomeworksByTeacher(ctx context.Context, arg ListHomeworksByTeacherParams) ([]Homework, error)
+	ListHomeworksForUser(ctx context.Context, arg ListHomeworksForUserParams) ([]Homework, error)
+	ListInviteRedemptions(ctx context.Context, arg ListInviteRedemptionsParams) ([]InviteRedemption, error)
+	ListInvites(ctx context.Context, arg ListInvitesParams) ([]Invite, error)
+	ListInvitesByCreator(ctx context.Context, arg ListInvitesByCreatorParams) ([]Invite, error)
 	ListMessages(ctx context.Context, arg ListMessagesParams) ([]Message, error)
 	ListMessagesFromUser(ctx context.Context, arg ListMessagesFromUserParams) ([]Message, error)
 	ListMessagesToUser(ctx context.Context, arg ListMessagesToUserParams) ([]Message, error)
+	ListPasswordHistory(ctx context.Context, arg ListPasswordHistoryParams) ([]PasswordHistory, error)
+	ListSessionsByUsername(ctx context.Context, username string) ([]Session, error)
 	ListSolutionsByProblem(ctx context.Context, arg ListSolutionsByProblemParams) ([]Solution, error)
 	ListSolutionsByUser(ctx context.Context, arg ListSolutionsByUserParams) ([]Solution, error)
+	ListSubjects(ctx context.Context) ([]Subject, error)
+	ListUserFiles(ctx context.Context, userID int64) ([]string, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	LockLogin(ctx context.Context, arg LockLoginParams) error
+	PrunePasswordHistory(ctx context.Context, arg PrunePasswordHistoryParams) error
+	ReactivateUser(ctx context.Context, id int64) (User, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginFailure, error)
+	ReleaseGrade(ctx context.Context, arg ReleaseGradeParams) (Grade, error)
+	RemoveClassMember(ctx context.Context, arg RemoveClassMemberParams) error
+	RevokeInvite(ctx context.Context, arg RevokeInviteParams) (Invite, error)
+	RotateSession(ctx context.Context, arg RotateSessionParams) (Session, error)
+	SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) (User, error)
+	UpdateClassJoinCode(ctx context.Context, arg UpdateClassJoinCodeParams) (Class, error)
+	UpdateGrade(ctx context.Context, arg UpdateGradeParams) (Grade, error)
 	UpdateHomework(ctx context.Context, arg UpdateHomeworkParams) (Homework, error)
+	UpdateHomeworkClass(ctx context.Context, arg UpdateHomeworkClassParams) (Homework, error)
+	UpdateHomeworkDueAt(ctx context.Context, arg UpdateHomeworkDueAtParams) (Homework, error)
+	UpdateHomeworkLatePolicy(ctx context.Context, arg UpdateHomeworkLatePolicyParams) (Homework, error)
 	UpdateMessage(ctx context.Context, arg UpdateMessageParams) (Message, error)
 	UpdateMessageState(ctx context.Context, arg UpdateMessageStateParams) (Message, error)
 	UpdateSolution(ctx context.Context, arg UpdateSolutionParams) (Solution, error)
+	UpdateSubject(ctx context.Context, arg UpdateSubjectParams) (Subject, error)
 	UpdateUserInfo(ctx context.Context, arg UpdateUserInfoParams) (User, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UseEmailVerification(ctx context.Context, arg UseEmailVerificationParams) (EmailVerification, error)
+	UseInvite(ctx context.Context, arg UseInviteParams) (Invite, error)
+	UseMFAChallenge(ctx context.Context, arg UseMFAChallengeParams) (MfaChallenge, error)
+	UseOIDCLogin(ctx context.Context, arg UseOIDCLoginParams) (OidcLogin, error)
+	UsePasswordReset(ctx context.Context, arg UsePasswordResetParams) (PasswordReset, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (User, error)
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
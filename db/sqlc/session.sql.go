@@ -12,6 +12,17 @@ import (
 	"github.com/google/uuid"
 )
 
+const blockSessionFamily = `-- name: BlockSessionFamily :exec
+UPDATE sessions
+SET is_blocked = true
+WHERE family_id = $1
+`
+
+func (q *Queries) BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, blockSessionFamily, familyID)
+	return err
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
     id,
@@ -20,10 +31,11 @@ INSERT INTO sessions (
     user_agent,
     client_ip,
     is_blocked,
-    expires_at
+    expires_at,
+    family_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, create_at
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, create_at, family_id, rotated_at
 `
 
 type CreateSessionParams struct {
@@ -34,6 +46,7 @@ type CreateSessionParams struct {
 	ClientIp     string    `json:"client_ip"`
 	IsBlocked    bool      `json:"is_blocked"`
 	ExpiresAt    time.Time `json:"expires_at"`
+	FamilyID     uuid.UUID `json:"family_id"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
@@ -45,6 +58,7 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		arg.ClientIp,
 		arg.IsBlocked,
 		arg.ExpiresAt,
+		arg.FamilyID,
 	)
 	var i Session
 	err := row.Scan(
@@ -56,6 +70,8 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreateAt,
+		&i.FamilyID,
+		&i.RotatedAt,
 	)
 	return i, err
 }
@@ -70,8 +86,28 @@ func (q *Queries) DeleteSession(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteSessionFamily = `-- name: DeleteSessionFamily :exec
+DELETE FROM sessions
+WHERE family_id = $1
+`
+
+func (q *Queries) DeleteSessionFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionFamily, familyID)
+	return err
+}
+
+const deleteSessionsByUsername = `-- name: DeleteSessionsByUsername :exec
+DELETE FROM sessions
+WHERE username = $1
+`
+
+func (q *Queries) DeleteSessionsByUsername(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionsByUsername, username)
+	return err
+}
+
 const getSession = `-- name: GetSession :one
-SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, create_at FROM sessions
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, create_at, family_id, rotated_at FROM sessions
 WHERE id = $1 LIMIT 1
 `
 
@@ -87,6 +123,114 @@ func (q *Queries) GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 		&i.IsBlocked,
 		&i.ExpiresAt,
 		&i.CreateAt,
+		&i.FamilyID,
+		&i.RotatedAt,
+	)
+	return i, err
+}
+
+const getSessionAuth = `-- name: GetSessionAuth :one
+SELECT s.id, s.username, s.family_id, s.is_blocked, s.expires_at, u.id AS user_id, u.role, u.password_changed_at
+FROM sessions s
+JOIN users u ON u.username = s.username
+WHERE s.id = $1 LIMIT 1
+`
+
+type GetSessionAuthRow struct {
+	ID                uuid.UUID `json:"id"`
+	Username          string    `json:"username"`
+	FamilyID          uuid.UUID `json:"family_id"`
+	IsBlocked         bool      `json:"is_blocked"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	UserID            int64     `json:"user_id"`
+	Role              string    `json:"role"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+}
+
+func (q *Queries) GetSessionAuth(ctx context.Context, id uuid.UUID) (GetSessionAuthRow, error) {
+	row := q.db.QueryRowContext(ctx, getSessionAuth, id)
+	var i GetSessionAuthRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FamilyID,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.UserID,
+		&i.Role,
+		&i.PasswordChangedAt,
+	)
+	return i, err
+}
+
+const listSessionsByUsername = `-- name: ListSessionsByUsername :many
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, create_at, family_id, rotated_at FROM sessions
+WHERE username = $1
+    AND expires_at > now()
+    AND rotated_at = '0001-01-01 00:00:00Z'
+ORDER BY create_at DESC
+`
+
+func (q *Queries) ListSessionsByUsername(ctx context.Context, username string) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionsByUsername, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreateAt,
+			&i.FamilyID,
+			&i.RotatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rotateSession = `-- name: RotateSession :one
+UPDATE sessions
+SET rotated_at = $2
+WHERE id = $1 AND rotated_at = '0001-01-01 00:00:00Z'
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, create_at, family_id, rotated_at
+`
+
+type RotateSessionParams struct {
+	ID        uuid.UUID `json:"id"`
+	RotatedAt time.Time `json:"rotated_at"`
+}
+
+func (q *Queries) RotateSession(ctx context.Context, arg RotateSessionParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, rotateSession, arg.ID, arg.RotatedAt)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreateAt,
+		&i.FamilyID,
+		&i.RotatedAt,
 	)
 	return i, err
 }
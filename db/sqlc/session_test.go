@@ -20,6 +20,7 @@ func createRandomSession(t *testing.T, username sql.NullString) Session {
 		ClientIp:     util.RandomString(10),
 		IsBlocked:    util.RandomBoolean(),
 		ExpiresAt:    time.Now().Add(time.Hour * 24),
+		FamilyID:     uuid.New(),
 	}
 
 	session, err := testQueries.CreateSession(context.Background(), arg)
@@ -31,6 +32,8 @@ func createRandomSession(t *testing.T, username sql.NullString) Session {
 	require.Equal(t, arg.ClientIp, session.ClientIp)
 	require.Equal(t, arg.IsBlocked, session.IsBlocked)
 	require.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Second)
+	require.Equal(t, arg.FamilyID, session.FamilyID)
+	require.True(t, session.RotatedAt.IsZero())
 	return session
 }
 
@@ -71,3 +74,48 @@ func TestDeleteSession(t *testing.T) {
 	require.Error(t, err)
 	testQueries.DeleteUser(context.Background(), user.ID)
 }
+
+func TestListAndBlockSessions(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+	session2 := createRandomSession(t, user.Username)
+
+	sessions, err := testQueries.ListSessionsByUsername(context.Background(), user.Username.String)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	err = testQueries.BlockSessionFamily(context.Background(), session1.FamilyID)
+	require.NoError(t, err)
+
+	session3, err := testQueries.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.True(t, session3.IsBlocked)
+
+	err = testQueries.DeleteSessionsByUsername(context.Background(), user.Username.String)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetSession(context.Background(), session2.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
+
+func TestGetSessionAuth(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user.Username)
+
+	auth, err := testQueries.GetSessionAuth(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.Equal(t, session.ID, auth.ID)
+	require.Equal(t, session.FamilyID, auth.FamilyID)
+	require.Equal(t, user.ID, auth.UserID)
+	require.Equal(t, user.Role, auth.Role)
+	require.WithinDuration(t, user.PasswordChangedAt, auth.PasswordChangedAt, time.Second)
+
+	// sessions go away with their user
+	err = testQueries.DeleteUser(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetSessionAuth(context.Background(), session.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -15,17 +16,23 @@ INSERT INTO solutions (
     problem_id,
     user_id,
     file_name,
-    saved_path
+    saved_path,
+    is_late,
+    late_seconds,
+    late_penalty
 ) VALUES (
-    $1, $2, $3, $4
-) RETURNING id, problem_id, user_id, file_name, saved_path, submited_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, problem_id, user_id, file_name, saved_path, submited_at, updated_at, is_late, late_seconds, late_penalty
 `
 
 type CreateSolutionParams struct {
-	ProblemID int64  `json:"problem_id"`
-	UserID    int64  `json:"user_id"`
-	FileName  string `json:"file_name"`
-	SavedPath string `json:"saved_path"`
+	ProblemID   int64  `json:"problem_id"`
+	UserID      int64  `json:"user_id"`
+	FileName    string `json:"file_name"`
+	SavedPath   string `json:"saved_path"`
+	IsLate      bool   `json:"is_late"`
+	LateSeconds int64  `json:"late_seconds"`
+	LatePenalty int32  `json:"late_penalty"`
 }
 
 func (q *Queries) CreateSolution(ctx context.Context, arg CreateSolutionParams) (Solution, error) {
@@ -34,6 +41,9 @@ func (q *Queries) CreateSolution(ctx context.Context, arg CreateSolutionParams)
 		arg.UserID,
 		arg.FileName,
 		arg.SavedPath,
+		arg.IsLate,
+		arg.LateSeconds,
+		arg.LatePenalty,
 	)
 	var i Solution
 	err := row.Scan(
@@ -44,6 +54,9 @@ func (q *Queries) CreateSolution(ctx context.Context, arg CreateSolutionParams)
 		&i.SavedPath,
 		&i.SubmitedAt,
 		&i.UpdatedAt,
+		&i.IsLate,
+		&i.LateSeconds,
+		&i.LatePenalty,
 	)
 	return i, err
 }
@@ -59,7 +72,7 @@ func (q *Queries) DeleteSolution(ctx context.Context, id int64) error {
 }
 
 const getSolutionByID = `-- name: GetSolutionByID :one
-SELECT id, problem_id, user_id, file_name, saved_path, submited_at, updated_at FROM solutions
+SELECT id, problem_id, user_id, file_name, saved_path, submited_at, updated_at, is_late, late_seconds, late_penalty FROM solutions
 WHERE id = $1
 LIMIT 1
 `
@@ -75,12 +88,15 @@ func (q *Queries) GetSolutionByID(ctx context.Context, id int64) (Solution, erro
 		&i.SavedPath,
 		&i.SubmitedAt,
 		&i.UpdatedAt,
+		&i.IsLate,
+		&i.LateSeconds,
+		&i.LatePenalty,
 	)
 	return i, err
 }
 
 const getSolutionByProblemAndUser = `-- name: GetSolutionByProblemAndUser :one
-SELECT id, problem_id, user_id, file_name, saved_path, submited_at, updated_at FROM solutions
+SELECT id, problem_id, user_id, file_name, saved_path, submited_at, updated_at, is_late, late_seconds, late_penalty FROM solutions
 WHERE problem_id = $1 AND user_id = $2
 LIMIT 1
 `
@@ -101,12 +117,85 @@ func (q *Queries) GetSolutionByProblemAndUser(ctx context.Context, arg GetSoluti
 		&i.SavedPath,
 		&i.SubmitedAt,
 		&i.UpdatedAt,
+		&i.IsLate,
+		&i.LateSeconds,
+		&i.LatePenalty,
 	)
 	return i, err
 }
 
+const listGradebookEntries = `-- name: ListGradebookEntries :many
+SELECT s.id, s.problem_id, s.user_id, s.submited_at, s.is_late, s.late_seconds, s.late_penalty,
+    g.score, g.is_released
+FROM solutions s
+JOIN homeworks h ON h.id = s.problem_id
+LEFT JOIN grades g ON g.solution_id = s.id
+WHERE h.teacher_id = $1
+    AND ($2::varchar IS NULL OR h.subject = $2)
+    AND ($3::timestamptz IS NULL OR h.created_at >= $3)
+    AND ($4::timestamptz IS NULL OR h.created_at < $4)
+ORDER BY s.user_id, s.problem_id
+`
+
+type ListGradebookEntriesParams struct {
+	TeacherID   int64          `json:"teacher_id"`
+	Subject     sql.NullString `json:"subject"`
+	CreatedFrom sql.NullTime   `json:"created_from"`
+	CreatedTo   sql.NullTime   `json:"created_to"`
+}
+
+type ListGradebookEntriesRow struct {
+	ID          int64           `json:"id"`
+	ProblemID   int64           `json:"problem_id"`
+	UserID      int64           `json:"user_id"`
+	SubmitedAt  time.Time       `json:"submited_at"`
+	IsLate      bool            `json:"is_late"`
+	LateSeconds int64           `json:"late_seconds"`
+	LatePenalty int32           `json:"late_penalty"`
+	Score       sql.NullFloat64 `json:"score"`
+	IsReleased  sql.NullBool    `json:"is_released"`
+}
+
+func (q *Queries) ListGradebookEntries(ctx context.Context, arg ListGradebookEntriesParams) ([]ListGradebookEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listGradebookEntries,
+		arg.TeacherID,
+		arg.Subject,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListGradebookEntriesRow{}
+	for rows.Next() {
+		var i ListGradebookEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProblemID,
+			&i.UserID,
+			&i.SubmitedAt,
+			&i.IsLate,
+			&i.LateSeconds,
+			&i.LatePenalty,
+			&i.Score,
+			&i.IsReleased,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSolutionsByProblem = `-- name: ListSolutionsByProblem :many
-SELECT id, problem_id, user_id, file_name, saved_path, submited_at, updated_at FROM solutions
+SELECT id, problem_id, user_id, file_name, saved_path, submited_at, updated_at, is_late, late_seconds, late_penalty FROM solutions
 WHERE problem_id = $1
 ORDER BY id
 LIMIT $2
@@ -136,6 +225,9 @@ func (q *Queries) ListSolutionsByProblem(ctx context.Context, arg ListSolutionsB
 			&i.SavedPath,
 			&i.SubmitedAt,
 			&i.UpdatedAt,
+			&i.IsLate,
+			&i.LateSeconds,
+			&i.LatePenalty,
 		); err != nil {
 			return nil, err
 		}
@@ -151,7 +243,7 @@ func (q *Queries) ListSolutionsByProblem(ctx context.Context, arg ListSolutionsB
 }
 
 const listSolutionsByUser = `-- name: ListSolutionsByUser :many
-SELECT id, problem_id, user_id, file_name, saved_path, submited_at, updated_at FROM solutions
+SELECT id, problem_id, user_id, file_name, saved_path, submited_at, updated_at, is_late, late_seconds, late_penalty FROM solutions
 WHERE user_id = $1
 ORDER BY id
 LIMIT $2
@@ -181,6 +273,9 @@ func (q *Queries) ListSolutionsByUser(ctx context.Context, arg ListSolutionsByUs
 			&i.SavedPath,
 			&i.SubmitedAt,
 			&i.UpdatedAt,
+			&i.IsLate,
+			&i.LateSeconds,
+			&i.LatePenalty,
 		); err != nil {
 			return nil, err
 		}
@@ -199,16 +294,22 @@ const updateSolution = `-- name: UpdateSolution :one
 UPDATE solutions
 SET file_name = $2,
     saved_path = $3,
-    updated_at = $4
+    updated_at = $4,
+    is_late = $5,
+    late_seconds = $6,
+    late_penalty = $7
 WHERE id = $1
-RETURNING id, problem_id, user_id, file_name, saved_path, submited_at, updated_at
+RETURNING id, problem_id, user_id, file_name, saved_path, submited_at, updated_at, is_late, late_seconds, late_penalty
 `
 
 type UpdateSolutionParams struct {
-	ID        int64     `json:"id"`
-	FileName  string    `json:"file_name"`
-	SavedPath string    `json:"saved_path"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          int64     `json:"id"`
+	FileName    string    `json:"file_name"`
+	SavedPath   string    `json:"saved_path"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	IsLate      bool      `json:"is_late"`
+	LateSeconds int64     `json:"late_seconds"`
+	LatePenalty int32     `json:"late_penalty"`
 }
 
 func (q *Queries) UpdateSolution(ctx context.Context, arg UpdateSolutionParams) (Solution, error) {
@@ -217,6 +318,9 @@ func (q *Queries) UpdateSolution(ctx context.Context, arg UpdateSolutionParams)
 		arg.FileName,
 		arg.SavedPath,
 		arg.UpdatedAt,
+		arg.IsLate,
+		arg.LateSeconds,
+		arg.LatePenalty,
 	)
 	var i Solution
 	err := row.Scan(
@@ -227,6 +331,9 @@ func (q *Queries) UpdateSolution(ctx context.Context, arg UpdateSolutionParams)
 		&i.SavedPath,
 		&i.SubmitedAt,
 		&i.UpdatedAt,
+		&i.IsLate,
+		&i.LateSeconds,
+		&i.LatePenalty,
 	)
 	return i, err
 }
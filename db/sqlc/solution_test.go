@@ -29,6 +29,7 @@ func createRandomSolution(t *testing.T, userID, homeworkID int64) Solution {
 	require.Equal(t, arg.SavedPath, solution.SavedPath)
 	require.NotZero(t, solution.SubmitedAt)
 	require.True(t, solution.UpdatedAt.IsZero())
+	require.False(t, solution.IsLate)
 
 	return solution
 }
@@ -47,6 +48,36 @@ func TestCreateSolution(t *testing.T) {
 	testQueries.DeleteUser(context.Background(), user.ID)
 }
 
+func TestCreateSolutionsWithSameFileName(t *testing.T) {
+	teacher := createRandomTeacher(t)
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	subject := util.RandomSubject()
+	homework := createRandomHomework(t, teacher.ID, subject)
+
+	fileName := util.RandomString(6)
+	solutions := make([]Solution, 0, 2)
+	for _, user := range []User{user1, user2} {
+		solution, err := testQueries.CreateSolution(context.Background(), CreateSolutionParams{
+			ProblemID: homework.ID,
+			UserID:    user.ID,
+			FileName:  fileName,
+			SavedPath: util.RandomString(6),
+		})
+		require.NoError(t, err)
+		require.Equal(t, fileName, solution.FileName)
+		solutions = append(solutions, solution)
+	}
+
+	for _, solution := range solutions {
+		testQueries.DeleteSolution(context.Background(), solution.ID)
+	}
+	testQueries.DeleteHomework(context.Background(), homework.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+	testQueries.DeleteUser(context.Background(), user1.ID)
+	testQueries.DeleteUser(context.Background(), user2.ID)
+}
+
 func TestGetSolutionByID(t *testing.T) {
 	teacher := createRandomTeacher(t)
 	user := createRandomUser(t)
@@ -198,10 +229,13 @@ func TestUpdateSolution(t *testing.T) {
 	solution1 := createRandomSolution(t, user.ID, homework.ID)
 
 	arg := UpdateSolutionParams{
-		ID:        solution1.ID,
-		FileName:  util.RandomString(10),
-		SavedPath: util.RandomString(10),
-		UpdatedAt: time.Now(),
+		ID:          solution1.ID,
+		FileName:    util.RandomString(10),
+		SavedPath:   util.RandomString(10),
+		UpdatedAt:   time.Now(),
+		IsLate:      true,
+		LateSeconds: 90000,
+		LatePenalty: 20,
 	}
 
 	solution2, err := testQueries.UpdateSolution(context.Background(), arg)
@@ -215,6 +249,9 @@ func TestUpdateSolution(t *testing.T) {
 	require.Equal(t, arg.SavedPath, solution2.SavedPath)
 	require.WithinDuration(t, solution1.SubmitedAt, solution2.SubmitedAt, time.Second)
 	require.WithinDuration(t, arg.UpdatedAt, solution2.UpdatedAt, time.Second)
+	require.True(t, solution2.IsLate)
+	require.Equal(t, arg.LateSeconds, solution2.LateSeconds)
+	require.Equal(t, arg.LatePenalty, solution2.LatePenalty)
 
 	testQueries.DeleteSolution(context.Background(), solution1.ID)
 	testQueries.DeleteHomework(context.Background(), homework.ID)
@@ -3,12 +3,30 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Store interface {
 	Querier
 	UpdateUserInfoTx(ctx context.Context, arg UpdateUserInfoTxParams) (UpdateUserInfoTxResult, error)
+	UpdateUserPasswordTx(ctx context.Context, arg UpdateUserPasswordTxParams) (User, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (User, error)
+	EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (User, error)
+	DisableTOTPTx(ctx context.Context, userID int64) (User, error)
+	ReplaceRecoveryCodesTx(ctx context.Context, arg ReplaceRecoveryCodesTxParams) error
+	ProvisionOIDCUserTx(ctx context.Context, arg ProvisionOIDCUserTxParams) (User, error)
+	SignUpWithInviteTx(ctx context.Context, arg SignUpWithInviteTxParams) (User, error)
+	JoinClassWithInviteTx(ctx context.Context, arg JoinClassWithInviteTxParams) (ClassMember, error)
+	ImportUsersTx(ctx context.Context, arg ImportUsersTxParams) ([]User, error)
+	UpdateHomeworkTx(ctx context.Context, arg UpdateHomeworkTxParams) (Homework, error)
+	DeactivateUserTx(ctx context.Context, arg DeactivateUserParams) (User, error)
+	PurgeUserTx(ctx context.Context, userID int64) ([]string, error)
 }
 
 type SQLStore struct {
@@ -79,3 +97,555 @@ func (store *SQLStore) UpdateUserInfoTx(ctx context.Context, arg UpdateUserInfoT
 
 	return result, err
 }
+
+type UpdateUserPasswordTxParams struct {
+	ID                int64     `json:"id"`
+	HashedPassword    string    `json:"hashed_password"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	KeepHistory       int32     `json:"keep_history"`
+}
+
+// UpdateUserPasswordTx changes the password and deletes every session of the
+// user, so refresh tokens issued with the old password stop working. The old
+// password is kept in the history of the user.
+func (store *SQLStore) UpdateUserPasswordTx(ctx context.Context, arg UpdateUserPasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := recordPasswordHistory(ctx, q, arg.ID, arg.KeepHistory)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			ID:                arg.ID,
+			HashedPassword:    arg.HashedPassword,
+			PasswordChangedAt: arg.PasswordChangedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteSessionsByUsername(ctx, user.Username.String)
+	})
+
+	return user, err
+}
+
+// recordPasswordHistory adds the current password of the user to its history
+// before it is changed, keeping only the newest keep ones
+func recordPasswordHistory(ctx context.Context, q *Queries, userID int64, keep int32) error {
+	err := q.AddPasswordHistory(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return q.PrunePasswordHistory(ctx, PrunePasswordHistoryParams{
+		UserID: userID,
+		Limit:  keep,
+	})
+}
+
+// ErrSessionRotated is returned when the refresh token of a session was already exchanged
+var ErrSessionRotated = errors.New("session is already rotated")
+
+type RotateSessionTxParams struct {
+	OldID      uuid.UUID           `json:"old_id"`
+	NewSession CreateSessionParams `json:"new_session"`
+}
+
+// RotateSessionTx marks the old session as rotated and creates the session of
+// the new refresh token. Only one caller can rotate a session, the others get
+// ErrSessionRotated.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.RotateSession(ctx, RotateSessionParams{
+			ID:        arg.OldID,
+			RotatedAt: time.Now(),
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrSessionRotated
+			}
+			return err
+		}
+
+		session, err = q.CreateSession(ctx, arg.NewSession)
+		return err
+	})
+
+	return session, err
+}
+
+// ErrPasswordResetInvalid is returned for reset tokens that are unknown, used or expired
+var ErrPasswordResetInvalid = errors.New("password reset token is invalid or expired")
+
+type ResetPasswordTxParams struct {
+	TokenHash      string    `json:"token_hash"`
+	HashedPassword string    `json:"hashed_password"`
+	ResetAt        time.Time `json:"reset_at"`
+	KeepHistory    int32     `json:"keep_history"`
+}
+
+// ResetPasswordTx uses up the reset token, sets the new password and deletes
+// every session and every other reset token of the user
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		reset, err := q.UsePasswordReset(ctx, UsePasswordResetParams{
+			TokenHash: arg.TokenHash,
+			UsedAt:    arg.ResetAt,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrPasswordResetInvalid
+			}
+			return err
+		}
+
+		err = recordPasswordHistory(ctx, q, reset.UserID, arg.KeepHistory)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			ID:                reset.UserID,
+			HashedPassword:    arg.HashedPassword,
+			PasswordChangedAt: arg.ResetAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteSessionsByUsername(ctx, user.Username.String)
+		if err != nil {
+			return err
+		}
+
+		return q.DeletePasswordResetsByUser(ctx, user.ID)
+	})
+
+	return user, err
+}
+
+// ErrEmailVerificationInvalid is returned for verification tokens that are
+// unknown, used, expired or sent to an address the user no longer has
+var ErrEmailVerificationInvalid = errors.New("email verification token is invalid or expired")
+
+type VerifyEmailTxParams struct {
+	TokenHash  string    `json:"token_hash"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// VerifyEmailTx uses up the verification token and marks the address it was
+// sent to as verified
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		verification, err := q.UseEmailVerification(ctx, UseEmailVerificationParams{
+			TokenHash: arg.TokenHash,
+			UsedAt:    arg.VerifiedAt,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrEmailVerificationInvalid
+			}
+			return err
+		}
+
+		user, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			ID:              verification.UserID,
+			Email:           sql.NullString{String: verification.Email, Valid: true},
+			EmailVerifiedAt: arg.VerifiedAt,
+		})
+		if err == sql.ErrNoRows {
+			return ErrEmailVerificationInvalid
+		}
+		return err
+	})
+
+	return user, err
+}
+
+type EnableTOTPTxParams struct {
+	UserID             int64     `json:"user_id"`
+	EnabledAt          time.Time `json:"enabled_at"`
+	Step               int64     `json:"step"`
+	RecoveryCodeHashes []string  `json:"recovery_code_hashes"`
+}
+
+// EnableTOTPTx confirms the pending TOTP secret of the user, remembering the
+// step of the code that confirmed it, and replaces their recovery codes
+func (store *SQLStore) EnableTOTPTx(ctx context.Context, arg EnableTOTPTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.EnableUserTOTP(ctx, EnableUserTOTPParams{
+			ID:            arg.UserID,
+			TotpEnabledAt: arg.EnabledAt,
+			TotpLastStep:  arg.Step,
+		})
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(ctx, q, arg.UserID, arg.RecoveryCodeHashes)
+	})
+
+	return user, err
+}
+
+// DisableTOTPTx removes the TOTP secret and the recovery codes of the user
+func (store *SQLStore) DisableTOTPTx(ctx context.Context, userID int64) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.DisableUserTOTP(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteRecoveryCodesByUser(ctx, userID)
+	})
+
+	return user, err
+}
+
+type ReplaceRecoveryCodesTxParams struct {
+	UserID     int64    `json:"user_id"`
+	CodeHashes []string `json:"code_hashes"`
+}
+
+// ReplaceRecoveryCodesTx deletes every recovery code of the user, used or
+// not, and stores the new ones
+func (store *SQLStore) ReplaceRecoveryCodesTx(ctx context.Context, arg ReplaceRecoveryCodesTxParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		return replaceRecoveryCodes(ctx, q, arg.UserID, arg.CodeHashes)
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, q *Queries, userID int64, codeHashes []string) error {
+	err := q.DeleteRecoveryCodesByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		_, err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: codeHash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type ProvisionOIDCUserTxParams struct {
+	User            CreateUserParams `json:"user"`
+	EmailVerifiedAt time.Time        `json:"email_verified_at"`
+	Issuer          string           `json:"issuer"`
+	Subject         string           `json:"subject"`
+}
+
+// ProvisionOIDCUserTx creates the user of an identity that logged in for the
+// first time and links the identity to it. The email is marked as verified
+// when the provider verified it.
+func (store *SQLStore) ProvisionOIDCUserTx(ctx context.Context, arg ProvisionOIDCUserTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.CreateUser(ctx, arg.User)
+		if err != nil {
+			return err
+		}
+
+		if !arg.EmailVerifiedAt.IsZero() {
+			user, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+				ID:              user.ID,
+				Email:           user.Email,
+				EmailVerifiedAt: arg.EmailVerifiedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = q.CreateUserIdentity(ctx, CreateUserIdentityParams{
+			UserID:  user.ID,
+			Issuer:  arg.Issuer,
+			Subject: arg.Subject,
+			Email:   user.Email.String,
+		})
+		return err
+	})
+
+	return user, err
+}
+
+// ErrInviteInvalid is returned for invite codes that are unknown, revoked,
+// expired, used up or of the wrong kind
+var ErrInviteInvalid = errors.New("invite code is invalid or expired")
+
+type SignUpWithInviteTxParams struct {
+	User       CreateUserParams `json:"user"`
+	CodeHash   string           `json:"code_hash"`
+	RedeemedAt time.Time        `json:"redeemed_at"`
+}
+
+// SignUpWithInviteTx uses up the invite and creates the user with the role of
+// the invite. Student invites also enroll the user in their class.
+func (store *SQLStore) SignUpWithInviteTx(ctx context.Context, arg SignUpWithInviteTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		invite, err := takeInvite(ctx, q, arg.CodeHash, arg.RedeemedAt)
+		if err != nil {
+			return err
+		}
+
+		arg.User.Role = invite.Role
+		user, err = q.CreateUser(ctx, arg.User)
+		if err != nil {
+			return err
+		}
+
+		_, err = redeemInvite(ctx, q, invite, user.ID, arg.RedeemedAt)
+		return err
+	})
+
+	return user, err
+}
+
+type JoinClassWithInviteTxParams struct {
+	CodeHash   string    `json:"code_hash"`
+	UserID     int64     `json:"user_id"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
+
+// JoinClassWithInviteTx uses up a student invite and enrolls an existing user
+// in its class. Teacher invites are only redeemed at sign up.
+func (store *SQLStore) JoinClassWithInviteTx(ctx context.Context, arg JoinClassWithInviteTxParams) (ClassMember, error) {
+	var member ClassMember
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		invite, err := takeInvite(ctx, q, arg.CodeHash, arg.RedeemedAt)
+		if err != nil {
+			return err
+		}
+
+		if !invite.ClassID.Valid {
+			return ErrInviteInvalid
+		}
+
+		member, err = redeemInvite(ctx, q, invite, arg.UserID, arg.RedeemedAt)
+		return err
+	})
+
+	return member, err
+}
+
+// takeInvite counts a use of the invite with the code
+func takeInvite(ctx context.Context, q *Queries, codeHash string, now time.Time) (Invite, error) {
+	invite, err := q.UseInvite(ctx, UseInviteParams{
+		CodeHash: codeHash,
+		Now:      now,
+	})
+	if err == sql.ErrNoRows {
+		return Invite{}, ErrInviteInvalid
+	}
+	return invite, err
+}
+
+// redeemInvite records who redeemed the invite and enrolls them in the class
+// of a student invite
+func redeemInvite(ctx context.Context, q *Queries, invite Invite, userID int64, redeemedAt time.Time) (ClassMember, error) {
+	_, err := q.CreateInviteRedemption(ctx, CreateInviteRedemptionParams{
+		InviteID:   invite.ID,
+		UserID:     userID,
+		RedeemedAt: redeemedAt,
+	})
+	if err != nil || !invite.ClassID.Valid {
+		return ClassMember{}, err
+	}
+
+	return q.AddClassMember(ctx, AddClassMemberParams{
+		ClassID: invite.ClassID.Int64,
+		UserID:  userID,
+	})
+}
+
+type ImportUserParams struct {
+	User           CreateUserParams `json:"user"`
+	ResetTokenHash string           `json:"reset_token_hash"`
+}
+
+type ImportUsersTxParams struct {
+	Users          []ImportUserParams `json:"users"`
+	ClassID        sql.NullInt64      `json:"class_id"`
+	ResetExpiresAt time.Time          `json:"reset_expires_at"`
+}
+
+// ImportUsersTx creates every user or none of them. Users are enrolled in the
+// class when one is given, and get a password reset token when they have a
+// token hash, so they can set their own password.
+func (store *SQLStore) ImportUsersTx(ctx context.Context, arg ImportUsersTxParams) ([]User, error) {
+	users := make([]User, 0, len(arg.Users))
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		for _, imported := range arg.Users {
+			user, err := q.CreateUser(ctx, imported.User)
+			if err != nil {
+				return err
+			}
+
+			if arg.ClassID.Valid {
+				_, err = q.AddClassMember(ctx, AddClassMemberParams{
+					ClassID: arg.ClassID.Int64,
+					UserID:  user.ID,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			if imported.ResetTokenHash != "" {
+				_, err = q.CreatePasswordReset(ctx, CreatePasswordResetParams{
+					UserID:    user.ID,
+					TokenHash: imported.ResetTokenHash,
+					ExpiresAt: arg.ResetExpiresAt,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			users = append(users, user)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+type UpdateHomeworkTxParams struct {
+	ID        int64         `json:"id"`
+	ClassID   sql.NullInt64 `json:"class_id"`
+	DueAt     sql.NullTime  `json:"due_at"`
+	FileName  string        `json:"file_name"`
+	SavedPath string        `json:"saved_path"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// UpdateHomeworkTx gives the homework a new due date, which reopens it, moves
+// it to another class and replaces its file. Only the parts that are set
+// change, and either all of them do or none.
+func (store *SQLStore) UpdateHomeworkTx(ctx context.Context, arg UpdateHomeworkTxParams) (Homework, error) {
+	var homework Homework
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		homework, err = q.GetHomework(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if arg.DueAt.Valid {
+			homework, err = q.UpdateHomeworkDueAt(ctx, UpdateHomeworkDueAtParams{
+				ID:        arg.ID,
+				DueAt:     arg.DueAt,
+				UpdatedAt: arg.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if arg.ClassID.Valid {
+			homework, err = q.UpdateHomeworkClass(ctx, UpdateHomeworkClassParams{
+				ID:        arg.ID,
+				ClassID:   arg.ClassID,
+				UpdatedAt: arg.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if arg.SavedPath != "" {
+			homework, err = q.UpdateHomework(ctx, UpdateHomeworkParams{
+				ID:        arg.ID,
+				FileName:  arg.FileName,
+				SavedPath: arg.SavedPath,
+				UpdatedAt: arg.UpdatedAt,
+			})
+		}
+		return err
+	})
+
+	return homework, err
+}
+
+// DeactivateUserTx marks the user as deleted and logs them out everywhere.
+// Their records stay, so the account can be reactivated.
+func (store *SQLStore) DeactivateUserTx(ctx context.Context, arg DeactivateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.DeactivateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteSessionsByUsername(ctx, user.Username.String)
+		if err != nil {
+			return err
+		}
+
+		return q.DeletePasswordResetsByUser(ctx, user.ID)
+	})
+
+	return user, err
+}
+
+// PurgeUserTx deletes the user and everything that cascades with them. It
+// returns the saved paths of the files that went with the records, they are
+// left to the caller to remove.
+func (store *SQLStore) PurgeUserTx(ctx context.Context, userID int64) ([]string, error) {
+	var savedPaths []string
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		savedPaths, err = q.ListUserFiles(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUser(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return savedPaths, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,3 +61,501 @@ func TestUpdateUserTx(t *testing.T) {
 	}
 
 }
+
+func TestUpdateUserPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user1 := createRandomUser(t)
+	session := createRandomSession(t, user1.Username)
+
+	hashPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	user2, err := store.UpdateUserPasswordTx(context.Background(), UpdateUserPasswordTxParams{
+		ID:                user1.ID,
+		HashedPassword:    hashPassword,
+		PasswordChangedAt: time.Now(),
+		KeepHistory:       1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashPassword, user2.HashedPassword)
+
+	_, err = store.GetSession(context.Background(), session.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = store.UpdateUserPasswordTx(context.Background(), UpdateUserPasswordTxParams{
+		ID:                user1.ID,
+		HashedPassword:    user1.HashedPassword,
+		PasswordChangedAt: time.Now(),
+		KeepHistory:       1,
+	})
+	require.NoError(t, err)
+
+	// only the newest old password is kept
+	history, err := testQueries.ListPasswordHistory(context.Background(), ListPasswordHistoryParams{
+		UserID: user1.ID,
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, hashPassword, history[0].HashedPassword)
+
+	testQueries.DeleteUser(context.Background(), user1.ID)
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user.Username)
+
+	arg := RotateSessionTxParams{
+		OldID: session1.ID,
+		NewSession: CreateSessionParams{
+			ID:           uuid.New(),
+			Username:     session1.Username,
+			RefreshToken: util.RandomString(10),
+			UserAgent:    session1.UserAgent,
+			ClientIp:     session1.ClientIp,
+			ExpiresAt:    session1.ExpiresAt,
+			FamilyID:     session1.FamilyID,
+		},
+	}
+
+	session2, err := store.RotateSessionTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.NewSession.ID, session2.ID)
+	require.Equal(t, session1.FamilyID, session2.FamilyID)
+
+	session3, err := store.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.False(t, session3.RotatedAt.IsZero())
+
+	// the old session can not be rotated twice
+	arg.NewSession.ID = uuid.New()
+	_, err = store.RotateSessionTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrSessionRotated)
+
+	err = store.BlockSessionFamily(context.Background(), session1.FamilyID)
+	require.NoError(t, err)
+
+	session4, err := store.GetSession(context.Background(), session2.ID)
+	require.NoError(t, err)
+	require.True(t, session4.IsBlocked)
+
+	err = store.DeleteSessionFamily(context.Background(), session1.FamilyID)
+	require.NoError(t, err)
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user1 := createRandomUser(t)
+	session := createRandomSession(t, user1.Username)
+
+	resetToken, err := util.NewSecret()
+	require.NoError(t, err)
+
+	_, err = testQueries.CreatePasswordReset(context.Background(), CreatePasswordResetParams{
+		UserID:    user1.ID,
+		TokenHash: util.HashSecret(resetToken),
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	hashPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	reset, err := testQueries.GetPasswordReset(context.Background(), GetPasswordResetParams{
+		TokenHash: util.HashSecret(resetToken),
+		Now:       time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, reset.UserID)
+
+	arg := ResetPasswordTxParams{
+		TokenHash:      util.HashSecret(resetToken),
+		HashedPassword: hashPassword,
+		ResetAt:        time.Now(),
+		KeepHistory:    3,
+	}
+
+	user2, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, user2.ID)
+	require.Equal(t, hashPassword, user2.HashedPassword)
+
+	_, err = store.GetSession(context.Background(), session.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	history, err := testQueries.ListPasswordHistory(context.Background(), ListPasswordHistoryParams{
+		UserID: user1.ID,
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, user1.HashedPassword, history[0].HashedPassword)
+
+	_, err = testQueries.GetPasswordReset(context.Background(), GetPasswordResetParams{
+		TokenHash: util.HashSecret(resetToken),
+		Now:       time.Now(),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	// the token can not be used twice
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.EqualError(t, err, ErrPasswordResetInvalid.Error())
+
+	testQueries.DeleteUser(context.Background(), user1.ID)
+}
+
+func TestResetPasswordTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+
+	resetToken, err := util.NewSecret()
+	require.NoError(t, err)
+
+	_, err = testQueries.CreatePasswordReset(context.Background(), CreatePasswordResetParams{
+		UserID:    user.ID,
+		TokenHash: util.HashSecret(resetToken),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash:      util.HashSecret(resetToken),
+		HashedPassword: user.HashedPassword,
+		ResetAt:        time.Now(),
+	})
+	require.EqualError(t, err, ErrPasswordResetInvalid.Error())
+
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user1 := createRandomUser(t)
+
+	verifyToken, err := util.NewSecret()
+	require.NoError(t, err)
+
+	_, err = testQueries.CreateEmailVerification(context.Background(), CreateEmailVerificationParams{
+		UserID:    user1.ID,
+		Email:     user1.Email.String,
+		TokenHash: util.HashSecret(verifyToken),
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	arg := VerifyEmailTxParams{
+		TokenHash:  util.HashSecret(verifyToken),
+		VerifiedAt: time.Now(),
+	}
+
+	user2, err := store.VerifyEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, user2.ID)
+	require.WithinDuration(t, arg.VerifiedAt, user2.EmailVerifiedAt, time.Second)
+
+	_, err = store.VerifyEmailTx(context.Background(), arg)
+	require.EqualError(t, err, ErrEmailVerificationInvalid.Error())
+
+	// changing the address takes the verification back
+	user3, err := testQueries.UpdateUserInfo(context.Background(), UpdateUserInfoParams{
+		ID:    user1.ID,
+		Email: sql.NullString{String: util.RandomEmail(), Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, user3.EmailVerifiedAt.IsZero())
+
+	testQueries.DeleteUser(context.Background(), user1.ID)
+}
+
+func TestTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	user1 := createRandomUser(t)
+	require.Empty(t, user1.TotpSecret)
+	require.True(t, user1.TotpEnabledAt.IsZero())
+
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+
+	user2, err := testQueries.SetUserTOTPSecret(context.Background(), SetUserTOTPSecretParams{
+		ID:         user1.ID,
+		TotpSecret: secret,
+	})
+	require.NoError(t, err)
+	require.Equal(t, secret, user2.TotpSecret)
+	require.True(t, user2.TotpEnabledAt.IsZero())
+
+	recoveryCode, err := util.NewRecoveryCode()
+	require.NoError(t, err)
+
+	step := util.TOTPStep(time.Now())
+	arg := EnableTOTPTxParams{
+		UserID:             user1.ID,
+		EnabledAt:          time.Now(),
+		Step:               step,
+		RecoveryCodeHashes: []string{util.HashSecret(recoveryCode), util.HashSecret(util.RandomString(10))},
+	}
+
+	user3, err := store.EnableTOTPTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.WithinDuration(t, arg.EnabledAt, user3.TotpEnabledAt, time.Second)
+	require.Equal(t, step, user3.TotpLastStep)
+
+	// the step that confirmed the secret can not be used again
+	_, err = testQueries.UseUserTOTPStep(context.Background(), UseUserTOTPStepParams{
+		ID:           user1.ID,
+		TotpLastStep: step,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = testQueries.UseUserTOTPStep(context.Background(), UseUserTOTPStepParams{
+		ID:           user1.ID,
+		TotpLastStep: step + 1,
+	})
+	require.NoError(t, err)
+
+	useArg := UseRecoveryCodeParams{
+		UserID:   user1.ID,
+		CodeHash: util.HashSecret(recoveryCode),
+		UsedAt:   time.Now(),
+	}
+
+	_, err = testQueries.UseRecoveryCode(context.Background(), useArg)
+	require.NoError(t, err)
+
+	_, err = testQueries.UseRecoveryCode(context.Background(), useArg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	user4, err := store.DisableTOTPTx(context.Background(), user1.ID)
+	require.NoError(t, err)
+	require.Empty(t, user4.TotpSecret)
+	require.True(t, user4.TotpEnabledAt.IsZero())
+
+	testQueries.DeleteUser(context.Background(), user1.ID)
+}
+
+func TestProvisionOIDCUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	arg := ProvisionOIDCUserTxParams{
+		User: CreateUserParams{
+			Username:       sql.NullString{String: util.RandomString(6), Valid: true},
+			HashedPassword: hashedPassword,
+			Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
+			Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
+			Role:           util.RoleStudent,
+		},
+		EmailVerifiedAt: time.Now(),
+		Issuer:          "https://" + util.RandomString(8) + ".example.com",
+		Subject:         util.RandomString(12),
+	}
+
+	user, err := store.ProvisionOIDCUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.User.Username, user.Username)
+	require.Equal(t, util.RoleStudent, user.Role)
+	require.WithinDuration(t, arg.EmailVerifiedAt, user.EmailVerifiedAt, time.Second)
+
+	identity, err := testQueries.GetUserIdentity(context.Background(), GetUserIdentityParams{
+		Issuer:  arg.Issuer,
+		Subject: arg.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, identity.UserID)
+
+	// the identity is linked already, so nothing is created
+	arg.User.Username = sql.NullString{String: util.RandomString(6), Valid: true}
+	arg.User.Email = sql.NullString{String: util.RandomEmail(), Valid: true}
+
+	_, err = store.ProvisionOIDCUserTx(context.Background(), arg)
+	require.Error(t, err)
+
+	_, err = testQueries.GetByUsername(context.Background(), arg.User.Username)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
+
+func randomImportUser(t *testing.T) ImportUserParams {
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	return ImportUserParams{
+		User: CreateUserParams{
+			Username:       sql.NullString{String: util.RandomString(6), Valid: true},
+			HashedPassword: hashedPassword,
+			Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
+			Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
+			PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
+			Role:           util.RoleStudent,
+		},
+		ResetTokenHash: util.HashSecret(util.RandomString(32)),
+	}
+}
+
+func TestImportUsersTx(t *testing.T) {
+	store := NewStore(testDB)
+	teacher := createRandomTeacher(t)
+	class := createRandomClass(t, teacher.ID)
+
+	arg := ImportUsersTxParams{
+		Users:          []ImportUserParams{randomImportUser(t), randomImportUser(t)},
+		ClassID:        sql.NullInt64{Int64: class.ID, Valid: true},
+		ResetExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	users, err := store.ImportUsersTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	for i, user := range users {
+		require.Equal(t, arg.Users[i].User.Username, user.Username)
+		require.Equal(t, util.RoleStudent, user.Role)
+
+		_, err = testQueries.GetClassMember(context.Background(), GetClassMemberParams{
+			ClassID: class.ID,
+			UserID:  user.ID,
+		})
+		require.NoError(t, err)
+
+		reset, err := testQueries.GetPasswordReset(context.Background(), GetPasswordResetParams{
+			TokenHash: arg.Users[i].ResetTokenHash,
+			Now:       time.Now(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, user.ID, reset.UserID)
+	}
+
+	// the second user takes the username of the first, so neither is created
+	failed := ImportUsersTxParams{
+		Users: []ImportUserParams{randomImportUser(t), randomImportUser(t)},
+	}
+	failed.Users[1].User.Username = users[0].Username
+
+	_, err = store.ImportUsersTx(context.Background(), failed)
+	require.Error(t, err)
+
+	_, err = testQueries.GetByUsername(context.Background(), failed.Users[0].User.Username)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	for _, user := range users {
+		testQueries.DeleteUser(context.Background(), user.ID)
+	}
+}
+
+func TestUpdateHomeworkTx(t *testing.T) {
+	store := NewStore(testDB)
+	teacher := createRandomTeacher(t)
+	class := createRandomClass(t, teacher.ID)
+	homework1 := createRandomHomework(t, teacher.ID, util.RandomSubject())
+
+	homework1, err := testQueries.CloseHomework(context.Background(), CloseHomeworkParams{
+		ID:       homework1.ID,
+		IsClosed: true,
+		ClosedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	// a class that does not exist fails the update after the due date was
+	// set, the homework stays closed
+	_, err = store.UpdateHomeworkTx(context.Background(), UpdateHomeworkTxParams{
+		ID:        homework1.ID,
+		ClassID:   sql.NullInt64{Int64: class.ID + 1000, Valid: true},
+		DueAt:     sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		UpdatedAt: time.Now(),
+	})
+	require.Error(t, err)
+
+	homework2, err := testQueries.GetHomework(context.Background(), homework1.ID)
+	require.NoError(t, err)
+	require.True(t, homework2.IsClosed)
+	require.False(t, homework2.DueAt.Valid)
+
+	arg := UpdateHomeworkTxParams{
+		ID:        homework1.ID,
+		ClassID:   sql.NullInt64{Int64: class.ID, Valid: true},
+		DueAt:     sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		FileName:  util.RandomString(10),
+		SavedPath: util.RandomString(10),
+		UpdatedAt: time.Now(),
+	}
+
+	homework2, err = store.UpdateHomeworkTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ClassID, homework2.ClassID)
+	require.WithinDuration(t, arg.DueAt.Time, homework2.DueAt.Time, time.Second)
+	require.False(t, homework2.IsClosed)
+	require.Equal(t, arg.FileName, homework2.FileName)
+	require.Equal(t, arg.SavedPath, homework2.SavedPath)
+
+	testQueries.DeleteHomework(context.Background(), homework2.ID)
+	testQueries.DeleteClass(context.Background(), class.ID)
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+}
+
+func TestDeactivateUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomSession(t, user.Username)
+
+	deactivated, err := store.DeactivateUserTx(context.Background(), DeactivateUserParams{
+		ID:        user.ID,
+		DeletedAt: time.Now(),
+	})
+	require.NoError(t, err)
+	require.False(t, deactivated.DeletedAt.IsZero())
+
+	sessions, err := testQueries.ListSessionsByUsername(context.Background(), user.Username.String)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	_, err = store.DeactivateUserTx(context.Background(), DeactivateUserParams{
+		ID:        user.ID,
+		DeletedAt: time.Now(),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
+
+func TestPurgeUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	teacher := createRandomTeacher(t)
+	student := createRandomStudent(t)
+	createRandomClass(t, teacher.ID)
+	createRandomMessage(t, student.ID, teacher.ID)
+
+	homework := createRandomHomework(t, teacher.ID, util.RandomSubject())
+	solution := createRandomSolution(t, student.ID, homework.ID)
+	createRandomGrade(t, teacher.ID, solution.ID)
+
+	savedPaths, err := store.PurgeUserTx(context.Background(), teacher.ID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{homework.SavedPath, solution.SavedPath}, savedPaths)
+
+	_, err = testQueries.GetUser(context.Background(), teacher.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = testQueries.GetHomework(context.Background(), homework.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = testQueries.GetSolutionByID(context.Background(), solution.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	// the student only loses what belonged to the teacher
+	_, err = testQueries.GetUser(context.Background(), student.ID)
+	require.NoError(t, err)
+
+	testQueries.DeleteUser(context.Background(), student.ID)
+}
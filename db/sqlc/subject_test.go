@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSubject(t *testing.T) Subject {
+	name := util.RandomString(10)
+
+	subject, err := testQueries.CreateSubject(context.Background(), name)
+	require.NoError(t, err)
+	require.NotEmpty(t, subject)
+
+	require.NotZero(t, subject.ID)
+	require.Equal(t, name, subject.Name)
+	require.NotZero(t, subject.CreatedAt)
+
+	return subject
+}
+
+func TestSeededSubjects(t *testing.T) {
+	subjects, err := testQueries.ListSubjects(context.Background())
+	require.NoError(t, err)
+
+	names := make(map[string]bool, len(subjects))
+	for _, subject := range subjects {
+		names[subject.Name] = true
+	}
+
+	for i := 0; i < 10; i++ {
+		require.True(t, names[util.RandomSubject()])
+	}
+}
+
+func TestUpdateSubjectCascadesToHomework(t *testing.T) {
+	teacher := createRandomTeacher(t)
+	subject := createRandomSubject(t)
+	homework := createRandomHomework(t, teacher.ID, subject.Name)
+
+	subject2, err := testQueries.UpdateSubject(context.Background(), UpdateSubjectParams{
+		ID:   subject.ID,
+		Name: util.RandomString(10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, subject.ID, subject2.ID)
+	require.NotEqual(t, subject.Name, subject2.Name)
+
+	homework2, err := testQueries.GetHomework(context.Background(), homework.ID)
+	require.NoError(t, err)
+	require.Equal(t, subject2.Name, homework2.Subject)
+
+	// the subject is still referenced
+	err = testQueries.DeleteSubject(context.Background(), subject.ID)
+	require.Error(t, err)
+
+	testQueries.DeleteHomework(context.Background(), homework.ID)
+
+	err = testQueries.DeleteSubject(context.Background(), subject.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetSubject(context.Background(), subject.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	testQueries.DeleteUser(context.Background(), teacher.ID)
+}
@@ -18,10 +18,10 @@ INSERT INTO users (
     fullname,
     email,
     phone_number,
-    is_teacher
+    role
 ) VALUES (
     $1, $2, $3, $4, $5, $6
-) RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, is_teacher
+) RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
 `
 
 type CreateUserParams struct {
@@ -30,7 +30,7 @@ type CreateUserParams struct {
 	Fullname       sql.NullString `json:"fullname"`
 	Email          sql.NullString `json:"email"`
 	PhoneNumber    sql.NullString `json:"phone_number"`
-	IsTeacher      bool           `json:"is_teacher"`
+	Role           string         `json:"role"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -40,7 +40,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Fullname,
 		arg.Email,
 		arg.PhoneNumber,
-		arg.IsTeacher,
+		arg.Role,
 	)
 	var i User
 	err := row.Scan(
@@ -52,7 +52,46 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PhoneNumber,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
-		&i.IsTeacher,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const deactivateUser = `-- name: DeactivateUser :one
+UPDATE users
+SET deleted_at = $2
+WHERE id = $1 AND deleted_at = '0001-01-01 00:00:00Z'
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
+`
+
+type DeactivateUserParams struct {
+	ID        int64     `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func (q *Queries) DeactivateUser(ctx context.Context, arg DeactivateUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, deactivateUser, arg.ID, arg.DeletedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -67,8 +106,75 @@ func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
 	return err
 }
 
+const disableUserTOTP = `-- name: DisableUserTOTP :one
+UPDATE users
+SET totp_secret = '',
+    totp_enabled_at = '0001-01-01 00:00:00Z',
+    totp_last_step = 0
+WHERE id = $1
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
+`
+
+func (q *Queries) DisableUserTOTP(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, disableUserTOTP, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :one
+UPDATE users
+SET totp_enabled_at = $2,
+    totp_last_step = $3
+WHERE id = $1 AND totp_secret <> ''
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
+`
+
+type EnableUserTOTPParams struct {
+	ID            int64     `json:"id"`
+	TotpEnabledAt time.Time `json:"totp_enabled_at"`
+	TotpLastStep  int64     `json:"totp_last_step"`
+}
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, enableUserTOTP, arg.ID, arg.TotpEnabledAt, arg.TotpLastStep)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const getByUsername = `-- name: GetByUsername :one
-SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, is_teacher FROM users
+SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -84,13 +190,18 @@ func (q *Queries) GetByUsername(ctx context.Context, username sql.NullString) (U
 		&i.PhoneNumber,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
-		&i.IsTeacher,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, is_teacher FROM users
+SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -106,13 +217,72 @@ func (q *Queries) GetUser(ctx context.Context, id int64) (User, error) {
 		&i.PhoneNumber,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
-		&i.IsTeacher,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at FROM users
+WHERE email = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getUserByPhoneNumber = `-- name: GetUserByPhoneNumber :one
+SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at FROM users
+WHERE phone_number = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByPhoneNumber(ctx context.Context, phoneNumber sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByPhoneNumber, phoneNumber)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getUserForUpdate = `-- name: GetUserForUpdate :one
-SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, is_teacher FROM users
+SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at FROM users
 WHERE id = $1 LIMIT 1
 FOR UPDATE
 `
@@ -129,13 +299,155 @@ func (q *Queries) GetUserForUpdate(ctx context.Context, id int64) (User, error)
 		&i.PhoneNumber,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
-		&i.IsTeacher,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const listAllUsers = `-- name: ListAllUsers :many
+SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at FROM users
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListAllUsersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListAllUsers(ctx context.Context, arg ListAllUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listAllUsers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.HashedPassword,
+			&i.Fullname,
+			&i.Email,
+			&i.PhoneNumber,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.EmailVerifiedAt,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGradebookStudents = `-- name: ListGradebookStudents :many
+SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at FROM users
+WHERE id IN (
+    SELECT cm.user_id FROM class_members cm
+    JOIN classes c ON c.id = cm.class_id
+    WHERE c.teacher_id = $1
+)
+ORDER BY id
+`
+
+func (q *Queries) ListGradebookStudents(ctx context.Context, teacherID int64) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listGradebookStudents, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.HashedPassword,
+			&i.Fullname,
+			&i.Email,
+			&i.PhoneNumber,
+			&i.PasswordChangedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.EmailVerifiedAt,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserFiles = `-- name: ListUserFiles :many
+SELECT saved_path FROM homeworks
+WHERE teacher_id = $1
+UNION
+SELECT s.saved_path FROM solutions s
+JOIN homeworks h ON h.id = s.problem_id
+WHERE s.user_id = $1 OR h.teacher_id = $1
+UNION
+SELECT g.feedback_saved_path FROM grades g
+JOIN solutions s ON s.id = g.solution_id
+JOIN homeworks h ON h.id = s.problem_id
+WHERE g.feedback_saved_path <> ''
+    AND (s.user_id = $1 OR h.teacher_id = $1 OR g.grader_id = $1)
+`
+
+func (q *Queries) ListUserFiles(ctx context.Context, userID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listUserFiles, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var savedPath string
+		if err := rows.Scan(&savedPath); err != nil {
+			return nil, err
+		}
+		items = append(items, savedPath)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
-SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, is_teacher FROM users
+SELECT id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at FROM users
+WHERE deleted_at = '0001-01-01 00:00:00Z'
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -164,7 +476,12 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.PhoneNumber,
 			&i.PasswordChangedAt,
 			&i.CreatedAt,
-			&i.IsTeacher,
+			&i.Role,
+			&i.EmailVerifiedAt,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -179,14 +496,80 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const reactivateUser = `-- name: ReactivateUser :one
+UPDATE users
+SET deleted_at = '0001-01-01 00:00:00Z'
+WHERE id = $1 AND deleted_at <> '0001-01-01 00:00:00Z'
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
+`
+
+func (q *Queries) ReactivateUser(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, reactivateUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const setUserTOTPSecret = `-- name: SetUserTOTPSecret :one
+UPDATE users
+SET totp_secret = $2,
+    totp_enabled_at = '0001-01-01 00:00:00Z',
+    totp_last_step = 0
+WHERE id = $1
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
+`
+
+type SetUserTOTPSecretParams struct {
+	ID         int64  `json:"id"`
+	TotpSecret string `json:"totp_secret"`
+}
+
+func (q *Queries) SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserTOTPSecret, arg.ID, arg.TotpSecret)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const updateUserInfo = `-- name: UpdateUserInfo :one
 UPDATE users
 SET username = COALESCE($2, username),
     fullname = COALESCE($3, fullname),
     email = COALESCE($4, email),
-    phone_number = COALESCE($5, phone_number)
+    phone_number = COALESCE($5, phone_number),
+    email_verified_at = CASE WHEN $4 IS NULL OR $4 = email THEN email_verified_at ELSE '0001-01-01 00:00:00Z' END
 WHERE id = $1
-RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, is_teacher
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
 `
 
 type UpdateUserInfoParams struct {
@@ -215,7 +598,12 @@ func (q *Queries) UpdateUserInfo(ctx context.Context, arg UpdateUserInfoParams)
 		&i.PhoneNumber,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
-		&i.IsTeacher,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
 	)
 	return i, err
 }
@@ -225,7 +613,7 @@ UPDATE users
 SET hashed_password = $2,
     password_changed_at = $3
 WHERE id = $1
-RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, is_teacher
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
 `
 
 type UpdateUserPasswordParams struct {
@@ -246,7 +634,115 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 		&i.PhoneNumber,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
-		&i.IsTeacher,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
+`
+
+type UpdateUserRoleParams struct {
+	ID   int64  `json:"id"`
+	Role string `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const useUserTOTPStep = `-- name: UseUserTOTPStep :one
+UPDATE users
+SET totp_last_step = $2
+WHERE id = $1 AND totp_last_step < $2
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
+`
+
+type UseUserTOTPStepParams struct {
+	ID           int64 `json:"id"`
+	TotpLastStep int64 `json:"totp_last_step"`
+}
+
+func (q *Queries) UseUserTOTPStep(ctx context.Context, arg UseUserTOTPStepParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, useUserTOTPStep, arg.ID, arg.TotpLastStep)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE users
+SET email_verified_at = $3
+WHERE id = $1 AND email = $2
+RETURNING id, username, hashed_password, fullname, email, phone_number, password_changed_at, created_at, role, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, deleted_at
+`
+
+type VerifyUserEmailParams struct {
+	ID              int64          `json:"id"`
+	Email           sql.NullString `json:"email"`
+	EmailVerifiedAt time.Time      `json:"email_verified_at"`
+}
+
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, verifyUserEmail, arg.ID, arg.Email, arg.EmailVerifiedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.HashedPassword,
+		&i.Fullname,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.DeletedAt,
 	)
 	return i, err
 }
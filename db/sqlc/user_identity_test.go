@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserIdentity(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := CreateUserIdentityParams{
+		UserID:  user.ID,
+		Issuer:  "https://" + util.RandomString(8) + ".example.com",
+		Subject: util.RandomString(12),
+		Email:   user.Email.String,
+	}
+
+	identity1, err := testQueries.CreateUserIdentity(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserID, identity1.UserID)
+	require.Equal(t, arg.Issuer, identity1.Issuer)
+	require.Equal(t, arg.Subject, identity1.Subject)
+
+	identity2, err := testQueries.GetUserIdentity(context.Background(), GetUserIdentityParams{
+		Issuer:  arg.Issuer,
+		Subject: arg.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, identity1.ID, identity2.ID)
+
+	// a subject is only unique at its issuer
+	_, err = testQueries.GetUserIdentity(context.Background(), GetUserIdentityParams{
+		Issuer:  "https://other.example.com",
+		Subject: arg.Subject,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = testQueries.CreateUserIdentity(context.Background(), arg)
+	require.Error(t, err)
+
+	testQueries.DeleteUser(context.Background(), user.ID)
+
+	_, err = testQueries.GetUserIdentity(context.Background(), GetUserIdentityParams{
+		Issuer:  arg.Issuer,
+		Subject: arg.Subject,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestOIDCLogin(t *testing.T) {
+	state, err := util.NewSecret()
+	require.NoError(t, err)
+
+	arg := CreateOIDCLoginParams{
+		StateHash:    util.HashSecret(state),
+		Nonce:        util.RandomString(16),
+		CodeVerifier: util.RandomString(43),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	login1, err := testQueries.CreateOIDCLogin(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, login1.UsedAt.IsZero())
+
+	login2, err := testQueries.UseOIDCLogin(context.Background(), UseOIDCLoginParams{
+		StateHash: arg.StateHash,
+		UsedAt:    time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, login1.ID, login2.ID)
+	require.Equal(t, arg.Nonce, login2.Nonce)
+	require.Equal(t, arg.CodeVerifier, login2.CodeVerifier)
+
+	_, err = testQueries.UseOIDCLogin(context.Background(), UseOIDCLoginParams{
+		StateHash: arg.StateHash,
+		UsedAt:    time.Now(),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
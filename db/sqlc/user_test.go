@@ -20,7 +20,7 @@ func createRandomUser(t *testing.T) User {
 		Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
 		Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
 		PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
-		IsTeacher:      util.RandomBoolean(),
+		Role:           util.RandomRole(),
 	}
 
 	user, err := testQueries.CreateUser(context.Background(), arg)
@@ -33,7 +33,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.Fullname, user.Fullname)
 	require.Equal(t, arg.Email, user.Email)
 	require.Equal(t, arg.PhoneNumber, user.PhoneNumber)
-	require.Equal(t, arg.IsTeacher, user.IsTeacher)
+	require.Equal(t, arg.Role, user.Role)
 	require.NotZero(t, user.CreatedAt)
 	require.True(t, user.PasswordChangedAt.IsZero())
 
@@ -50,7 +50,7 @@ func createRandomTeacher(t *testing.T) User {
 		Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
 		Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
 		PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
-		IsTeacher:      true,
+		Role:           util.RoleTeacher,
 	}
 
 	user, err := testQueries.CreateUser(context.Background(), arg)
@@ -63,7 +63,7 @@ func createRandomTeacher(t *testing.T) User {
 	require.Equal(t, arg.Fullname, user.Fullname)
 	require.Equal(t, arg.Email, user.Email)
 	require.Equal(t, arg.PhoneNumber, user.PhoneNumber)
-	require.Equal(t, arg.IsTeacher, user.IsTeacher)
+	require.Equal(t, arg.Role, user.Role)
 	require.NotZero(t, user.CreatedAt)
 	require.True(t, user.PasswordChangedAt.IsZero())
 
@@ -80,7 +80,7 @@ func createRandomStudent(t *testing.T) User {
 		Fullname:       sql.NullString{String: util.RandomString(6), Valid: true},
 		Email:          sql.NullString{String: util.RandomEmail(), Valid: true},
 		PhoneNumber:    sql.NullString{String: util.RandomPhoneNumber(), Valid: true},
-		IsTeacher:      false,
+		Role:           util.RoleStudent,
 	}
 
 	user, err := testQueries.CreateUser(context.Background(), arg)
@@ -93,7 +93,7 @@ func createRandomStudent(t *testing.T) User {
 	require.Equal(t, arg.Fullname, user.Fullname)
 	require.Equal(t, arg.Email, user.Email)
 	require.Equal(t, arg.PhoneNumber, user.PhoneNumber)
-	require.Equal(t, arg.IsTeacher, user.IsTeacher)
+	require.Equal(t, arg.Role, user.Role)
 	require.NotZero(t, user.CreatedAt)
 	require.True(t, user.PasswordChangedAt.IsZero())
 
@@ -117,7 +117,7 @@ func TestGetUser(t *testing.T) {
 	require.Equal(t, user1.Fullname, user2.Fullname)
 	require.Equal(t, user1.Email, user2.Email)
 	require.Equal(t, user1.PhoneNumber, user2.PhoneNumber)
-	require.Equal(t, user1.IsTeacher, user2.IsTeacher)
+	require.Equal(t, user1.Role, user2.Role)
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 
@@ -136,7 +136,7 @@ func TestGetByUserName(t *testing.T) {
 	require.Equal(t, user1.Fullname, user2.Fullname)
 	require.Equal(t, user1.Email, user2.Email)
 	require.Equal(t, user1.PhoneNumber, user2.PhoneNumber)
-	require.Equal(t, user1.IsTeacher, user2.IsTeacher)
+	require.Equal(t, user1.Role, user2.Role)
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 
@@ -198,7 +198,7 @@ func TestUpdateUserInfo(t *testing.T) {
 	require.Equal(t, arg.PhoneNumber, user2.PhoneNumber)
 
 	require.Equal(t, user1.ID, user2.ID)
-	require.Equal(t, user1.IsTeacher, user2.IsTeacher)
+	require.Equal(t, user1.Role, user2.Role)
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 
@@ -223,7 +223,7 @@ func TestUpdateUserName(t *testing.T) {
 	require.Equal(t, user1.Fullname, user2.Fullname)
 	require.Equal(t, user1.Email, user2.Email)
 	require.Equal(t, user1.PhoneNumber, user2.PhoneNumber)
-	require.Equal(t, user1.IsTeacher, user2.IsTeacher)
+	require.Equal(t, user1.Role, user2.Role)
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 
@@ -251,9 +251,64 @@ func TestUpdateUserPassword(t *testing.T) {
 	require.Equal(t, user1.Fullname, user2.Fullname)
 	require.Equal(t, user1.Email, user2.Email)
 	require.Equal(t, user1.PhoneNumber, user2.PhoneNumber)
-	require.Equal(t, user1.IsTeacher, user2.IsTeacher)
+	require.Equal(t, user1.Role, user2.Role)
 
 	require.Equal(t, arg.HashedPassword, user2.HashedPassword)
 	require.WithinDuration(t, arg.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	user2, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		ID:   user1.ID,
+		Role: util.RoleAdmin,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, user2.ID)
+	require.Equal(t, util.RoleAdmin, user2.Role)
+
+	_, err = testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		ID:   user1.ID,
+		Role: "principal",
+	})
+	require.Error(t, err)
+
+	testQueries.DeleteUser(context.Background(), user1.ID)
+}
+
+func TestDeactivateUser(t *testing.T) {
+	user := createRandomUser(t)
+	require.True(t, user.DeletedAt.IsZero())
+
+	deletedAt := time.Now()
+	deactivated, err := testQueries.DeactivateUser(context.Background(), DeactivateUserParams{
+		ID:        user.ID,
+		DeletedAt: deletedAt,
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, deletedAt, deactivated.DeletedAt, time.Second)
+
+	// deactivating twice does nothing
+	_, err = testQueries.DeactivateUser(context.Background(), DeactivateUserParams{
+		ID:        user.ID,
+		DeletedAt: time.Now(),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	users, err := testQueries.ListUsers(context.Background(), ListUsersParams{Limit: 1000, Offset: 0})
+	require.NoError(t, err)
+	for _, listed := range users {
+		require.NotEqual(t, user.ID, listed.ID)
+	}
+
+	reactivated, err := testQueries.ReactivateUser(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, reactivated.DeletedAt.IsZero())
+
+	_, err = testQueries.ReactivateUser(context.Background(), user.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	testQueries.DeleteUser(context.Background(), user.ID)
+}
@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// LogMailer does not deliver anything, it logs every email and also writes it
+// to a file when a directory is set. It is meant for local development.
+type LogMailer struct {
+	dir string
+}
+
+func NewLogMailer(dir string) (Mailer, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("cannot create mail directory: %w", err)
+		}
+	}
+
+	return &LogMailer{dir: dir}, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+func (mailer *LogMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	log.Printf("mail to %s: %s\n%s", to, subject, body)
+
+	if mailer.dir == "" {
+		return nil
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), unsafeFileChars.ReplaceAllString(to, "_"))
+	return ioutil.WriteFile(filepath.Join(mailer.dir, name), buildMessage("", to, subject, body), 0644)
+}
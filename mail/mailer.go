@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dongocanh96/class_manager_go/util"
+)
+
+// Mailer sends plain text emails
+type Mailer interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+const (
+	BackendLog  = "log"
+	BackendSMTP = "smtp"
+)
+
+// NewMailer creates the mailer selected by config.MailBackend
+func NewMailer(config util.Config) (Mailer, error) {
+	switch config.MailBackend {
+	case "", BackendLog:
+		return NewLogMailer(config.MailDirectory)
+	case BackendSMTP:
+		return NewSMTPMailer(SMTPConfig{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+			Sender:   config.MailSender,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported mail backend %s", config.MailBackend)
+	}
+}
@@ -0,0 +1,55 @@
+package mail
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMailer(t *testing.T) {
+	dir := t.TempDir()
+
+	mailer, err := NewLogMailer(dir)
+	require.NoError(t, err)
+
+	to := util.RandomEmail()
+	err = mailer.Send(context.Background(), to, "Reset your password", "line 1\nline 2")
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	data, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(data), "To: "+to+"\r\n")
+	require.Contains(t, string(data), "Subject: Reset your password\r\n")
+	require.Contains(t, string(data), "\r\n\r\nline 1\r\nline 2")
+}
+
+func TestBuildMessageHeaderInjection(t *testing.T) {
+	message := string(buildMessage("sender@example.com", "user@example.com\r\nBcc: victim@example.com", "hi", "body"))
+
+	require.NotContains(t, message, "\r\nBcc:")
+	require.Contains(t, message, "To: user@example.comBcc: victim@example.com\r\n")
+}
+
+func TestNewMailer(t *testing.T) {
+	mailer, err := NewMailer(util.Config{MailBackend: BackendLog})
+	require.NoError(t, err)
+	require.IsType(t, &LogMailer{}, mailer)
+
+	_, err = NewMailer(util.Config{MailBackend: BackendSMTP})
+	require.Error(t, err)
+
+	mailer, err = NewMailer(util.Config{MailBackend: BackendSMTP, SMTPHost: "localhost", SMTPPort: 1025, MailSender: "sender@example.com"})
+	require.NoError(t, err)
+	require.IsType(t, &SMTPMailer{}, mailer)
+
+	_, err = NewMailer(util.Config{MailBackend: "pigeon"})
+	require.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Sender   string
+}
+
+// SMTPMailer delivers emails through an SMTP relay
+type SMTPMailer struct {
+	addr   string
+	auth   smtp.Auth
+	sender string
+}
+
+func NewSMTPMailer(config SMTPConfig) (Mailer, error) {
+	if config.Host == "" || config.Sender == "" {
+		return nil, errors.New("smtp host and sender are required")
+	}
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	return &SMTPMailer{
+		addr:   fmt.Sprintf("%s:%d", config.Host, config.Port),
+		auth:   auth,
+		sender: config.Sender,
+	}, nil
+}
+
+func (mailer *SMTPMailer) Send(ctx context.Context, to string, subject string, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	message := buildMessage(mailer.sender, to, subject, body)
+	return smtp.SendMail(mailer.addr, mailer.auth, mailer.sender, []string{to}, message)
+}
+
+// buildMessage formats a plain text email, header values are stripped of line
+// breaks so they can not inject headers
+func buildMessage(from string, to string, subject string, body string) []byte {
+	header := strings.NewReplacer("\r", "", "\n", "")
+
+	var message bytes.Buffer
+	if from != "" {
+		fmt.Fprintf(&message, "From: %s\r\n", header.Replace(from))
+	}
+	fmt.Fprintf(&message, "To: %s\r\n", header.Replace(to))
+	fmt.Fprintf(&message, "Subject: %s\r\n", header.Replace(subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return message.Bytes()
+}
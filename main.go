@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"os"
 
 	"github.com/dongocanh96/class_manager_go/api"
 	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/mail"
+	"github.com/dongocanh96/class_manager_go/scheduler"
+	"github.com/dongocanh96/class_manager_go/storage"
 	"github.com/dongocanh96/class_manager_go/util"
 	_ "github.com/lib/pq"
 )
@@ -22,7 +27,29 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		runCreateAdmin(config, store, os.Args[2:])
+		return
+	}
+
+	closer := scheduler.NewHomeworkCloser(store, config.HomeworkCloserInterval)
+	go closer.Start(context.Background())
+
+	cleaner := scheduler.NewLoginFailureCleaner(store, config.LoginMaxLockout)
+	go cleaner.Start(context.Background())
+
+	fileStore, err := storage.NewFileStore(config)
+	if err != nil {
+		log.Fatal("cannot create file store:", err)
+	}
+
+	mailer, err := mail.NewMailer(config)
+	if err != nil {
+		log.Fatal("cannot create mailer:", err)
+	}
+
+	server, err := api.NewServer(config, store, fileStore, mailer)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
@@ -33,3 +60,22 @@ func main() {
 	}
 
 }
+
+func runCreateAdmin(config util.Config, store db.Store, args []string) {
+	arg, err := parseCreateAdminArgs(args, os.Stdin)
+	if err != nil {
+		log.Fatal("cannot read admin:", err)
+	}
+
+	policy, err := util.NewPasswordPolicy(config)
+	if err != nil {
+		log.Fatal("cannot load password policy:", err)
+	}
+
+	admin, err := createAdmin(context.Background(), store, policy, arg)
+	if err != nil {
+		log.Fatal("cannot create admin:", err)
+	}
+
+	log.Printf("created admin %s with id %d", admin.Username.String, admin.ID)
+}
@@ -0,0 +1,229 @@
+// Package oidctest runs an OpenID Connect provider for tests
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/token"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// User is the account the provider logs in
+type User struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// Server is a provider whose authorization endpoint logs User in without
+// asking and redirects back with a code. Codes are redeemed once, with the
+// client secret and the PKCE code verifier.
+type Server struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	mu    sync.Mutex
+	user  User
+	codes map[string]authorization
+
+	key   *rsa.PrivateKey
+	keys  *token.KeySet
+	keyID string
+}
+
+type authorization struct {
+	user          User
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+}
+
+// NewServer starts a provider, callers Close it when done
+func NewServer(clientID string, clientSecret string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("oidctest: " + err.Error())
+	}
+
+	server := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		codes:        make(map[string]authorization),
+		key:          key,
+		keys:         token.NewKeySet(key),
+		keyID:        token.KeyID(&key.PublicKey),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", server.discovery)
+	mux.HandleFunc("/jwks", server.jwks)
+	mux.HandleFunc("/authorize", server.authorize)
+	mux.HandleFunc("/token", server.token)
+	server.Server = httptest.NewServer(mux)
+
+	return server
+}
+
+// Issuer returns the issuer of the id tokens of the server
+func (server *Server) Issuer() string {
+	return server.URL
+}
+
+// SetUser changes the account the next authorization logs in
+func (server *Server) SetUser(user User) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.user = user
+}
+
+// IDToken signs an id token for the user, for tests of tokens the server
+// would never issue
+func (server *Server) IDToken(user User, audience string, nonce string, expiresAt time.Time) string {
+	claims := jwt.MapClaims{
+		"iss":                server.Issuer(),
+		"sub":                user.Subject,
+		"aud":                audience,
+		"iat":                time.Now().Unix(),
+		"exp":                expiresAt.Unix(),
+		"nonce":              nonce,
+		"email":              user.Email,
+		"email_verified":     user.EmailVerified,
+		"name":               user.Name,
+		"preferred_username": user.PreferredUsername,
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = server.keyID
+
+	signed, err := idToken.SignedString(server.key)
+	if err != nil {
+		panic("oidctest: " + err.Error())
+	}
+	return signed
+}
+
+func (server *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                server.Issuer(),
+		"authorization_endpoint":                server.URL + "/authorize",
+		"token_endpoint":                        server.URL + "/token",
+		"jwks_uri":                              server.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (server *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, server.keys.JWKS())
+}
+
+func (server *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != server.ClientID ||
+		query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" ||
+		query.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect uri", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+
+	server.mu.Lock()
+	server.codes[code] = authorization{
+		user:          server.user,
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+	}
+	server.mu.Unlock()
+
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (server *Server) token(w http.ResponseWriter, r *http.Request) {
+	// the client id and secret are form encoded before basic auth, RFC 6749
+	// 2.3.1. Public clients without a secret only send their id.
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = r.PostFormValue("client_id")
+	}
+
+	if clientID != server.ClientID || clientSecret != server.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	if r.PostFormValue("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	server.mu.Lock()
+	auth, ok := server.codes[r.PostFormValue("code")]
+	delete(server.codes, r.PostFormValue("code"))
+	server.mu.Unlock()
+
+	if !ok || auth.redirectURI != r.PostFormValue("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "code verifier does not match",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     server.IDToken(auth.user, server.ClientID, auth.nonce, time.Now().Add(5*time.Minute)),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic("oidctest: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,322 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// keyRefreshInterval limits how often the keys of the provider are fetched
+// again for an unknown key id
+const keyRefreshInterval = time.Minute
+
+var ErrInvalidIDToken = errors.New("id token is invalid")
+
+// Config is the registration of this server as a client at the provider
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Claims are the claims of an id token the server uses
+type Claims struct {
+	jwt.RegisteredClaims
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// Provider logs users in at an OpenID Connect provider with the authorization
+// code flow and PKCE. The discovery document and the keys of the provider are
+// fetched on first use, so the server starts while the provider is down.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *metadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func NewProvider(config Config) (*Provider, error) {
+	if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, errors.New("oidc issuer, client id and redirect url are required")
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	provider := &Provider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	return provider, nil
+}
+
+// Issuer returns the issuer identities of the provider are scoped to
+func (provider *Provider) Issuer() string {
+	return provider.config.Issuer
+}
+
+// AuthCodeURL returns the url of the provider the user logs in at. The nonce
+// comes back in the id token, the code verifier is needed to redeem the code.
+func (provider *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeVerifier string) (string, error) {
+	meta, err := provider.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.config.ClientID)
+	query.Set("redirect_uri", provider.config.RedirectURL)
+	query.Set("scope", strings.Join(provider.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return meta.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange redeems the authorization code and returns the raw id token
+func (provider *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	meta, err := provider.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", provider.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", provider.config.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if provider.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(provider.config.ClientID), url.QueryEscape(provider.config.ClientSecret))
+	}
+
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to redeem code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return "", fmt.Errorf("failed to redeem code: %s %s", token.Error, token.ErrorDescription)
+	}
+
+	if token.IDToken == "" {
+		return "", errors.New("token response has no id token")
+	}
+
+	return token.IDToken, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of
+// the id token and returns its claims
+func (provider *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*Claims, error) {
+	if _, err := provider.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "EdDSA"}))
+
+	claims := &Claims{}
+	_, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+		return provider.publicKey(ctx, keyID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Issuer != provider.config.Issuer {
+		return nil, fmt.Errorf("%w: issued by %s", ErrInvalidIDToken, claims.Issuer)
+	}
+
+	if !claims.VerifyAudience(provider.config.ClientID, true) {
+		return nil, fmt.Errorf("%w: issued for another client", ErrInvalidIDToken)
+	}
+
+	if !claims.VerifyExpiresAt(time.Now(), true) {
+		return nil, fmt.Errorf("%w: no expiry", ErrInvalidIDToken)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
+
+// discover fetches the discovery document of the provider once
+func (provider *Provider) discover(ctx context.Context) (*metadata, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.metadata != nil {
+		return provider.metadata, nil
+	}
+
+	wellKnown := strings.TrimSuffix(provider.config.Issuer, "/") + "/.well-known/openid-configuration"
+
+	var meta metadata
+	if err := provider.getJSON(ctx, wellKnown, &meta); err != nil {
+		return nil, fmt.Errorf("failed to discover oidc provider: %w", err)
+	}
+
+	// the document must belong to the issuer it was fetched from
+	if meta.Issuer != provider.config.Issuer {
+		return nil, fmt.Errorf("oidc provider issuer %s does not match %s", meta.Issuer, provider.config.Issuer)
+	}
+
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc discovery document is incomplete")
+	}
+
+	provider.metadata = &meta
+	return provider.metadata, nil
+}
+
+// publicKey returns the key the provider signs with under the key id, the
+// keys are fetched again when a token names a key that is not known yet
+func (provider *Provider) publicKey(ctx context.Context, keyID string) (interface{}, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if key, ok := provider.lookupKey(keyID); ok {
+		return key, nil
+	}
+
+	if time.Since(provider.keysFetchedAt) < keyRefreshInterval {
+		return nil, fmt.Errorf("unknown key %s", keyID)
+	}
+
+	var set jsonWebKeySet
+	if err := provider.getJSON(ctx, provider.metadata.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch oidc keys: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	provider.keys = keys
+	provider.keysFetchedAt = time.Now()
+
+	if key, ok := provider.lookupKey(keyID); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %s", keyID)
+}
+
+// lookupKey finds the key with the id, a token without a key id may only be
+// used while the provider has a single key
+func (provider *Provider) lookupKey(keyID string) (interface{}, bool) {
+	if keyID == "" && len(provider.keys) == 1 {
+		for _, key := range provider.keys {
+			return key, true
+		}
+	}
+
+	key, ok := provider.keys[keyID]
+	return key, ok
+}
+
+func (provider *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := provider.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// NewCodeVerifier returns a random PKCE code verifier as described in RFC 7636
+func NewCodeVerifier() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge returns the S256 challenge of the code verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
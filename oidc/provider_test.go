@@ -0,0 +1,192 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/oidc/oidctest"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirectURL = "http://localhost:8080/oidc/callback"
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Server) {
+	server := oidctest.NewServer("class_manager", util.RandomString(16))
+	t.Cleanup(server.Close)
+
+	provider, err := NewProvider(Config{
+		Issuer:       server.Issuer(),
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURL:  testRedirectURL,
+	})
+	require.NoError(t, err)
+
+	return provider, server
+}
+
+// authorize follows the login at the provider and returns the code and state
+// it redirects back with
+func authorize(t *testing.T, authURL string) (code string, state string) {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, testRedirectURL, location.Scheme+"://"+location.Host+location.Path)
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	provider, server := newTestProvider(t)
+
+	user := oidctest.User{
+		Subject:           util.RandomString(12),
+		Email:             util.RandomEmail(),
+		EmailVerified:     true,
+		Name:              util.RandomString(8),
+		PreferredUsername: util.RandomString(8),
+	}
+	server.SetUser(user)
+
+	codeVerifier, err := NewCodeVerifier()
+	require.NoError(t, err)
+	require.Len(t, codeVerifier, 43)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state1", "nonce1", codeVerifier)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, CodeChallenge(codeVerifier), parsed.Query().Get("code_challenge"))
+	require.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+
+	code, state := authorize(t, authURL)
+	require.Equal(t, "state1", state)
+
+	idToken, err := provider.Exchange(context.Background(), code, codeVerifier)
+	require.NoError(t, err)
+
+	claims, err := provider.VerifyIDToken(context.Background(), idToken, "nonce1")
+	require.NoError(t, err)
+	require.Equal(t, server.Issuer(), claims.Issuer)
+	require.Equal(t, user.Subject, claims.Subject)
+	require.Equal(t, user.Email, claims.Email)
+	require.True(t, claims.EmailVerified)
+	require.Equal(t, user.Name, claims.Name)
+	require.Equal(t, user.PreferredUsername, claims.PreferredUsername)
+
+	// codes are redeemed once
+	_, err = provider.Exchange(context.Background(), code, codeVerifier)
+	require.Error(t, err)
+}
+
+func TestExchangeWrongCodeVerifier(t *testing.T) {
+	provider, server := newTestProvider(t)
+	server.SetUser(oidctest.User{Subject: util.RandomString(12)})
+
+	codeVerifier, err := NewCodeVerifier()
+	require.NoError(t, err)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", codeVerifier)
+	require.NoError(t, err)
+
+	code, _ := authorize(t, authURL)
+
+	otherVerifier, err := NewCodeVerifier()
+	require.NoError(t, err)
+
+	_, err = provider.Exchange(context.Background(), code, otherVerifier)
+	require.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider, server := newTestProvider(t)
+	user := oidctest.User{Subject: util.RandomString(12)}
+
+	other := oidctest.NewServer(server.ClientID, "")
+	defer other.Close()
+
+	testCases := []struct {
+		name    string
+		idToken string
+		nonce   string
+		ok      bool
+	}{
+		{
+			name:    "OK",
+			idToken: server.IDToken(user, server.ClientID, "nonce", time.Now().Add(time.Minute)),
+			nonce:   "nonce",
+			ok:      true,
+		},
+		{
+			name:    "WrongNonce",
+			idToken: server.IDToken(user, server.ClientID, "nonce", time.Now().Add(time.Minute)),
+			nonce:   "other",
+		},
+		{
+			name:    "OtherClient",
+			idToken: server.IDToken(user, "other_client", "nonce", time.Now().Add(time.Minute)),
+			nonce:   "nonce",
+		},
+		{
+			name:    "Expired",
+			idToken: server.IDToken(user, server.ClientID, "nonce", time.Now().Add(-time.Minute)),
+			nonce:   "nonce",
+		},
+		{
+			name:    "NoSubject",
+			idToken: server.IDToken(oidctest.User{}, server.ClientID, "nonce", time.Now().Add(time.Minute)),
+			nonce:   "nonce",
+		},
+		{
+			name:    "OtherProvider",
+			idToken: other.IDToken(user, server.ClientID, "nonce", time.Now().Add(time.Minute)),
+			nonce:   "nonce",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			claims, err := provider.VerifyIDToken(context.Background(), tc.idToken, tc.nonce)
+			if tc.ok {
+				require.NoError(t, err)
+				require.Equal(t, user.Subject, claims.Subject)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidIDToken)
+			require.Nil(t, claims)
+		})
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	server := oidctest.NewServer("class_manager", "")
+	defer server.Close()
+
+	provider, err := NewProvider(Config{
+		Issuer:      server.Issuer() + "/other",
+		ClientID:    server.ClientID,
+		RedirectURL: testRedirectURL,
+	})
+	require.NoError(t, err)
+
+	_, err = provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	require.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+)
+
+// HomeworkCloser periodically closes homeworks whose due date has passed
+type HomeworkCloser struct {
+	store    db.Store
+	interval time.Duration
+}
+
+const defaultCloserInterval = time.Minute
+
+func NewHomeworkCloser(store db.Store, interval time.Duration) *HomeworkCloser {
+	if interval <= 0 {
+		interval = defaultCloserInterval
+	}
+
+	return &HomeworkCloser{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start runs the closer until ctx is cancelled
+func (closer *HomeworkCloser) Start(ctx context.Context) {
+	ticker := time.NewTicker(closer.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := closer.CloseOverdue(ctx, time.Now()); err != nil {
+			log.Println("cannot close overdue homeworks:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CloseOverdue closes every open homework due at or before now
+func (closer *HomeworkCloser) CloseOverdue(ctx context.Context, now time.Time) ([]db.Homework, error) {
+	homeworks, err := closer.store.CloseOverdueHomeworks(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, homework := range homeworks {
+		log.Printf("closed homework %d, due at %s", homework.ID, homework.DueAt.Time.Format(time.RFC3339))
+	}
+
+	return homeworks, nil
+}
@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseOverdue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	homework := db.Homework{
+		ID:        util.RandomInt(1, 100),
+		TeacherID: util.RandomInt(1, 100),
+		Subject:   util.RandomSubject(),
+		Title:     util.RandomString(10),
+		IsClosed:  true,
+		ClosedAt:  now.Add(-time.Minute),
+		DueAt:     sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+	}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CloseOverdueHomeworks(gomock.Any(), gomock.Eq(now)).
+		Times(1).
+		Return([]db.Homework{homework}, nil)
+
+	closer := NewHomeworkCloser(store, time.Minute)
+	homeworks, err := closer.CloseOverdue(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, homeworks, 1)
+	require.Equal(t, homework.ID, homeworks[0].ID)
+}
+
+func TestHomeworkCloserStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CloseOverdueHomeworks(gomock.Any(), gomock.Any()).
+		MinTimes(1).
+		DoAndReturn(func(_ context.Context, _ time.Time) ([]db.Homework, error) {
+			cancel()
+			return []db.Homework{}, nil
+		})
+
+	done := make(chan struct{})
+	go func() {
+		NewHomeworkCloser(store, time.Hour).Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("closer did not stop after context was cancelled")
+	}
+}
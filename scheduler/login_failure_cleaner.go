@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/dongocanh96/class_manager_go/db/sqlc"
+)
+
+// LoginFailureCleaner periodically deletes the failed logins that no longer
+// count towards a lockout
+type LoginFailureCleaner struct {
+	store  db.Store
+	window time.Duration
+}
+
+// defaultLoginFailureWindow matches the default longest login lockout
+const defaultLoginFailureWindow = time.Hour
+
+// NewLoginFailureCleaner returns a cleaner for failures older than window,
+// the longest login lockout. It runs once every window.
+func NewLoginFailureCleaner(store db.Store, window time.Duration) *LoginFailureCleaner {
+	if window <= 0 {
+		window = defaultLoginFailureWindow
+	}
+
+	return &LoginFailureCleaner{
+		store:  store,
+		window: window,
+	}
+}
+
+// Start runs the cleaner until ctx is cancelled
+func (cleaner *LoginFailureCleaner) Start(ctx context.Context) {
+	ticker := time.NewTicker(cleaner.window)
+	defer ticker.Stop()
+
+	for {
+		if err := cleaner.DeleteExpired(ctx, time.Now()); err != nil {
+			log.Println("cannot delete expired login failures:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeleteExpired deletes the failures that are older than the window and no
+// longer lock anyone out at now
+func (cleaner *LoginFailureCleaner) DeleteExpired(ctx context.Context, now time.Time) error {
+	return cleaner.store.DeleteExpiredLoginFailures(ctx, now.Add(-cleaner.window))
+}
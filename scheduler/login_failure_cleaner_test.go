@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/dongocanh96/class_manager_go/db/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteExpiredLoginFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().DeleteExpiredLoginFailures(gomock.Any(), gomock.Eq(now.Add(-30*time.Minute))).
+		Times(1).
+		Return(nil)
+
+	cleaner := NewLoginFailureCleaner(store, 30*time.Minute)
+	require.NoError(t, cleaner.DeleteExpired(context.Background(), now))
+}
+
+func TestLoginFailureCleanerDefaultWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().DeleteExpiredLoginFailures(gomock.Any(), gomock.Eq(now.Add(-defaultLoginFailureWindow))).
+		Times(1).
+		Return(nil)
+
+	cleaner := NewLoginFailureCleaner(store, 0)
+	require.NoError(t, cleaner.DeleteExpired(context.Background(), now))
+}
+
+func TestLoginFailureCleanerStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().DeleteExpiredLoginFailures(gomock.Any(), gomock.Any()).
+		MinTimes(1).
+		DoAndReturn(func(_ context.Context, _ time.Time) error {
+			cancel()
+			return nil
+		})
+
+	done := make(chan struct{})
+	go func() {
+		NewLoginFailureCleaner(store, time.Hour).Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("cleaner did not stop after context was cancelled")
+	}
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore keeps files on the local filesystem under a root directory
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+
+	return &LocalStore{root}, nil
+}
+
+// path resolves key inside the root directory. Keys are flat names, anything
+// that could escape the root (separators, "..") is rejected.
+func (store *LocalStore) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || key != filepath.Base(key) || strings.ContainsAny(key, `/\`) {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(store.root, key), nil
+}
+
+func (store *LocalStore) Save(ctx context.Context, key string, file io.Reader, size int64, contentType string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, file)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// a partial file would be served as if it were complete
+		os.Remove(path)
+		return err
+	}
+
+	return nil
+}
+
+func (store *LocalStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrFileNotFound
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (store *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrFileNotFound
+	}
+
+	return err
+}
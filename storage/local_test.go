@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	testFileStore(t, store)
+}
+
+func TestLocalStoreInvalidKey(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	keys := []string{"", ".", "..", "../../app.env", "/etc/passwd", "sub/file.txt", `..\\app.env`}
+	for _, key := range keys {
+		err = store.Save(context.Background(), key, strings.NewReader("data"), 4, "text/plain")
+		require.EqualError(t, err, ErrInvalidKey.Error(), key)
+
+		_, err = store.Open(context.Background(), key)
+		require.EqualError(t, err, ErrInvalidKey.Error(), key)
+
+		err = store.Delete(context.Background(), key)
+		require.EqualError(t, err, ErrInvalidKey.Error(), key)
+	}
+}
+
+func TestLocalStoreLegacyFile(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root)
+	require.NoError(t, err)
+
+	// files uploaded before keys were generated sit in the root under their
+	// client file name, which the saved_path migration turns into their key
+	name := "homework 1.pdf"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, name), []byte("data"), 0644))
+
+	file, err := store.Open(context.Background(), name)
+	require.NoError(t, err)
+	defer file.Close()
+
+	data, err := ioutil.ReadAll(file)
+	require.NoError(t, err)
+	require.Equal(t, "data", string(data))
+}
+
+func TestLocalStoreFailedSave(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	key := util.RandomString(10)
+	errRead := errors.New("connection reset")
+
+	// the upload breaks off half way, nothing is left behind
+	file := io.MultiReader(strings.NewReader("partial"), &failingReader{errRead})
+	err = store.Save(ctx, key, file, 64, "text/plain")
+	require.ErrorIs(t, err, errRead)
+
+	_, err = store.Open(ctx, key)
+	require.EqualError(t, err, ErrFileNotFound.Error())
+
+	// and the key can be saved again
+	err = store.Save(ctx, key, strings.NewReader("data"), 4, "text/plain")
+	require.NoError(t, err)
+}
+
+type failingReader struct {
+	err error
+}
+
+func (reader *failingReader) Read(p []byte) (int, error) {
+	return 0, reader.err
+}
+
+func testFileStore(t *testing.T, store FileStore) {
+	ctx := context.Background()
+	key := util.RandomString(10)
+	content := util.RandomString(64)
+
+	err := store.Save(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain")
+	require.NoError(t, err)
+
+	file, err := store.Open(ctx, key)
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(file)
+	require.NoError(t, err)
+	require.Equal(t, content, string(data))
+
+	offset, err := file.Seek(10, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(10), offset)
+
+	data, err = ioutil.ReadAll(file)
+	require.NoError(t, err)
+	require.Equal(t, content[10:], string(data))
+	require.NoError(t, file.Close())
+
+	err = store.Delete(ctx, key)
+	require.NoError(t, err)
+
+	_, err = store.Open(ctx, key)
+	require.EqualError(t, err, ErrFileNotFound.Error())
+
+	err = store.Delete(ctx, key)
+	require.EqualError(t, err, ErrFileNotFound.Error())
+}
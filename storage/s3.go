@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UseSSL          bool
+}
+
+// S3Store keeps files in a bucket of an S3-compatible object storage,
+// so several server replicas can share the same files
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Store(config S3Config) (*S3Store, error) {
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKeyID, config.SecretAccessKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Store{client: client, bucket: config.Bucket}, nil
+}
+
+func (store *S3Store) Save(ctx context.Context, key string, file io.Reader, size int64, contentType string) error {
+	_, err := store.client.PutObject(ctx, store.bucket, key, file, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (store *S3Store) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	object, err := store.client.GetObject(ctx, store.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, convertS3Error(err)
+	}
+
+	// GetObject is lazy, stat the object so a missing key is reported here
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, convertS3Error(err)
+	}
+
+	return object, nil
+}
+
+func (store *S3Store) Delete(ctx context.Context, key string) error {
+	_, err := store.client.StatObject(ctx, store.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return convertS3Error(err)
+	}
+
+	err = store.client.RemoveObject(ctx, store.bucket, key, minio.RemoveObjectOptions{})
+	return convertS3Error(err)
+}
+
+func convertS3Error(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	rsp := minio.ToErrorResponse(err)
+	if rsp.StatusCode == http.StatusNotFound || rsp.Code == "NoSuchKey" {
+		return ErrFileNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 is a minimal in-memory stand-in for an S3-compatible server,
+// supporting the object operations used by S3Store
+type fakeS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func (s3 *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s3.mutex.Lock()
+	defer s3.mutex.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodPut:
+		data, err := ioutil.ReadAll(r.Body)
+		if err == nil && r.Header.Get("X-Amz-Content-Sha256") == streamingPayload {
+			data, err = decodeAWSChunked(data)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s3.objects[path] = data
+		w.Header().Set("ETag", `"fake-etag"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		data, ok := s3.objects[path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+			}
+			return
+		}
+		w.Header().Set("ETag", `"fake-etag"`)
+		http.ServeContent(w, r, path, time.Now(), bytes.NewReader(data))
+	case http.MethodDelete:
+		delete(s3.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+const streamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+// decodeAWSChunked strips the per-chunk signatures of a streaming upload
+func decodeAWSChunked(body []byte) ([]byte, error) {
+	var data []byte
+	for len(body) > 0 {
+		end := bytes.Index(body, []byte("\r\n"))
+		if end < 0 {
+			return nil, errors.New("malformed chunk header")
+		}
+
+		header := strings.SplitN(string(body[:end]), ";", 2)
+		size, err := strconv.ParseInt(header[0], 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		body = body[end+2:]
+		if size == 0 {
+			break
+		}
+		if int64(len(body)) < size+2 {
+			return nil, errors.New("truncated chunk")
+		}
+
+		data = append(data, body[:size]...)
+		body = body[size+2:]
+	}
+
+	return data, nil
+}
+
+func TestS3Store(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{objects: make(map[string][]byte)})
+	defer server.Close()
+
+	store, err := NewS3Store(S3Config{
+		Endpoint:        strings.TrimPrefix(server.URL, "http://"),
+		Region:          "us-east-1",
+		Bucket:          "class-manager",
+		AccessKeyID:     "minioadmin",
+		SecretAccessKey: "minioadmin",
+		UseSSL:          false,
+	})
+	require.NoError(t, err)
+
+	testFileStore(t, store)
+}
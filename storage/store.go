@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dongocanh96/class_manager_go/util"
+)
+
+var (
+	ErrFileNotFound = errors.New("file not found")
+	ErrInvalidKey   = errors.New("invalid file key")
+)
+
+// FileStore saves, opens and deletes uploaded files identified by a key
+type FileStore interface {
+	Save(ctx context.Context, key string, file io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// NewFileStore creates the file store selected by config.StorageBackend
+func NewFileStore(config util.Config) (FileStore, error) {
+	switch config.StorageBackend {
+	case "", BackendLocal:
+		return NewLocalStore(config.Asset)
+	case BackendS3:
+		return NewS3Store(S3Config{
+			Endpoint:        config.S3Endpoint,
+			Region:          config.S3Region,
+			Bucket:          config.S3Bucket,
+			AccessKeyID:     config.S3AccessKeyID,
+			SecretAccessKey: config.S3SecretAccessKey,
+			UseSSL:          config.S3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported storage backend %s", config.StorageBackend)
+	}
+}
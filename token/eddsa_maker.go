@@ -0,0 +1,92 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// EdDSAMaker signs JSON web tokens with an Ed25519 key
+type EdDSAMaker struct {
+	keyID      string
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+func NewEdDSAMaker(privateKey ed25519.PrivateKey) (Maker, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d bytes", ed25519.PrivateKeySize)
+	}
+
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	return &EdDSAMaker{edKeyID(publicKey), privateKey, publicKey}, nil
+}
+
+func (maker *EdDSAMaker) CreateToken(userid int64, username string, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(userid, username, role, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, payload)
+	jwtToken.Header["kid"] = maker.keyID
+	token, err := jwtToken.SignedString(maker.privateKey)
+	return token, payload, err
+}
+
+func (maker *EdDSAMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		_, ok := token.Method.(*jwt.SigningMethodEd25519)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		keyID, _ := token.Header["kid"].(string)
+		if keyID != maker.keyID {
+			return nil, ErrInvalidToken
+		}
+		return maker.publicKey, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		verr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+func (maker *EdDSAMaker) JWKS() JSONWebKeySet {
+	return JSONWebKeySet{Keys: []JSONWebKey{{
+		KeyType:   "OKP",
+		Use:       "sig",
+		Algorithm: jwt.SigningMethodEdDSA.Alg(),
+		KeyID:     maker.keyID,
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(maker.publicKey),
+	}}}
+}
+
+// edKeyID returns the RFC 8037 thumbprint of the key
+func edKeyID(publicKey ed25519.PublicKey) string {
+	thumbprint := fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`,
+		base64.RawURLEncoding.EncodeToString(publicKey))
+
+	sum := sha256.Sum256([]byte(thumbprint))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -1,30 +1,34 @@
 package token
 
 import (
-	"crypto/rsa"
+	"context"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 )
 
+// JWTMaker signs RS256 JSON web tokens with the active key of a key set
 type JWTMaker struct {
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
+	keys *KeySet
 }
 
-func NewJWTMaker(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) (*JWTMaker, error) {
-	return &JWTMaker{privateKey, publicKey}, nil
+func NewJWTMaker(keys *KeySet) (Maker, error) {
+	return &JWTMaker{keys}, nil
 }
 
-func (maker *JWTMaker) CreateToken(userid int64, username string, isteacher bool, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(userid, username, isteacher, duration)
+func (maker *JWTMaker) CreateToken(userid int64, username string, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(userid, username, role, sessionID, duration)
 	if err != nil {
 		return "", payload, err
 	}
 
+	keyID, privateKey := maker.keys.active()
+
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodRS256, payload)
-	token, err := jwtToken.SignedString(maker.privateKey)
+	jwtToken.Header["kid"] = keyID
+	token, err := jwtToken.SignedString(privateKey)
 	return token, payload, err
 }
 
@@ -34,7 +38,13 @@ func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 		if !ok {
 			return nil, ErrInvalidToken
 		}
-		return maker.publicKey, nil
+
+		keyID, _ := token.Header["kid"].(string)
+		publicKey, ok := maker.keys.publicKey(keyID)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return publicKey, nil
 	}
 
 	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
@@ -53,3 +63,12 @@ func (maker *JWTMaker) VerifyToken(token string) (*Payload, error) {
 
 	return payload, nil
 }
+
+func (maker *JWTMaker) JWKS() JSONWebKeySet {
+	return maker.keys.JWKS()
+}
+
+// Watch reloads the key set every interval until ctx is cancelled
+func (maker *JWTMaker) Watch(ctx context.Context, interval time.Duration) {
+	maker.keys.Watch(ctx, interval)
+}
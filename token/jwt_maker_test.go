@@ -1,110 +1,155 @@
 package token
 
 import (
-	"crypto/rsa"
+	"crypto/ed25519"
+	"crypto/rand"
 	"io/ioutil"
 	"testing"
 	"time"
 
 	"github.com/dongocanh96/class_manager_go/util"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
-func getKeyPairData(t *testing.T) (privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) {
+func newTestKeySet(t *testing.T) *KeySet {
 	privateKeyByte, err := ioutil.ReadFile("../private.pem")
-	if err != nil {
-		panic(err)
-	}
+	require.NoError(t, err)
 
-	privatekey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyByte)
-	if err != nil {
-		panic(err)
-	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyByte)
+	require.NoError(t, err)
 
-	publicKeyByte, err := ioutil.ReadFile("../public.pem")
-	if err != nil {
-		panic(err)
-	}
+	return NewKeySet(privateKey)
+}
 
-	publickey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyByte)
-	if err != nil {
-		panic(err)
-	}
+func newTestEdKey(t *testing.T) ed25519.PrivateKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
 
-	return privatekey, publickey
+	return privateKey
 }
 
-func TestJWTMaker(t *testing.T) {
-	privateKey, publicKey := getKeyPairData(t)
-	maker, err := NewJWTMaker(privateKey, publicKey)
+// testMakers returns one maker of every implementation
+func testMakers(t *testing.T) map[string]Maker {
+	jwtMaker, err := NewJWTMaker(newTestKeySet(t))
 	require.NoError(t, err)
 
-	userid := util.RandomInt(1, 15)
-	username := util.RandomString(8)
-	isTeacher := util.RandomBoolean()
-	duration := time.Minute
-	issuedAt := time.Now()
-	expiredAt := issuedAt.Add(duration)
-
-	token, payload, err := maker.CreateToken(userid, username, isTeacher, duration)
+	eddsaMaker, err := NewEdDSAMaker(newTestEdKey(t))
 	require.NoError(t, err)
-	require.NotEmpty(t, payload)
-	require.NotEmpty(t, token)
 
-	payload, err = maker.VerifyToken(token)
+	pasetoLocalMaker, err := NewPasetoLocalMaker([]byte(util.RandomString(32)))
 	require.NoError(t, err)
-	require.NotEmpty(t, payload)
-
-	require.NotZero(t, payload.ID)
-	require.Equal(t, userid, payload.Userid)
-	require.Equal(t, username, payload.Username)
-	require.Equal(t, isTeacher, payload.IsTeacher)
-	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
-	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
-}
 
-func TestExpiredJWTToken(t *testing.T) {
-	privateKey, publicKey := getKeyPairData(t)
-	maker, err := NewJWTMaker(privateKey, publicKey)
+	pasetoPublicMaker, err := NewPasetoPublicMaker(newTestEdKey(t))
 	require.NoError(t, err)
 
-	userid := util.RandomInt(1, 15)
-	username := util.RandomString(8)
-	isTeacher := util.RandomBoolean()
-	duration := -time.Minute
+	return map[string]Maker{
+		TypeJWT:          jwtMaker,
+		TypeJWTEdDSA:     eddsaMaker,
+		TypePasetoLocal:  pasetoLocalMaker,
+		TypePasetoPublic: pasetoPublicMaker,
+	}
+}
 
-	token, payload, err := maker.CreateToken(userid, username, isTeacher, duration)
-	require.NoError(t, err)
-	require.NotEmpty(t, payload)
-	require.NotEmpty(t, token)
+func TestJWTMaker(t *testing.T) {
+	for name, maker := range testMakers(t) {
+		maker := maker
+
+		t.Run(name, func(t *testing.T) {
+			userid := util.RandomInt(1, 15)
+			username := util.RandomString(8)
+			role := util.RandomRole()
+			sessionID := uuid.New()
+			duration := time.Minute
+			issuedAt := time.Now()
+			expiredAt := issuedAt.Add(duration)
+
+			token, payload, err := maker.CreateToken(userid, username, role, sessionID, duration)
+			require.NoError(t, err)
+			require.NotEmpty(t, payload)
+			require.NotEmpty(t, token)
+
+			payload, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.NotEmpty(t, payload)
+
+			require.NotZero(t, payload.ID)
+			require.Equal(t, userid, payload.Userid)
+			require.Equal(t, username, payload.Username)
+			require.Equal(t, role, payload.Role)
+			require.Equal(t, sessionID, payload.SessionID)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+			require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+		})
+	}
+}
 
-	payload, err = maker.VerifyToken(token)
-	require.Error(t, err)
-	require.EqualError(t, err, ErrExpiredToken.Error())
-	require.Nil(t, payload)
+func TestExpiredJWTToken(t *testing.T) {
+	for name, maker := range testMakers(t) {
+		maker := maker
+
+		t.Run(name, func(t *testing.T) {
+			userid := util.RandomInt(1, 15)
+			username := util.RandomString(8)
+			role := util.RandomRole()
+			duration := -time.Minute
+
+			token, payload, err := maker.CreateToken(userid, username, role, uuid.New(), duration)
+			require.NoError(t, err)
+			require.NotEmpty(t, payload)
+			require.NotEmpty(t, token)
+
+			payload, err = maker.VerifyToken(token)
+			require.Error(t, err)
+			require.EqualError(t, err, ErrExpiredToken.Error())
+			require.Nil(t, payload)
+		})
+	}
 }
 
 func TestInvalidJWTTokenAlgNone(t *testing.T) {
 	userid := util.RandomInt(1, 15)
 	username := util.RandomString(8)
-	isTeacher := util.RandomBoolean()
+	role := util.RandomRole()
 	duration := time.Minute
 
-	payload, err := NewPayload(userid, username, isTeacher, duration)
+	payload, err := NewPayload(userid, username, role, uuid.New(), duration)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
 	token, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
 	require.NoError(t, err)
 
-	privateKey, publicKey := getKeyPairData(t)
-	maker, err := NewJWTMaker(privateKey, publicKey)
-	require.NoError(t, err)
+	for name, maker := range testMakers(t) {
+		maker := maker
+
+		t.Run(name, func(t *testing.T) {
+			payload, err := maker.VerifyToken(token)
+			require.Error(t, err)
+			require.EqualError(t, err, ErrInvalidToken.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestTokenFromOtherMaker(t *testing.T) {
+	makers := testMakers(t)
+	others := testMakers(t)
 
-	payload, err = maker.VerifyToken(token)
-	require.Error(t, err)
-	require.EqualError(t, err, ErrInvalidToken.Error())
-	require.Nil(t, payload)
+	for name, maker := range makers {
+		token, _, err := maker.CreateToken(util.RandomInt(1, 15), util.RandomString(8), util.RandomRole(), uuid.New(), time.Minute)
+		require.NoError(t, err)
 
+		for otherName, other := range others {
+			// the RS256 makers share the key of the repository
+			if name == TypeJWT && otherName == TypeJWT {
+				continue
+			}
+
+			payload, err := other.VerifyToken(token)
+			require.EqualError(t, err, ErrInvalidToken.Error(), "%s token verified by %s", name, otherName)
+			require.Nil(t, payload)
+		}
+	}
 }
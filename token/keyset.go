@@ -0,0 +1,239 @@
+package token
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// activeKeyFile names the file of a key directory holding the id of the
+// signing key
+const activeKeyFile = "active"
+
+const defaultKeyReloadInterval = time.Minute
+
+// KeySet holds every key tokens are verified with and the one new tokens are
+// signed with. Keys loaded from a directory can be reloaded while the server
+// runs, so a key is rotated by adding the new one, making it active and
+// removing the old one once the tokens it signed have expired.
+type KeySet struct {
+	dir        string
+	mu         sync.RWMutex
+	activeID   string
+	signingKey *rsa.PrivateKey
+	publicKeys map[string]*rsa.PublicKey
+}
+
+// NewKeySet creates a key set from a single key pair, its id is the
+// thumbprint of the public key
+func NewKeySet(privateKey *rsa.PrivateKey) *KeySet {
+	keyID := KeyID(&privateKey.PublicKey)
+
+	return &KeySet{
+		activeID:   keyID,
+		signingKey: privateKey,
+		publicKeys: map[string]*rsa.PublicKey{keyID: &privateKey.PublicKey},
+	}
+}
+
+// LoadKeySet loads the keys of dir. Every <kid>.pem file is a key, private
+// keys can sign and verify while public keys only verify. The active file
+// holds the kid of the signing key, it can be left out when there is only one
+// private key.
+func LoadKeySet(dir string) (*KeySet, error) {
+	keys := &KeySet{dir: dir}
+
+	err := keys.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Reload reads the key directory again. The current keys are kept when the
+// directory does not hold a usable key set.
+func (keys *KeySet) Reload() error {
+	if keys.dir == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(keys.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	privateKeys := make(map[string]*rsa.PrivateKey)
+	publicKeys := make(map[string]*rsa.PublicKey)
+	for _, file := range files {
+		keyID := strings.TrimSuffix(filepath.Base(file), ".pem")
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			privateKeys[keyID] = privateKey
+			publicKeys[keyID] = &privateKey.PublicKey
+			continue
+		}
+
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return fmt.Errorf("cannot parse key %s: %w", file, err)
+		}
+		publicKeys[keyID] = publicKey
+	}
+
+	activeID, err := readActiveKeyID(keys.dir, privateKeys)
+	if err != nil {
+		return err
+	}
+
+	signingKey, ok := privateKeys[activeID]
+	if !ok {
+		return fmt.Errorf("no private key for active key %q", activeID)
+	}
+
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+
+	keys.activeID = activeID
+	keys.signingKey = signingKey
+	keys.publicKeys = publicKeys
+
+	return nil
+}
+
+func readActiveKeyID(dir string, privateKeys map[string]*rsa.PrivateKey) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, activeKeyFile))
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if len(privateKeys) != 1 {
+		return "", fmt.Errorf("%d private keys in %s and no %s file", len(privateKeys), dir, activeKeyFile)
+	}
+	for keyID := range privateKeys {
+		return keyID, nil
+	}
+	return "", nil
+}
+
+// Watch reloads the key directory every interval until ctx is cancelled
+func (keys *KeySet) Watch(ctx context.Context, interval time.Duration) {
+	if keys.dir == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultKeyReloadInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := keys.Reload(); err != nil {
+			log.Println("cannot reload signing keys:", err)
+		}
+	}
+}
+
+func (keys *KeySet) active() (string, *rsa.PrivateKey) {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	return keys.activeID, keys.signingKey
+}
+
+// publicKey returns the key with the given id, tokens signed before key ids
+// were used have none and are checked with the active key
+func (keys *KeySet) publicKey(keyID string) (*rsa.PublicKey, bool) {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	if keyID == "" {
+		keyID = keys.activeID
+	}
+
+	publicKey, ok := keys.publicKeys[keyID]
+	return publicKey, ok
+}
+
+// JSONWebKey is the public part of a signing key as described in RFC 7517,
+// N and E are set for RSA keys and Curve and X for Ed25519 keys
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS returns every verification key of the set
+func (keys *KeySet) JWKS() JSONWebKeySet {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(keys.publicKeys))}
+	for keyID, publicKey := range keys.publicKeys {
+		set.Keys = append(set.Keys, JSONWebKey{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: jwt.SigningMethodRS256.Alg(),
+			KeyID:     keyID,
+			N:         encodeBigInt(publicKey.N),
+			E:         encodeBigInt(big.NewInt(int64(publicKey.E))),
+		})
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+
+	return set
+}
+
+// KeyID returns the RFC 7638 thumbprint of the key
+func KeyID(publicKey *rsa.PublicKey) string {
+	thumbprint := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`,
+		encodeBigInt(big.NewInt(int64(publicKey.E))), encodeBigInt(publicKey.N))
+
+	sum := sha256.Sum256([]byte(thumbprint))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
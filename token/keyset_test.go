@@ -0,0 +1,158 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func writePrivateKey(t *testing.T, dir string, keyID string) *rsa.PrivateKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})
+	err = ioutil.WriteFile(filepath.Join(dir, keyID+".pem"), data, 0600)
+	require.NoError(t, err)
+
+	return privateKey
+}
+
+func writePublicKey(t *testing.T, dir string, keyID string, publicKey *rsa.PublicKey) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	err = ioutil.WriteFile(filepath.Join(dir, keyID+".pem"), data, 0644)
+	require.NoError(t, err)
+}
+
+func setActiveKey(t *testing.T, dir string, keyID string) {
+	err := ioutil.WriteFile(filepath.Join(dir, activeKeyFile), []byte(keyID+"\n"), 0644)
+	require.NoError(t, err)
+}
+
+func tokenKeyID(t *testing.T, token string) string {
+	jwtToken, _, err := new(jwt.Parser).ParseUnverified(token, &Payload{})
+	require.NoError(t, err)
+
+	keyID, _ := jwtToken.Header["kid"].(string)
+	return keyID
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := writePrivateKey(t, dir, "2022-10")
+
+	keys, err := LoadKeySet(dir)
+	require.NoError(t, err)
+
+	maker, err := NewJWTMaker(keys)
+	require.NoError(t, err)
+
+	oldToken, _, err := maker.CreateToken(util.RandomInt(1, 15), util.RandomString(8), util.RandomRole(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "2022-10", tokenKeyID(t, oldToken))
+
+	// the new key signs, the old one still verifies
+	writePrivateKey(t, dir, "2022-11")
+	setActiveKey(t, dir, "2022-11")
+	require.NoError(t, keys.Reload())
+
+	newToken, _, err := maker.CreateToken(util.RandomInt(1, 15), util.RandomString(8), util.RandomRole(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "2022-11", tokenKeyID(t, newToken))
+
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	// keeping only the public part of the old key is enough to verify
+	writePublicKey(t, dir, "2022-10", &oldKey.PublicKey)
+	require.NoError(t, keys.Reload())
+
+	_, err = maker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	// retiring the old key rejects what it signed
+	require.NoError(t, os.Remove(filepath.Join(dir, "2022-10.pem")))
+	require.NoError(t, keys.Reload())
+
+	_, err = maker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	_, err = maker.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	require.Equal(t, "2022-11", jwks.Keys[0].KeyID)
+}
+
+func TestReloadKeepsKeysOnError(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "first")
+
+	keys, err := LoadKeySet(dir)
+	require.NoError(t, err)
+
+	// two private keys and nothing saying which one signs
+	writePrivateKey(t, dir, "second")
+	require.Error(t, keys.Reload())
+
+	setActiveKey(t, dir, "missing")
+	require.Error(t, keys.Reload())
+
+	keyID, _ := keys.active()
+	require.Equal(t, "first", keyID)
+}
+
+func TestTokenWithoutKeyID(t *testing.T) {
+	keys := newTestKeySet(t)
+	maker, err := NewJWTMaker(keys)
+	require.NoError(t, err)
+
+	payload, err := NewPayload(util.RandomInt(1, 15), util.RandomString(8), util.RandomRole(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	_, privateKey := keys.active()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, payload).SignedString(privateKey)
+	require.NoError(t, err)
+
+	verified, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+}
+
+func TestJWKS(t *testing.T) {
+	keys := newTestKeySet(t)
+	keyID, privateKey := keys.active()
+	require.Equal(t, KeyID(&privateKey.PublicKey), keyID)
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 1)
+
+	key := jwks.Keys[0]
+	require.Equal(t, "RSA", key.KeyType)
+	require.Equal(t, "RS256", key.Algorithm)
+	require.Equal(t, keyID, key.KeyID)
+	require.Equal(t, "AQAB", key.E)
+
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	require.NoError(t, err)
+	require.Equal(t, privateKey.N.Bytes(), n)
+}
@@ -0,0 +1,93 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// Maker creates and verifies access and refresh tokens
+type Maker interface {
+	CreateToken(userid int64, username string, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error)
+	VerifyToken(token string) (*Payload, error)
+}
+
+// KeyPublisher is implemented by makers whose tokens other services can verify
+// with the published public keys
+type KeyPublisher interface {
+	JWKS() JSONWebKeySet
+}
+
+const (
+	TypeJWT          = "jwt"
+	TypeJWTEdDSA     = "jwt_eddsa"
+	TypePasetoLocal  = "paseto_local"
+	TypePasetoPublic = "paseto_public"
+)
+
+// NewMaker creates the token maker selected by config.TokenType
+func NewMaker(config util.Config) (Maker, error) {
+	switch config.TokenType {
+	case "", TypeJWT:
+		keys, err := loadKeySet(config)
+		if err != nil {
+			return nil, err
+		}
+		return NewJWTMaker(keys)
+	case TypeJWTEdDSA:
+		privateKey, err := loadEdPrivateKey(config.PrivateKeyLocation)
+		if err != nil {
+			return nil, err
+		}
+		return NewEdDSAMaker(privateKey)
+	case TypePasetoLocal:
+		return NewPasetoLocalMaker([]byte(config.TokenSymmetricKey))
+	case TypePasetoPublic:
+		privateKey, err := loadEdPrivateKey(config.PrivateKeyLocation)
+		if err != nil {
+			return nil, err
+		}
+		return NewPasetoPublicMaker(privateKey)
+	default:
+		return nil, fmt.Errorf("unsupported token type %s", config.TokenType)
+	}
+}
+
+// loadKeySet loads the signing keys from KEY_DIRECTORY, or the single key of
+// PRIVATE_KEY_LOCATION when no directory is configured
+func loadKeySet(config util.Config) (*KeySet, error) {
+	if config.KeyDirectory != "" {
+		return LoadKeySet(config.KeyDirectory)
+	}
+
+	privateKeyByte, err := ioutil.ReadFile(config.PrivateKeyLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyByte)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeySet(privateKey), nil
+}
+
+func loadEdPrivateKey(location string) (ed25519.PrivateKey, error) {
+	privateKeyByte, err := ioutil.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := jwt.ParseEdPrivateKeyFromPEM(privateKeyByte)
+	if err != nil {
+		return nil, err
+	}
+
+	return privateKey.(ed25519.PrivateKey), nil
+}
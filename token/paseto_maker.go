@@ -0,0 +1,161 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
+)
+
+// pasetoSymmetricKeySize is the key size of PASETO v4.local
+const pasetoSymmetricKeySize = 32
+
+// PasetoLocalMaker creates PASETO v4.local tokens, encrypted with a shared key
+type PasetoLocalMaker struct {
+	symmetricKey paseto.V4SymmetricKey
+}
+
+func NewPasetoLocalMaker(symmetricKey []byte) (Maker, error) {
+	if len(symmetricKey) != pasetoSymmetricKeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", pasetoSymmetricKeySize)
+	}
+
+	key, err := paseto.V4SymmetricKeyFromBytes(symmetricKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasetoLocalMaker{key}, nil
+}
+
+func (maker *PasetoLocalMaker) CreateToken(userid int64, username string, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, token, err := newPasetoToken(userid, username, role, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return token.V4Encrypt(maker.symmetricKey, nil), payload, nil
+}
+
+func (maker *PasetoLocalMaker) VerifyToken(token string) (*Payload, error) {
+	parsed, err := pasetoParser().ParseV4Local(maker.symmetricKey, token, nil)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return pasetoPayload(parsed)
+}
+
+// PasetoPublicMaker creates PASETO v4.public tokens, signed with an Ed25519 key
+type PasetoPublicMaker struct {
+	secretKey paseto.V4AsymmetricSecretKey
+	publicKey paseto.V4AsymmetricPublicKey
+}
+
+func NewPasetoPublicMaker(privateKey ed25519.PrivateKey) (Maker, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d bytes", ed25519.PrivateKeySize)
+	}
+
+	secretKey, err := paseto.NewV4AsymmetricSecretKeyFromEd25519(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasetoPublicMaker{secretKey, secretKey.Public()}, nil
+}
+
+func (maker *PasetoPublicMaker) CreateToken(userid int64, username string, role string, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, token, err := newPasetoToken(userid, username, role, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return token.V4Sign(maker.secretKey, nil), payload, nil
+}
+
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	parsed, err := pasetoParser().ParseV4Public(maker.publicKey, token, nil)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return pasetoPayload(parsed)
+}
+
+// newPasetoToken puts the payload in the registered claims where PASETO has
+// one for it. The claims keep whole seconds, so the payload is truncated to
+// match what the token carries.
+func newPasetoToken(userid int64, username string, role string, sessionID uuid.UUID, duration time.Duration) (*Payload, paseto.Token, error) {
+	payload, err := NewPayload(userid, username, role, sessionID, duration)
+	if err != nil {
+		return payload, paseto.Token{}, err
+	}
+	payload.IssuedAt = payload.IssuedAt.Truncate(time.Second)
+	payload.ExpiredAt = payload.ExpiredAt.Truncate(time.Second)
+
+	token := paseto.NewToken()
+	token.SetJti(payload.ID.String())
+	token.SetSubject(username)
+	token.SetIssuedAt(payload.IssuedAt)
+	token.SetNotBefore(payload.IssuedAt)
+	token.SetExpiration(payload.ExpiredAt)
+	if err := token.Set("userid", userid); err != nil {
+		return payload, paseto.Token{}, err
+	}
+	token.SetString("role", role)
+	token.SetString("session_id", sessionID.String())
+
+	return payload, token, nil
+}
+
+// pasetoParser checks nbf, expiry is left to Payload.Valid so it can be told
+// apart from an invalid token
+func pasetoParser() paseto.Parser {
+	return paseto.MakeParser([]paseto.Rule{paseto.NotBeforeNbf()})
+}
+
+func pasetoPayload(token *paseto.Token) (*Payload, error) {
+	payload := &Payload{}
+
+	jti, err := token.GetJti()
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if payload.ID, err = uuid.Parse(jti); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := token.Get("userid", &payload.Userid); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if payload.Username, err = token.GetSubject(); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if payload.Role, err = token.GetString("role"); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	sessionID, err := token.GetString("session_id")
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if payload.SessionID, err = uuid.Parse(sessionID); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if payload.IssuedAt, err = token.GetIssuedAt(); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if payload.ExpiredAt, err = token.GetExpiration(); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,78 @@
+package token
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/dongocanh96/class_manager_go/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasetoLocalMakerKeySize(t *testing.T) {
+	_, err := NewPasetoLocalMaker([]byte(util.RandomString(31)))
+	require.Error(t, err)
+}
+
+func TestTamperedPasetoToken(t *testing.T) {
+	makers := testMakers(t)
+
+	for _, name := range []string{TypePasetoLocal, TypePasetoPublic} {
+		maker := makers[name]
+
+		t.Run(name, func(t *testing.T) {
+			token, _, err := maker.CreateToken(util.RandomInt(1, 15), util.RandomString(8), util.RandomRole(), uuid.New(), time.Minute)
+			require.NoError(t, err)
+
+			dot := strings.LastIndex(token, ".")
+			body, err := base64.RawURLEncoding.DecodeString(token[dot+1:])
+			require.NoError(t, err)
+
+			body[len(body)/2] ^= 1
+			tampered := token[:dot+1] + base64.RawURLEncoding.EncodeToString(body)
+
+			payload, err := maker.VerifyToken(tampered)
+			require.EqualError(t, err, ErrInvalidToken.Error())
+			require.Nil(t, payload)
+
+			payload, err = maker.VerifyToken(token + ".Zm9vdGVy")
+			require.EqualError(t, err, ErrInvalidToken.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestPasetoRegisteredClaims(t *testing.T) {
+	key := []byte(util.RandomString(32))
+	maker, err := NewPasetoLocalMaker(key)
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(util.RandomInt(1, 15), util.RandomString(8), util.RandomRole(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	symmetricKey, err := paseto.V4SymmetricKeyFromBytes(key)
+	require.NoError(t, err)
+
+	// any PASETO implementation can read the token and enforce its times
+	parsed, err := paseto.NewParser().ParseV4Local(symmetricKey, token, nil)
+	require.NoError(t, err)
+
+	jti, err := parsed.GetJti()
+	require.NoError(t, err)
+	require.Equal(t, payload.ID.String(), jti)
+
+	issuedAt, err := parsed.GetIssuedAt()
+	require.NoError(t, err)
+	require.True(t, payload.IssuedAt.Equal(issuedAt))
+
+	notBefore, err := parsed.GetNotBefore()
+	require.NoError(t, err)
+	require.True(t, payload.IssuedAt.Equal(notBefore))
+
+	expiredAt, err := parsed.GetExpiration()
+	require.NoError(t, err)
+	require.True(t, payload.ExpiredAt.Equal(expiredAt))
+}
@@ -10,18 +10,22 @@ import (
 var (
 	ErrInvalidToken = errors.New("token is invalid")
 	ErrExpiredToken = errors.New("token has expired")
+	ErrRevokedToken = errors.New("token has been revoked")
 )
 
 type Payload struct {
 	ID        uuid.UUID `json:"id"`
 	Userid    int64     `json:"userid"`
 	Username  string    `json:"username"`
-	IsTeacher bool      `json:"is_teacher"`
+	Role      string    `json:"role"`
+	SessionID uuid.UUID `json:"session_id"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(userid int64, username string, isteacher bool, duration time.Duration) (*Payload, error) {
+// NewPayload creates the payload of a token. Access tokens carry the id of the
+// session they were issued for, refresh tokens are the session and use uuid.Nil.
+func NewPayload(userid int64, username string, role string, sessionID uuid.UUID, duration time.Duration) (*Payload, error) {
 	tokenId, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -31,7 +35,8 @@ func NewPayload(userid int64, username string, isteacher bool, duration time.Dur
 		ID:        tokenId,
 		Userid:    userid,
 		Username:  username,
-		IsTeacher: isteacher,
+		Role:      role,
+		SessionID: sessionID,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -7,15 +7,56 @@ import (
 )
 
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVER"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
-	SignUpKeyForTeacher  string        `mapstructure:"SIGN_UP_KEY_FOR_TEACHER"`
-	PrivateKeyLocation   string        `mapstructure:"PRIVATE_KEY_LOCATION"`
-	PublicKeyLocation    string        `mapstructure:"PUBLIC_KEY_LOCATION"`
-	Asset                string        `mapstructure:"ASSET"`
+	DBDriver                   string        `mapstructure:"DB_DRIVER"`
+	DBSource                   string        `mapstructure:"DB_SOURCE"`
+	ServerAddress              string        `mapstructure:"SERVER_ADDRESS"`
+	TrustedProxies             []string      `mapstructure:"TRUSTED_PROXIES"`
+	AccessTokenDuration        time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration       time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	TokenType                  string        `mapstructure:"TOKEN_TYPE"`
+	TokenSymmetricKey          string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	PrivateKeyLocation         string        `mapstructure:"PRIVATE_KEY_LOCATION"`
+	KeyDirectory               string        `mapstructure:"KEY_DIRECTORY"`
+	KeyReloadInterval          time.Duration `mapstructure:"KEY_RELOAD_INTERVAL"`
+	Asset                      string        `mapstructure:"ASSET"`
+	MaxHomeworkFileSize        int64         `mapstructure:"MAX_HOMEWORK_FILE_SIZE"`
+	MaxSolutionFileSize        int64         `mapstructure:"MAX_SOLUTION_FILE_SIZE"`
+	AllowedFileTypes           []string      `mapstructure:"ALLOWED_FILE_TYPES"`
+	HomeworkCloserInterval     time.Duration `mapstructure:"HOMEWORK_CLOSER_INTERVAL"`
+	StorageBackend             string        `mapstructure:"STORAGE_BACKEND"`
+	S3Endpoint                 string        `mapstructure:"S3_ENDPOINT"`
+	S3Region                   string        `mapstructure:"S3_REGION"`
+	S3Bucket                   string        `mapstructure:"S3_BUCKET"`
+	S3AccessKeyID              string        `mapstructure:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey          string        `mapstructure:"S3_SECRET_ACCESS_KEY"`
+	S3UseSSL                   bool          `mapstructure:"S3_USE_SSL"`
+	MailBackend                string        `mapstructure:"MAIL_BACKEND"`
+	MailSender                 string        `mapstructure:"MAIL_SENDER"`
+	MailDirectory              string        `mapstructure:"MAIL_DIRECTORY"`
+	SMTPHost                   string        `mapstructure:"SMTP_HOST"`
+	SMTPPort                   int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername               string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword               string        `mapstructure:"SMTP_PASSWORD"`
+	PasswordResetDuration      time.Duration `mapstructure:"PASSWORD_RESET_DURATION"`
+	ImportInviteDuration       time.Duration `mapstructure:"IMPORT_INVITE_DURATION"`
+	PublicURL                  string        `mapstructure:"PUBLIC_URL"`
+	EmailVerifyDuration        time.Duration `mapstructure:"EMAIL_VERIFY_DURATION"`
+	RequireVerifiedEmail       bool          `mapstructure:"REQUIRE_VERIFIED_EMAIL"`
+	LoginMaxAttempts           int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginMaxAttemptsPerIP      int           `mapstructure:"LOGIN_MAX_ATTEMPTS_PER_IP"`
+	LoginLockout               time.Duration `mapstructure:"LOGIN_LOCKOUT"`
+	LoginMaxLockout            time.Duration `mapstructure:"LOGIN_MAX_LOCKOUT"`
+	TOTPIssuer                 string        `mapstructure:"TOTP_ISSUER"`
+	MFAChallengeDuration       time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	RequireTeacherMFA          bool          `mapstructure:"REQUIRE_TEACHER_MFA"`
+	OIDCIssuer                 string        `mapstructure:"OIDC_ISSUER"`
+	OIDCClientID               string        `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret           string        `mapstructure:"OIDC_CLIENT_SECRET"`
+	OIDCRedirectURL            string        `mapstructure:"OIDC_REDIRECT_URL"`
+	PasswordMinLength          int           `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMinClasses         int           `mapstructure:"PASSWORD_MIN_CLASSES"`
+	PasswordHistory            int           `mapstructure:"PASSWORD_HISTORY"`
+	BreachedPasswordsDirectory string        `mapstructure:"BREACHED_PASSWORDS_DIRECTORY"`
 }
 
 // LoadConfig reads configuration from file or environment variables
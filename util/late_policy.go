@@ -0,0 +1,61 @@
+package util
+
+import (
+	"errors"
+	"time"
+)
+
+// maxLatePenalty caps the late penalty, in percent of the score
+const maxLatePenalty = 100
+
+var ErrSubmissionClosed = errors.New("submission is closed")
+
+// LatePolicy describes how a homework treats work submitted after its deadline
+type LatePolicy struct {
+	AllowLate     bool
+	GracePeriod   time.Duration
+	PenaltyPerDay int32
+	CutoffAt      time.Time
+}
+
+// Lateness is the result of evaluating a submission against a LatePolicy
+type Lateness struct {
+	IsLate  bool
+	LateBy  time.Duration
+	Penalty int32
+}
+
+// Evaluate checks a submission made at submittedAt against the deadline.
+// Work submitted within the grace period is on time, after that every
+// started day costs PenaltyPerDay percent, up to 100. ErrSubmissionClosed
+// is returned when late work is not allowed or the cutoff has passed.
+func (policy LatePolicy) Evaluate(deadline, submittedAt time.Time) (Lateness, error) {
+	if deadline.IsZero() || !submittedAt.After(deadline) {
+		return Lateness{}, nil
+	}
+
+	if !policy.AllowLate {
+		return Lateness{}, ErrSubmissionClosed
+	}
+
+	if !policy.CutoffAt.IsZero() && submittedAt.After(policy.CutoffAt) {
+		return Lateness{}, ErrSubmissionClosed
+	}
+
+	lateBy := submittedAt.Sub(deadline)
+	if lateBy <= policy.GracePeriod {
+		return Lateness{}, nil
+	}
+
+	days := int64((lateBy - policy.GracePeriod + 24*time.Hour - 1) / (24 * time.Hour))
+	penalty := days * int64(policy.PenaltyPerDay)
+	if penalty > maxLatePenalty {
+		penalty = maxLatePenalty
+	}
+
+	return Lateness{
+		IsLate:  true,
+		LateBy:  lateBy,
+		Penalty: int32(penalty),
+	}, nil
+}
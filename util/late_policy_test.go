@@ -0,0 +1,90 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatePolicyEvaluate(t *testing.T) {
+	deadline := time.Now().Truncate(time.Second)
+	policy := LatePolicy{
+		AllowLate:     true,
+		GracePeriod:   time.Hour,
+		PenaltyPerDay: 10,
+		CutoffAt:      deadline.Add(72 * time.Hour),
+	}
+
+	testCases := []struct {
+		name        string
+		policy      LatePolicy
+		deadline    time.Time
+		submittedAt time.Time
+		lateness    Lateness
+		err         error
+	}{
+		{
+			name:        "NoDeadline",
+			policy:      LatePolicy{},
+			submittedAt: deadline,
+		},
+		{
+			name:        "OnTime",
+			policy:      policy,
+			deadline:    deadline,
+			submittedAt: deadline.Add(-time.Minute),
+		},
+		{
+			name:        "LateNotAllowed",
+			policy:      LatePolicy{},
+			deadline:    deadline,
+			submittedAt: deadline.Add(time.Second),
+			err:         ErrSubmissionClosed,
+		},
+		{
+			name:        "WithinGracePeriod",
+			policy:      policy,
+			deadline:    deadline,
+			submittedAt: deadline.Add(time.Hour),
+		},
+		{
+			name:        "FirstDay",
+			policy:      policy,
+			deadline:    deadline,
+			submittedAt: deadline.Add(time.Hour + time.Second),
+			lateness:    Lateness{IsLate: true, LateBy: time.Hour + time.Second, Penalty: 10},
+		},
+		{
+			name:        "SecondDay",
+			policy:      policy,
+			deadline:    deadline,
+			submittedAt: deadline.Add(26 * time.Hour),
+			lateness:    Lateness{IsLate: true, LateBy: 26 * time.Hour, Penalty: 20},
+		},
+		{
+			name:        "PenaltyCapped",
+			policy:      LatePolicy{AllowLate: true, PenaltyPerDay: 60},
+			deadline:    deadline,
+			submittedAt: deadline.Add(48 * time.Hour),
+			lateness:    Lateness{IsLate: true, LateBy: 48 * time.Hour, Penalty: 100},
+		},
+		{
+			name:        "AfterCutoff",
+			policy:      policy,
+			deadline:    deadline,
+			submittedAt: deadline.Add(72*time.Hour + time.Second),
+			err:         ErrSubmissionClosed,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			lateness, err := tc.policy.Evaluate(tc.deadline, tc.submittedAt)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.lateness, lateness)
+		})
+	}
+}
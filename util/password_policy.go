@@ -0,0 +1,228 @@
+package util
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxPasswordLength is the most bytes of a password bcrypt looks at
+const MaxPasswordLength = 72
+
+// the policy used for settings that are not configured
+const (
+	defaultPasswordMinLength  = 8
+	defaultPasswordMinClasses = 2
+)
+
+// ErrWeakPassword is returned for passwords the policy does not allow
+var ErrWeakPassword = errors.New("password is too weak")
+
+// PasswordPolicy is what a new password has to satisfy
+type PasswordPolicy struct {
+	MinLength int
+	// MinClasses is how many of lower case letters, upper case letters,
+	// digits and other characters a password needs
+	MinClasses int
+	// History is how many of the last passwords of a user, the current one
+	// included, can not be used again
+	History  int
+	Breached *BreachedPasswords
+}
+
+// NewPasswordPolicy returns the password policy of the config
+func NewPasswordPolicy(config Config) (PasswordPolicy, error) {
+	policy := PasswordPolicy{
+		MinLength:  config.PasswordMinLength,
+		MinClasses: config.PasswordMinClasses,
+		History:    config.PasswordHistory,
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = defaultPasswordMinLength
+	}
+	if policy.MinClasses <= 0 {
+		policy.MinClasses = defaultPasswordMinClasses
+	}
+
+	if policy.MinLength > MaxPasswordLength {
+		return PasswordPolicy{}, fmt.Errorf("password min length can not be more than %d", MaxPasswordLength)
+	}
+
+	if policy.MinClasses > 4 {
+		return PasswordPolicy{}, errors.New("a password has at most 4 character classes")
+	}
+
+	if config.BreachedPasswordsDirectory != "" {
+		breached, err := NewBreachedPasswords(config.BreachedPasswordsDirectory)
+		if err != nil {
+			return PasswordPolicy{}, err
+		}
+		policy.Breached = breached
+	}
+
+	return policy, nil
+}
+
+// Check returns an error wrapping ErrWeakPassword when the password breaks the
+// policy. Personal are the username and email of the user, the password may
+// not be one of them.
+func (policy PasswordPolicy) Check(password string, personal ...string) error {
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return fmt.Errorf("%w: it needs at least %d characters", ErrWeakPassword, policy.MinLength)
+	}
+
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("%w: it can have at most %d bytes", ErrWeakPassword, MaxPasswordLength)
+	}
+
+	if classes := characterClasses(password); classes < policy.MinClasses {
+		return fmt.Errorf("%w: it needs %d of lower case letters, upper case letters, digits and symbols", ErrWeakPassword, policy.MinClasses)
+	}
+
+	for _, p := range personal {
+		if p == "" {
+			continue
+		}
+
+		// the local part of an email is as easy to guess as the whole address
+		local := strings.Split(p, "@")[0]
+		if strings.EqualFold(password, p) || strings.EqualFold(password, local) {
+			return fmt.Errorf("%w: it can not be your username or email", ErrWeakPassword)
+		}
+	}
+
+	if policy.Breached != nil {
+		breached, err := policy.Breached.Contains(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			return fmt.Errorf("%w: it appeared in a data breach", ErrWeakPassword)
+		}
+	}
+
+	return nil
+}
+
+// generatedPasswordLength is the length of generated passwords, unless the
+// policy asks for longer ones
+const generatedPasswordLength = 16
+
+// Generate returns a random password the policy allows
+func (policy PasswordPolicy) Generate() (string, error) {
+	length := generatedPasswordLength
+	if policy.MinLength > length {
+		length = policy.MinLength
+	}
+
+	for i := 0; i < 100; i++ {
+		var password string
+		for len(password) < length {
+			secret, err := NewSecret()
+			if err != nil {
+				return "", err
+			}
+			password += secret
+		}
+		password = password[:length]
+
+		err := policy.Check(password)
+		if err == nil {
+			return password, nil
+		}
+		if !errors.Is(err, ErrWeakPassword) {
+			return "", err
+		}
+	}
+
+	return "", errors.New("cannot generate a password the policy allows")
+}
+
+func characterClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	classes := 0
+	for _, has := range []bool{lower, upper, digit, other} {
+		if has {
+			classes++
+		}
+	}
+	return classes
+}
+
+// breachedPrefixLength is how many hex characters of the SHA-1 of a password
+// name the file its hash is listed in
+const breachedPrefixLength = 5
+
+// BreachedPasswords is an offline list of breached passwords laid out like
+// the range api of Have I Been Pwned. The file named after the first five hex
+// characters of the SHA-1 of a password lists the rest of every breached hash
+// with that prefix, one SUFFIX or SUFFIX:COUNT a line. Only the file of the
+// prefix is read for a lookup.
+type BreachedPasswords struct {
+	dir string
+}
+
+func NewBreachedPasswords(dir string) (*BreachedPasswords, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open breached passwords: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached passwords %s is not a directory", dir)
+	}
+
+	return &BreachedPasswords{dir: dir}, nil
+}
+
+// Contains reports whether the password is in the list
+func (breached *BreachedPasswords) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+
+	file, err := os.Open(filepath.Join(breached.dir, prefix))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("cannot open breached passwords: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			line = line[:i]
+		}
+
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("cannot read breached passwords: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,123 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	breached, err := NewBreachedPasswords("../breached_passwords")
+	require.NoError(t, err)
+
+	policy := PasswordPolicy{
+		MinLength:  8,
+		MinClasses: 3,
+		Breached:   breached,
+	}
+
+	testCases := []struct {
+		name     string
+		password string
+		ok       bool
+	}{
+		{name: "OK", password: "Kx7#mQ2vLp", ok: true},
+		{name: "ThreeClasses", password: "correct Horse battery", ok: true},
+		{name: "TooShort", password: "Ab1#", ok: false},
+		{name: "TooShortInCharacters", password: "Äb1#Öü€", ok: false},
+		{name: "TooLong", password: "Ab1#" + strings.Repeat("x", MaxPasswordLength), ok: false},
+		{name: "TwoClasses", password: "abcdefgh12", ok: false},
+		{name: "Username", password: "Jane.Doe42", ok: false},
+		{name: "EmailLocalPart", password: "J.doe-2024", ok: false},
+		{name: "Email", password: "j.doe-2024@example.com", ok: false},
+		{name: "Breached", password: "Password123", ok: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(tc.password, "jane.doe42", "J.Doe-2024@example.com")
+			if tc.ok {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrWeakPassword)
+			}
+		})
+	}
+}
+
+func TestNewPasswordPolicy(t *testing.T) {
+	policy, err := NewPasswordPolicy(Config{PasswordMinLength: 10, PasswordMinClasses: 2, PasswordHistory: 5})
+	require.NoError(t, err)
+	require.Equal(t, 10, policy.MinLength)
+	require.Equal(t, 2, policy.MinClasses)
+	require.Equal(t, 5, policy.History)
+	require.Nil(t, policy.Breached)
+
+	// unset settings fall back to the defaults instead of allowing anything
+	policy, err = NewPasswordPolicy(Config{})
+	require.NoError(t, err)
+	require.Equal(t, defaultPasswordMinLength, policy.MinLength)
+	require.Equal(t, defaultPasswordMinClasses, policy.MinClasses)
+	require.Error(t, policy.Check("a1"))
+
+	_, err = NewPasswordPolicy(Config{PasswordMinLength: MaxPasswordLength + 1})
+	require.Error(t, err)
+
+	_, err = NewPasswordPolicy(Config{PasswordMinClasses: 5})
+	require.Error(t, err)
+
+	_, err = NewPasswordPolicy(Config{BreachedPasswordsDirectory: filepath.Join(t.TempDir(), "missing")})
+	require.Error(t, err)
+}
+
+func TestGeneratePassword(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, MinClasses: 4}
+
+	password1, err := policy.Generate()
+	require.NoError(t, err)
+	require.Len(t, password1, generatedPasswordLength)
+	require.NoError(t, policy.Check(password1))
+
+	password2, err := policy.Generate()
+	require.NoError(t, err)
+	require.NotEqual(t, password1, password2)
+
+	policy.MinLength = 30
+	password3, err := policy.Generate()
+	require.NoError(t, err)
+	require.Len(t, password3, 30)
+}
+
+func TestBreachedPasswords(t *testing.T) {
+	dir := t.TempDir()
+
+	// SHA-1 of "P@ssw0rd" is 21BD12DC183F740EE76F27B78EB39C8AD972A757
+	err := os.WriteFile(filepath.Join(dir, "21BD1"), []byte("0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n2DC183F740EE76F27B78EB39C8AD972A757:52579\r\n"), 0644)
+	require.NoError(t, err)
+
+	breached, err := NewBreachedPasswords(dir)
+	require.NoError(t, err)
+
+	ok, err := breached.Contains("P@ssw0rd")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = breached.Contains("p@ssw0rd")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = breached.Contains(RandomString(20))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+
+	_, err = NewBreachedPasswords(file)
+	require.Error(t, err)
+}
@@ -52,14 +52,16 @@ func RandomBoolean() bool {
 	return rand.Float32() < 0.5
 }
 
-func RandomSubject() string {
-	n := len(Subjects)
-	subjects := make([]string, n)
-	id := 0
-	for _, value := range Subjects {
-		subjects[id] = value
-		id++
-	}
+// RandomRole returns a random role other than admin
+func RandomRole() string {
+	roles := []string{RoleTeacher, RoleStudent, RoleGuardian}
+	return roles[rand.Intn(len(roles))]
+}
 
-	return subjects[rand.Intn(n)]
+// seededSubjects are the subjects inserted by the subjects migration
+var seededSubjects = []string{"Math", "Biology", "Literature", "Science", "Physics", "Chemical"}
+
+// RandomSubject returns one of the seeded subjects so test data satisfies the foreign key
+func RandomSubject() string {
+	return seededSubjects[rand.Intn(len(seededSubjects))]
 }
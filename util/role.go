@@ -0,0 +1,17 @@
+package util
+
+// roles a user can have
+const (
+	RoleAdmin    = "admin"
+	RoleTeacher  = "teacher"
+	RoleStudent  = "student"
+	RoleGuardian = "guardian"
+)
+
+func IsSupportedRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleTeacher, RoleStudent, RoleGuardian:
+		return true
+	}
+	return false
+}
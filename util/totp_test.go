@@ -0,0 +1,86 @@
+package util
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// the sha1 secret of the test vectors in RFC 6238, "12345678901234567890"
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// the last 6 digits of the 8 digit codes of RFC 6238
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range testCases {
+		code, err := TOTPCode(rfcTOTPSecret, TOTPStep(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code)
+	}
+
+	_, err := TOTPCode("not base32!", 1)
+	require.Error(t, err)
+}
+
+func TestCheckTOTP(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	now := time.Now()
+	step := TOTPStep(now)
+
+	code, err := TOTPCode(secret, step)
+	require.NoError(t, err)
+
+	checked, err := CheckTOTP(secret, code, now)
+	require.NoError(t, err)
+	require.Equal(t, step, checked)
+
+	// one step of clock drift is accepted
+	checked, err = CheckTOTP(secret, code, now.Add(TOTPPeriod))
+	require.NoError(t, err)
+	require.Equal(t, step, checked)
+
+	_, err = CheckTOTP(secret, code, now.Add(3*TOTPPeriod))
+	require.ErrorIs(t, err, ErrInvalidTOTP)
+
+	_, err = CheckTOTP(secret, "12345", now)
+	require.ErrorIs(t, err, ErrInvalidTOTP)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri, err := url.Parse(TOTPURI("Class manager", "alice", rfcTOTPSecret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Class manager:alice", uri.Path)
+	require.Equal(t, rfcTOTPSecret, uri.Query().Get("secret"))
+	require.Equal(t, "Class manager", uri.Query().Get("issuer"))
+	require.Equal(t, "6", uri.Query().Get("digits"))
+	require.Equal(t, "30", uri.Query().Get("period"))
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code, err := NewRecoveryCode()
+	require.NoError(t, err)
+	require.Len(t, code, 11)
+	require.Equal(t, "-", code[5:6])
+
+	require.Equal(t, code, NormalizeRecoveryCode(code))
+	require.Equal(t, code, NormalizeRecoveryCode(strings.ToUpper(code)))
+	require.Equal(t, code, NormalizeRecoveryCode(" "+strings.ReplaceAll(code, "-", "")+" "))
+}